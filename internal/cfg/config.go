@@ -39,6 +39,60 @@ type CLIParameter struct {
 	// EnableExternalConnection allows daemon to start without --curl, waiting for external Xdebug trigger
 	EnableExternalConnection bool
 
+	// CaptureOutput copies the script's stdout to the debugger for the output command
+	CaptureOutput bool
+
 	// RetryAttempts is the number of connection retry attempts for attach command
 	RetryAttempts int
+
+	// PathMappings maps remote (Xdebug) paths to local paths, each as "remote=local"
+	PathMappings []string
+
+	// Profile is the config file profile to apply (empty = none)
+	Profile string
+
+	// Session is the ID of the debug session attach commands are sent to (empty = default session)
+	Session string
+
+	// Wait makes attach block until the script next breaks or ends
+	Wait bool
+
+	// WaitTimeout is how long attach --wait blocks, in seconds
+	WaitTimeout int
+
+	// Interactive makes attach read commands from a prompt
+	Interactive bool
+
+	// TraceOver makes trace step over calls instead of into them
+	TraceOver bool
+
+	// TraceTo is the line location (":N" or "file:N") trace stops at
+	TraceTo string
+
+	// TraceReturn makes trace stop when the starting function returns
+	TraceReturn bool
+
+	// TraceSteps is the maximum number of statements trace executes
+	TraceSteps int
+
+	// TraceWhen is a PHP expression that stops trace once it is true
+	TraceWhen string
+
+	// TraceVars are the variables recorded with every traced statement
+	TraceVars []string
+
+	// TraceTimeout is how long trace runs before stopping, in seconds
+	TraceTimeout int
+
+	// TraceOutput is the file trace writes JSON lines to (empty = stdout)
+	TraceOutput string
+
+	// IPCListen is an optional TCP address the daemon accepts IPC requests on
+	IPCListen string
+
+	// IPCAddr is the TCP address of a daemon to talk to instead of looking up the port in the registry
+	IPCAddr string
+
+	// IPCToken is the shared token for IPC over TCP
+	IPCToken string
 }
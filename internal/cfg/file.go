@@ -0,0 +1,427 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileNames are the config file names searched for from the working
+// directory up to the filesystem root
+var ProjectFileNames = []string{".xdebug-cli.yaml", ".xdebug-cli.yml"}
+
+// EnvPrefix is the prefix of environment variables overriding config values
+const EnvPrefix = "XDEBUG_CLI_"
+
+// ProfileEnv selects a profile when --profile is not given
+const ProfileEnv = EnvPrefix + "PROFILE"
+
+// Sources of a configuration value besides file paths
+const (
+	SourceFlag    = "flag"
+	SourceDefault = "default"
+)
+
+// KeyKind is the type of value a config key holds
+type KeyKind int
+
+const (
+	KindString KeyKind = iota
+	KindInt
+	KindBool
+	KindList
+)
+
+// Key is a setting that can be given in a config file, an environment
+// variable or a command-line flag
+type Key struct {
+	// Name is the key as written in config files, e.g. breakpoint_timeout
+	Name string
+
+	// Kind is the type of the value
+	Kind KeyKind
+
+	// Command limits the key to one subcommand (empty = every command with the flag)
+	Command string
+
+	// Secret hides the value in config show
+	Secret bool
+
+	// EnvSeparators are the characters separating list items in the
+	// environment variable (default ",")
+	EnvSeparators string
+}
+
+// FlagName returns the command-line flag for the key, e.g. breakpoint-timeout
+func (k Key) FlagName() string {
+	return strings.ReplaceAll(k.Name, "_", "-")
+}
+
+// EnvName returns the environment variable for the key, e.g. XDEBUG_CLI_BREAKPOINT_TIMEOUT
+func (k Key) EnvName() string {
+	return EnvPrefix + strings.ToUpper(k.Name)
+}
+
+// Keys lists every configurable setting in display order
+var Keys = []Key{
+	{Name: "host", Kind: KindString},
+	{Name: "port", Kind: KindInt},
+	{Name: "json", Kind: KindBool},
+	{Name: "path_map", Kind: KindList},
+	{Name: "curl", Kind: KindString},
+	{Name: "enable_external_connection", Kind: KindBool},
+	{Name: "commands", Kind: KindList, Command: "start", EnvSeparators: ";\n"},
+	{Name: "breakpoint_timeout", Kind: KindInt},
+	{Name: "wait_forever", Kind: KindBool},
+	{Name: "capture_output", Kind: KindBool},
+	{Name: "retry", Kind: KindInt},
+	{Name: "ipc_listen", Kind: KindString, Command: "start"},
+	{Name: "ipc_addr", Kind: KindString},
+	{Name: "ipc_token", Kind: KindString, Secret: true},
+}
+
+// LookupKey finds a key by name
+func LookupKey(name string) (Key, bool) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Setting is the effective value of a key and where it came from
+type Setting struct {
+	Key Key
+
+	// Values holds the value; scalar keys have exactly one element
+	Values []string
+
+	// Source is a config file path, an environment variable name, SourceFlag or SourceDefault
+	Source string
+}
+
+// String formats the value for display
+func (s Setting) String() string {
+	if s.Key.Kind == KindList {
+		return "[" + strings.Join(s.Values, ", ") + "]"
+	}
+	if len(s.Values) == 0 {
+		return ""
+	}
+	return s.Values[0]
+}
+
+// Config is the merged configuration from config files and the environment
+type Config struct {
+	// Profile is the selected profile (empty = none)
+	Profile string
+
+	// Files lists the config files that were loaded, lowest precedence first
+	Files []string
+
+	settings map[string]Setting
+}
+
+// Get returns the setting for a key, if any source configured it
+func (c *Config) Get(name string) (Setting, bool) {
+	setting, ok := c.settings[name]
+	return setting, ok
+}
+
+// Settings returns the configured settings in key order
+func (c *Config) Settings() []Setting {
+	settings := make([]Setting, 0, len(c.settings))
+	for _, key := range Keys {
+		if setting, ok := c.settings[key.Name]; ok {
+			settings = append(settings, setting)
+		}
+	}
+	return settings
+}
+
+// LoadOptions controls where Load looks for configuration
+type LoadOptions struct {
+	// WorkDir is where the project config search starts
+	WorkDir string
+
+	// HomeDir holds the user config in .xdebug-cli/config.yaml (empty = skip)
+	HomeDir string
+
+	// Profile is the profile to apply (empty = XDEBUG_CLI_PROFILE, if set)
+	Profile string
+
+	// Getenv reads environment variables (nil = os.Getenv)
+	Getenv func(string) string
+}
+
+// UserConfigPath returns the user config file in the given home directory
+func UserConfigPath(homeDir string) string {
+	return filepath.Join(homeDir, ".xdebug-cli", "config.yaml")
+}
+
+// FindProjectFile walks up from dir and returns the first project config
+// file found, or an empty string
+func FindProjectFile(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		for _, name := range ProjectFileNames {
+			if path := filepath.Join(dir, name); fileExists(path) {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load merges the user config, the project config and XDEBUG_CLI_*
+// environment variables, in increasing precedence. Within a file, the
+// selected profile overrides the top-level values.
+func Load(opts LoadOptions) (*Config, error) {
+	getenv := opts.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	config := &Config{
+		Profile:  opts.Profile,
+		settings: make(map[string]Setting),
+	}
+	if config.Profile == "" {
+		config.Profile = getenv(ProfileEnv)
+	}
+
+	var paths []string
+	if opts.HomeDir != "" {
+		if path := UserConfigPath(opts.HomeDir); fileExists(path) {
+			paths = append(paths, path)
+		}
+	}
+	if opts.WorkDir != "" {
+		if path := FindProjectFile(opts.WorkDir); path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	profileFound := false
+	for _, path := range paths {
+		found, err := config.loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		profileFound = profileFound || found
+		config.Files = append(config.Files, path)
+	}
+
+	if config.Profile != "" && !profileFound {
+		return nil, fmt.Errorf("profile %q not found in config files", config.Profile)
+	}
+
+	for _, key := range Keys {
+		value := getenv(key.EnvName())
+		if value == "" {
+			continue
+		}
+		var values []string
+		if key.Kind == KindList {
+			values = splitEnvList(value, key.EnvSeparators)
+		} else {
+			values = []string{value}
+		}
+		if err := config.set(key, values, key.EnvName()); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// loadFile applies the top-level values of a config file and then the
+// selected profile. It reports whether the file defines the profile.
+func (c *Config) loadFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	doc, err := parseYAML(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	var profiles map[string]interface{}
+	if raw, ok := doc["profiles"]; ok {
+		delete(doc, "profiles")
+		if profiles, ok = raw.(map[string]interface{}); !ok && raw != "" {
+			return false, fmt.Errorf("config file %s: profiles must be a mapping", path)
+		}
+	}
+
+	if err := c.apply(doc, path); err != nil {
+		return false, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	if c.Profile == "" {
+		return false, nil
+	}
+	raw, ok := profiles[c.Profile]
+	if !ok {
+		return false, nil
+	}
+	profile, ok := raw.(map[string]interface{})
+	if !ok && raw != "" {
+		return false, fmt.Errorf("config file %s: profile %q must be a mapping", path, c.Profile)
+	}
+	if err := c.apply(profile, fmt.Sprintf("%s (profile %s)", path, c.Profile)); err != nil {
+		return false, fmt.Errorf("config file %s: profile %q: %w", path, c.Profile, err)
+	}
+	return true, nil
+}
+
+// apply sets every key of a parsed mapping, rejecting unknown keys
+func (c *Config) apply(values map[string]interface{}, source string) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key, ok := LookupKey(name)
+		if !ok {
+			return fmt.Errorf("unknown key %q", name)
+		}
+
+		var list []string
+		switch value := values[name].(type) {
+		case string:
+			list = []string{value}
+		case []string:
+			if key.Kind != KindList {
+				return fmt.Errorf("%s must be a single value", name)
+			}
+			list = value
+		default:
+			return fmt.Errorf("%s must be a value or a list", name)
+		}
+
+		if err := c.set(key, list, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set validates and records a value
+func (c *Config) set(key Key, values []string, source string) error {
+	for _, value := range values {
+		switch key.Kind {
+		case KindInt:
+			if _, err := strconv.Atoi(value); err != nil {
+				return fmt.Errorf("invalid %s %q from %s: must be an integer", key.Name, value, source)
+			}
+		case KindBool:
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid %s %q from %s: must be true or false", key.Name, value, source)
+			}
+		}
+	}
+
+	c.settings[key.Name] = Setting{Key: key, Values: values, Source: source}
+	return nil
+}
+
+// parseYAML parses a config file. Scalars are returned as their text, lists
+// as []string and mappings as map[string]interface{}; an empty value is "".
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	value, err := yamlValue(doc.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	mapping, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("line %d: expected a mapping of keys to values", doc.Content[0].Line)
+	}
+	return mapping, nil
+}
+
+// yamlValue converts a YAML node to a string, a list of strings or a mapping
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind == yaml.AliasNode {
+				item = item.Alias
+			}
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: list items must be values", item.Line)
+			}
+			items = append(items, item.Value)
+		}
+		return items, nil
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: keys must be values", key.Line)
+			}
+			if _, ok := mapping[key.Value]; ok {
+				return nil, fmt.Errorf("line %d: duplicate key %q", key.Line, key.Value)
+			}
+			value, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			mapping[key.Value] = value
+		}
+		return mapping, nil
+	}
+	return nil, fmt.Errorf("line %d: unsupported value", node.Line)
+}
+
+// splitEnvList splits an environment value into list items at any of the
+// separator characters, or at commas if none are given
+func splitEnvList(value, separators string) []string {
+	if separators == "" {
+		separators = ","
+	}
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// fileExists checks whether path is an existing regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
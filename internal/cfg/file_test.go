@@ -0,0 +1,237 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file, creating parent directories
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+// envMap returns a Getenv func backed by a map
+func envMap(env map[string]string) func(string) string {
+	return func(name string) string { return env[name] }
+}
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "Controller")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	if got := FindProjectFile(nested); got != "" {
+		t.Errorf("FindProjectFile() without config = %q, want empty", got)
+	}
+
+	path := filepath.Join(root, ".xdebug-cli.yaml")
+	writeConfig(t, path, "port: 9004\n")
+
+	if got := FindProjectFile(nested); got != path {
+		t.Errorf("FindProjectFile() = %q, want %q", got, path)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	userPath := UserConfigPath(home)
+	projectPath := filepath.Join(project, ".xdebug-cli.yaml")
+
+	writeConfig(t, userPath, `host: 127.0.0.1
+port: 9003
+retry: 5
+profiles:
+  api:
+    retry: 7
+    curl: http://user/
+`)
+	writeConfig(t, projectPath, `port: 9004
+path_map:
+  - /var/www=/home/dev/app
+profiles:
+  api:
+    curl: http://project/
+    commands: ["break /a.php:3", run]
+`)
+
+	tests := []struct {
+		name   string
+		opts   LoadOptions
+		env    map[string]string
+		key    string
+		want   []string
+		source string
+	}{
+		{name: "user value", key: "host", want: []string{"127.0.0.1"}, source: userPath},
+		{name: "project overrides user", key: "port", want: []string{"9004"}, source: projectPath},
+		{name: "project list", key: "path_map", want: []string{"/var/www=/home/dev/app"}, source: projectPath},
+		{name: "env overrides project", env: map[string]string{"XDEBUG_CLI_PORT": "9010"}, key: "port", want: []string{"9010"}, source: "XDEBUG_CLI_PORT"},
+		{name: "env list", env: map[string]string{"XDEBUG_CLI_PATH_MAP": "/a=/b, /c=/d"}, key: "path_map", want: []string{"/a=/b", "/c=/d"}, source: "XDEBUG_CLI_PATH_MAP"},
+		{name: "env commands keep commas", env: map[string]string{"XDEBUG_CLI_COMMANDS": "break /a.php:3 if $a == 1, $b\neval foo(1, 2); run"}, key: "commands", want: []string{"break /a.php:3 if $a == 1, $b", "eval foo(1, 2)", "run"}, source: "XDEBUG_CLI_COMMANDS"},
+		{name: "user profile overrides user", opts: LoadOptions{Profile: "api"}, key: "retry", want: []string{"7"}, source: userPath + " (profile api)"},
+		{name: "project profile overrides user profile", opts: LoadOptions{Profile: "api"}, key: "curl", want: []string{"http://project/"}, source: projectPath + " (profile api)"},
+		{name: "profile from env", env: map[string]string{"XDEBUG_CLI_PROFILE": "api"}, key: "commands", want: []string{"break /a.php:3", "run"}, source: projectPath + " (profile api)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.WorkDir = project
+			opts.HomeDir = home
+			opts.Getenv = envMap(tt.env)
+
+			config, err := Load(opts)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(config.Files, []string{userPath, projectPath}) {
+				t.Errorf("Files = %v", config.Files)
+			}
+
+			setting, ok := config.Get(tt.key)
+			if !ok {
+				t.Fatalf("Get(%q) not found", tt.key)
+			}
+			if !reflect.DeepEqual(setting.Values, tt.want) {
+				t.Errorf("Values = %v, want %v", setting.Values, tt.want)
+			}
+			if setting.Source != tt.source {
+				t.Errorf("Source = %q, want %q", setting.Source, tt.source)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown key", content: "verbose: true\n", wantErr: `unknown key "verbose"`},
+		{name: "invalid int", content: "port: abc\n", wantErr: "must be an integer"},
+		{name: "invalid bool", content: "json: maybe\n", wantErr: "must be true or false"},
+		{name: "list for scalar", content: "port: [1, 2]\n", wantErr: "must be a single value"},
+		{name: "unknown key in profile", content: "profiles:\n  api:\n    verbose: true\n", profile: "api", wantErr: `profile "api"`},
+		{name: "missing profile", content: "port: 9003\n", profile: "api", wantErr: `profile "api" not found`},
+		{name: "invalid env", content: "", env: map[string]string{"XDEBUG_CLI_RETRY": "x"}, wantErr: "XDEBUG_CLI_RETRY"},
+		{name: "parse error", content: "port 9003\n", wantErr: "failed to parse config file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			writeConfig(t, filepath.Join(project, ".xdebug-cli.yaml"), tt.content)
+
+			_, err := Load(LoadOptions{WorkDir: project, Profile: tt.profile, Getenv: envMap(tt.env)})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_NoConfig(t *testing.T) {
+	config, err := Load(LoadOptions{WorkDir: t.TempDir(), HomeDir: t.TempDir(), Getenv: envMap(nil)})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(config.Files) != 0 || len(config.Settings()) != 0 {
+		t.Errorf("Load() without config = %+v", config)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "scalars and comments",
+			input: "# comment\nhost: 127.0.0.1\nport: 9004 # trailing\ncurl: \"http://localhost/#anchor\"\nname: 'it''s'\n",
+			want: map[string]interface{}{
+				"host": "127.0.0.1",
+				"port": "9004",
+				"curl": "http://localhost/#anchor",
+				"name": "it's",
+			},
+		},
+		{
+			name:  "block and flow lists",
+			input: "path_map:\n  - /var/www=/home/dev/app\n  - \"/srv=/opt/srv\"\ncommands: [\"break /a.php:3\", \"eval foo(1, 2)\", run]\nempty: []\n",
+			want: map[string]interface{}{
+				"path_map": []string{"/var/www=/home/dev/app", "/srv=/opt/srv"},
+				"commands": []string{"break /a.php:3", "eval foo(1, 2)", "run"},
+				"empty":    []string{},
+			},
+		},
+		{
+			name:  "list at key indentation",
+			input: "path_map:\n- /a=/b\nport: 1\n",
+			want: map[string]interface{}{
+				"path_map": []string{"/a=/b"},
+				"port":     "1",
+			},
+		},
+		{
+			name:  "multi-line scalars",
+			input: "curl: >-\n  http://localhost/index.php\n  -H 'Cookie: XDEBUG_SESSION=1'\ncommands:\n  - |-\n    eval $a\n",
+			want: map[string]interface{}{
+				"curl":     "http://localhost/index.php -H 'Cookie: XDEBUG_SESSION=1'",
+				"commands": []string{"eval $a"},
+			},
+		},
+		{
+			name:  "nested mappings",
+			input: "port: 9003\nprofiles:\n  api:\n    port: 9004\n  cli:\n    json: true\n",
+			want: map[string]interface{}{
+				"port": "9003",
+				"profiles": map[string]interface{}{
+					"api": map[string]interface{}{"port": "9004"},
+					"cli": map[string]interface{}{"json": "true"},
+				},
+			},
+		},
+		{
+			name:  "empty value",
+			input: "curl:\nport: 1\n",
+			want:  map[string]interface{}{"curl": "", "port": "1"},
+		},
+		{name: "empty document", input: "# nothing\n", want: map[string]interface{}{}},
+		{name: "not a mapping", input: "port 9003\n", wantErr: true},
+		{name: "duplicate key", input: "port: 1\nport: 2\n", wantErr: true},
+		{name: "bad indentation", input: "port: 1\n  host: x\n", wantErr: true},
+		{name: "tab indentation", input: "profiles:\n\tapi:\n", wantErr: true},
+		{name: "unterminated string", input: "curl: \"abc\n", wantErr: true},
+		{name: "unterminated list", input: "commands: [a, b\n", wantErr: true},
+		{name: "nested list", input: "commands:\n  - [a, b]\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/console/xdebug-cli/internal/daemon"
 	"github.com/console/xdebug-cli/internal/ipc"
@@ -27,13 +30,25 @@ Examples:
   # Continue execution
   xdebug-cli attach --commands "run"
 
+  # Continue without blocking, then wait for the next break (slow bootstrap)
+  xdebug-cli attach --commands "run --async"
+  xdebug-cli attach --wait --wait-timeout 120
+
   # Get JSON output for automation
   xdebug-cli attach --json --commands "context local"
 
   # Set breakpoint and step through
   xdebug-cli attach --commands "break :100"
   xdebug-cli attach --commands "run"
-  xdebug-cli attach --commands "step" "step"`,
+  xdebug-cli attach --commands "step" "step"
+
+  # Interactive prompt with history and tab completion
+  xdebug-cli attach -i
+
+  # Work with parallel requests (commands default to the most recently broken session)
+  xdebug-cli attach --commands "sessions"
+  xdebug-cli attach --session 2 --commands "context local"
+  xdebug-cli attach --commands "session 2" "run"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runAttachCmd(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -45,6 +60,11 @@ Examples:
 func init() {
 	attachCmd.Flags().StringArrayVar(&CLIArgs.Commands, "commands", []string{}, "Commands to execute")
 	attachCmd.Flags().IntVar(&CLIArgs.RetryAttempts, "retry", ipc.DefaultRetryAttempts, "Number of connection retry attempts (with exponential backoff)")
+	attachCmd.Flags().StringVar(&CLIArgs.Session, "session", "", "ID of the debug session to send commands to (default: most recently broken session)")
+	attachCmd.Flags().BoolVar(&CLIArgs.Wait, "wait", false, "After the commands, wait until the script breaks or ends")
+	attachCmd.Flags().IntVar(&CLIArgs.WaitTimeout, "wait-timeout", int(daemon.DefaultWaitTimeout/time.Second), "Seconds --wait blocks before giving up")
+	attachCmd.Flags().BoolVarP(&CLIArgs.Interactive, "interactive", "i", false, "Read commands from a prompt over one daemon connection")
+	addIPCFlags(attachCmd)
 	rootCmd.AddCommand(attachCmd)
 }
 
@@ -52,30 +72,45 @@ func init() {
 func runAttachCmd() error {
 	v := view.NewView()
 
+	commands := CLIArgs.Commands
+	if CLIArgs.Wait {
+		if CLIArgs.WaitTimeout < 1 {
+			return fmt.Errorf("--wait-timeout must be at least 1 second")
+		}
+		commands = append(commands, fmt.Sprintf("wait --timeout %d", CLIArgs.WaitTimeout))
+	}
+
 	// Validate that commands are provided
-	if len(CLIArgs.Commands) == 0 {
+	if len(commands) == 0 && !CLIArgs.Interactive {
 		return fmt.Errorf("--commands flag is required for attach command")
 	}
 
-	// Create session registry
-	registry, err := daemon.NewSessionRegistry()
+	// Source listings are read from local files, so remote paths need mapping
+	pathMapper, err := pathMapperFromArgs()
 	if err != nil {
-		return fmt.Errorf("failed to create session registry: %w", err)
+		return err
 	}
+	v.SetPathMapper(pathMapper)
 
-	// Find session for the specified port
-	sessionInfo, err := registry.Get(CLIArgs.Port)
+	client, err := daemonClient()
 	if err != nil {
-		return fmt.Errorf("no daemon running on port %d. Start with:\n  xdebug-cli daemon start", CLIArgs.Port)
+		return err
+	}
+	client.SetSession(CLIArgs.Session)
+
+	if CLIArgs.Interactive {
+		return runAttachREPL(v, client, commands)
 	}
 
-	// Create IPC client
-	client := ipc.NewClient(sessionInfo.SocketPath)
+	// 'wait' holds the request open, so allow for it on top of the usual timeout
+	if timeout := daemon.RequestTimeout(commands); timeout > 0 {
+		client.SetTimeout(timeout + 5*time.Second)
+	}
 
 	// Send commands to daemon with retry logic
-	response, err := client.SendCommandsWithRetry(CLIArgs.Commands, CLIArgs.JSON, CLIArgs.RetryAttempts)
+	response, err := client.SendCommandsWithRetry(commands, CLIArgs.JSON, CLIArgs.RetryAttempts)
 	if err != nil {
-		return fmt.Errorf("failed to connect to daemon socket: %s\nThe daemon may have crashed or ended.", sessionInfo.SocketPath)
+		return fmt.Errorf("failed to connect to daemon at %s\nThe daemon may have crashed or ended.", client.Address())
 	}
 
 	// Handle response
@@ -147,33 +182,135 @@ func displayCommandResult(v *view.View, result ipc.CommandResult) {
 			}
 		}
 
-	case "run", "r", "continue", "cont", "step", "s", "into", "step_into", "next", "n", "over", "out", "o", "step_out":
+	case "run", "r", "continue", "cont", "step", "s", "into", "step_into", "next", "n", "over", "out", "o", "step_out", "pause", "wait":
 		// result.Result is a map with status, filename, line
 		if stateMap, ok := result.Result.(map[string]interface{}); ok {
 			status := stateMap["status"].(string)
 			filename := stateMap["filename"].(string)
 			line := int(stateMap["line"].(float64))
 
-			switch status {
-			case "break":
+			switch {
+			case result.Command == "pause" && status == "break":
+				v.PrintLn(fmt.Sprintf("Paused at %s:%d", filename, line))
+			case result.Command == "pause":
+				v.PrintLn(fmt.Sprintf("Pause requested, script is %s", status))
+			case status == "break":
 				v.PrintLn(fmt.Sprintf("Breakpoint hit at %s:%d", filename, line))
-			case "stopping", "stopped", "running":
+			case status == "running" && result.Command == "run":
+				v.PrintLn("Running. Use 'wait' to block until the script stops.")
+			case status == "stopping" || status == "stopped" || status == "running":
 				v.PrintLn("Execution finished.")
 			default:
 				v.PrintLn(fmt.Sprintf("Status: %s at %s:%d", status, filename, line))
 			}
+			printWatches(v, stateMap["watches"])
+			if exceptionMap, ok := stateMap["exception"].(map[string]interface{}); ok {
+				printException(v, exceptionMap)
+			}
+		}
+
+	case "exception":
+		if exceptionMap, ok := result.Result.(map[string]interface{}); ok {
+			printException(v, exceptionMap)
 		}
 
-	case "break", "b":
-		// result.Result is a map with id, location, and optionally condition
+	case "break", "b", "tbreak":
+		// result.Result is a map with id, location, and optionally condition,
+		// or a "breakpoints" list when several locations were given
 		if bpMap, ok := result.Result.(map[string]interface{}); ok {
+			if bps, ok := bpMap["breakpoints"].([]interface{}); ok {
+				for _, bpItem := range bps {
+					if bp, ok := bpItem.(map[string]interface{}); ok {
+						location := bp["location"].(string)
+						if errMsg, ok := bp["error"].(string); ok && errMsg != "" {
+							v.PrintErrorLn(fmt.Sprintf("Failed to set breakpoint at %s: %s", location, errMsg))
+						} else {
+							v.PrintLn(fmt.Sprintf("Breakpoint set at %s (ID: %s)", location, bp["id"].(string)))
+						}
+						printBreakpointWarning(v, bp)
+					}
+				}
+				break
+			}
+
 			id := bpMap["id"].(string)
 			location := bpMap["location"].(string)
 			if condition, ok := bpMap["condition"].(string); ok && condition != "" {
-				v.PrintLn(fmt.Sprintf("Breakpoint set at %s with condition '%s' (ID: %s)", location, condition, id))
+				location += fmt.Sprintf(" with condition '%s'", condition)
+			}
+			if hits, ok := bpMap["hits"].(string); ok {
+				location += fmt.Sprintf(" when hits %s", hits)
+			}
+			if temporary, _ := bpMap["temporary"].(bool); temporary {
+				v.PrintLn(fmt.Sprintf("Temporary breakpoint set at %s (ID: %s)", location, id))
 			} else {
 				v.PrintLn(fmt.Sprintf("Breakpoint set at %s (ID: %s)", location, id))
 			}
+			printBreakpointWarning(v, bpMap)
+		}
+
+	case "frame", "up", "down":
+		// result.Result is a map with depth, function, file and line of the selected frame
+		if frameMap, ok := result.Result.(map[string]interface{}); ok {
+			depth := int(frameMap["depth"].(float64))
+			function := frameMap["function"].(string)
+			file := frameMap["file"].(string)
+			line := int(frameMap["line"].(float64))
+			v.PrintLn(fmt.Sprintf("#%d %s at %s:%d", depth, function, file, line))
+		}
+
+	case "stack":
+		// result.Result is a list of frames; the selected frame is marked
+		if frames, ok := result.Result.([]interface{}); ok {
+			for _, frameItem := range frames {
+				if frameMap, ok := frameItem.(map[string]interface{}); ok {
+					marker := " "
+					if selected, _ := frameMap["selected"].(bool); selected {
+						marker = ">"
+					}
+					depth := frameMap["depth"].(string)
+					function := frameMap["function"].(string)
+					file := frameMap["file"].(string)
+					line := int(frameMap["line"].(float64))
+					v.PrintLn(fmt.Sprintf("%s #%s %s at %s:%d", marker, depth, function, file, line))
+				}
+			}
+		}
+
+	case "status", "st":
+		// result.Result is a map with status, filename, line and the selected frame
+		if statusMap, ok := result.Result.(map[string]interface{}); ok {
+			status := statusMap["status"].(string)
+			filename, _ := statusMap["filename"].(string)
+			line, _ := statusMap["line"].(float64)
+			message := fmt.Sprintf("Status: %s", status)
+			if filename != "" {
+				message += fmt.Sprintf(" at %s:%d", filename, int(line))
+			}
+			if frame, _ := statusMap["frame"].(float64); frame > 0 {
+				message += fmt.Sprintf(" (frame #%d selected)", int(frame))
+			}
+			v.PrintLn(message)
+		}
+
+	case "until", "u":
+		// result.Result is a map with status, filename, line, target and reached
+		if untilMap, ok := result.Result.(map[string]interface{}); ok {
+			status := untilMap["status"].(string)
+			filename := untilMap["filename"].(string)
+			line := int(untilMap["line"].(float64))
+			target := untilMap["target"].(string)
+			printBreakpointWarning(v, untilMap)
+
+			switch {
+			case untilMap["reached"] == true:
+				v.PrintLn(fmt.Sprintf("Reached %s:%d", filename, line))
+			case status == "break":
+				v.PrintLn(fmt.Sprintf("Stopped at breakpoint %s:%d before reaching %s", filename, line, target))
+			default:
+				v.PrintLn(fmt.Sprintf("Execution finished before reaching %s", target))
+			}
+			printWatches(v, untilMap["watches"])
 		}
 
 	case "info", "i":
@@ -194,9 +331,26 @@ func displayCommandResult(v *view.View, result ipc.CommandResult) {
 							if filename, ok := bpMap["filename"].(string); ok && filename != "" {
 								if line, ok := bpMap["line"].(float64); ok && line > 0 {
 									location = fmt.Sprintf("%s:%d", filename, int(line))
+									if requested, ok := bpMap["requested_line"].(float64); ok && requested > 0 {
+										location += fmt.Sprintf(" (requested line %d)", int(requested))
+									}
 								}
 							}
-							v.PrintLn(fmt.Sprintf("  [%s] %s (%s) %s", id, bpType, state, location))
+							hitCount, _ := bpMap["hit_count"].(float64)
+							hitValue, _ := bpMap["hit_value"].(float64)
+							hitCondition, _ := bpMap["hit_condition"].(string)
+							hits := view.FormatHits(int(hitCount), int(hitValue), hitCondition)
+							function, _ := bpMap["function"].(string)
+							class, _ := bpMap["class"].(string)
+							exception, _ := bpMap["exception"].(string)
+							expression, _ := bpMap["expression"].(string)
+							if details := view.FormatBreakpointDetails(function, class, exception, expression); details != "" {
+								location = strings.TrimSpace(location + " " + details)
+							}
+							if message, ok := bpMap["log"].(string); ok && message != "" {
+								location += fmt.Sprintf(" log: %q", message)
+							}
+							v.PrintLn(fmt.Sprintf("  [%s] %s (%s) %s hits: %s", id, bpType, state, location, hits))
 						}
 					}
 					v.PrintLn("")
@@ -211,7 +365,11 @@ func displayCommandResult(v *view.View, result ipc.CommandResult) {
 							where := frameMap["where"].(string)
 							filename := frameMap["filename"].(string)
 							line := int(frameMap["line"].(float64))
-							v.PrintLn(fmt.Sprintf("#%d %s at %s:%d", level, where, filename, line))
+							marker := " "
+							if selected, _ := frameMap["selected"].(bool); selected {
+								marker = ">"
+							}
+							v.PrintLn(fmt.Sprintf("%s #%d %s at %s:%d", marker, level, where, filename, line))
 						}
 					}
 					v.PrintLn("")
@@ -219,12 +377,11 @@ func displayCommandResult(v *view.View, result ipc.CommandResult) {
 			}
 		}
 
-	case "list", "l":
-		// result.Result is a map with file and line
+	case "list", "l", "source", "src":
+		// result.Result is a map with file and the annotated source lines
 		if listMap, ok := result.Result.(map[string]interface{}); ok {
-			filename := listMap["file"].(string)
-			line := int(listMap["line"].(float64))
-			v.PrintSourceLn(filename, line, 5)
+			filename, _ := listMap["file"].(string)
+			v.PrintSourceListing(filename, mapToSourceLines(listMap["lines"]))
 		}
 
 	case "finish", "f":
@@ -261,7 +418,306 @@ func displayCommandResult(v *view.View, result ipc.CommandResult) {
 			bpID := delMap["breakpoint_id"].(string)
 			v.PrintLn(fmt.Sprintf("Deleted breakpoint %s", bpID))
 		}
+
+	case "sessions":
+		// result.Result is a map with a "sessions" list
+		if sessionsMap, ok := result.Result.(map[string]interface{}); ok {
+			sessions, _ := sessionsMap["sessions"].([]interface{})
+			if len(sessions) == 0 {
+				v.PrintLn("No active sessions.")
+				return
+			}
+			v.PrintLn("\nSessions:")
+			v.PrintLn("----------------------------------------")
+			for _, sessionItem := range sessions {
+				if sessionMap, ok := sessionItem.(map[string]interface{}); ok {
+					v.PrintLn(formatSessionLine(sessionMap))
+				}
+			}
+			v.PrintLn("")
+		}
+
+	case "output":
+		// result.Result is a map with captured "output", or a "message" for capture changes
+		if outputMap, ok := result.Result.(map[string]interface{}); ok {
+			if msg, ok := outputMap["message"].(string); ok {
+				v.PrintLn(msg)
+				return
+			}
+			output, _ := outputMap["output"].(string)
+			if output == "" {
+				v.PrintLn("No output captured.")
+				return
+			}
+			v.Print(output)
+			if !strings.HasSuffix(output, "\n") {
+				v.PrintLn("")
+			}
+		}
+
+	case "notifications":
+		// result.Result is a map with a "notifications" list, or a "message" after clear
+		if notifyMap, ok := result.Result.(map[string]interface{}); ok {
+			if msg, ok := notifyMap["message"].(string); ok {
+				v.PrintLn(msg)
+				return
+			}
+			notifications, _ := notifyMap["notifications"].([]interface{})
+			if len(notifications) == 0 {
+				v.PrintLn("No notifications.")
+				return
+			}
+			for _, item := range notifications {
+				if notification, ok := item.(map[string]interface{}); ok {
+					v.PrintLn(formatNotificationLine(notification))
+				}
+			}
+		}
+
+	case "session":
+		// result.Result is a map describing the selected session
+		if sessionMap, ok := result.Result.(map[string]interface{}); ok {
+			v.PrintLn(fmt.Sprintf("Current session: %d", int(sessionMap["id"].(float64))))
+			v.PrintLn(formatSessionLine(sessionMap))
+		}
+
+	case "logpoint":
+		if lpMap, ok := result.Result.(map[string]interface{}); ok {
+			v.PrintLn(fmt.Sprintf("Logpoint set at %v (ID: %v): %q", lpMap["location"], lpMap["id"], lpMap["message"]))
+			printBreakpointWarning(v, lpMap)
+		}
+
+	case "logs":
+		// result.Result is a map with a "logs" list, or a "message" after clear
+		if logsMap, ok := result.Result.(map[string]interface{}); ok {
+			if msg, ok := logsMap["message"].(string); ok {
+				v.PrintLn(msg)
+				return
+			}
+			logs, _ := logsMap["logs"].([]interface{})
+			if len(logs) == 0 {
+				v.PrintLn("No log messages.")
+				return
+			}
+			for _, item := range logs {
+				if entry, ok := item.(map[string]interface{}); ok {
+					v.PrintLn(fmt.Sprintf("%v %v:%d %v", entry["time"], entry["filename"], int(entry["line"].(float64)), entry["message"]))
+				}
+			}
+		}
+
+	case "record":
+		// result.Result is a map with the recorded "trace" and why recording stopped
+		if recordMap, ok := result.Result.(map[string]interface{}); ok {
+			trace, _ := recordMap["trace"].([]interface{})
+			for _, item := range trace {
+				if statement, ok := item.(map[string]interface{}); ok {
+					v.PrintLn(formatTraceLine(statement))
+				}
+			}
+			v.PrintLn(fmt.Sprintf("Recorded %d steps, stopped: %v", int(recordMap["steps"].(float64)), recordMap["stopped"]))
+			if errMsg, ok := recordMap["error"].(string); ok {
+				v.PrintErrorLn(fmt.Sprintf("Stepping failed: %s", errMsg))
+			}
+		}
+
+	case "watch":
+		// result.Result is a single watch with id, expression and its value if evaluated
+		if watchMap, ok := result.Result.(map[string]interface{}); ok {
+			v.PrintLn("Watch " + formatWatchLine(watchMap))
+		}
+
+	case "watches":
+		// result.Result is a map with a "watches" list
+		if watchesMap, ok := result.Result.(map[string]interface{}); ok {
+			watches, _ := watchesMap["watches"].([]interface{})
+			if len(watches) == 0 {
+				v.PrintLn("No watch expressions.")
+				return
+			}
+			for _, item := range watches {
+				if watchMap, ok := item.(map[string]interface{}); ok {
+					v.PrintLn(formatWatchLine(watchMap))
+				}
+			}
+		}
+
+	case "unwatch":
+		if watchMap, ok := result.Result.(map[string]interface{}); ok {
+			v.PrintLn(fmt.Sprintf("Watch %d removed: %v", int(watchMap["id"].(float64)), watchMap["expression"]))
+		}
+
+	case "feature":
+		// result.Result is a map with a "features" list of name/value pairs
+		if featureMap, ok := result.Result.(map[string]interface{}); ok {
+			features, _ := featureMap["features"].([]interface{})
+			for _, item := range features {
+				feature, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if supported, _ := feature["supported"].(bool); !supported {
+					v.PrintLn(fmt.Sprintf("%v: not supported", feature["name"]))
+					continue
+				}
+				v.PrintLn(fmt.Sprintf("%v = %v", feature["name"], feature["value"]))
+			}
+		}
+	}
+}
+
+// formatTraceLine formats one recorded statement as
+// "#step file:line (depth N)" followed by the recorded variables
+func formatTraceLine(statement map[string]interface{}) string {
+	line := fmt.Sprintf("#%d %v:%d (depth %d)", int(statement["step"].(float64)),
+		statement["filename"], int(statement["line"].(float64)), int(statement["depth"].(float64)))
+
+	vars, _ := statement["vars"].(map[string]interface{})
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if valueMap, ok := vars[name].(map[string]interface{}); ok {
+			prop := mapToJSONProperty(valueMap)
+			if prop.NumChildren > 0 {
+				line += fmt.Sprintf(" %s=(%s)[%d]", name, prop.Type, prop.NumChildren)
+			} else {
+				line += fmt.Sprintf(" %s=%s", name, prop.Value)
+			}
+		}
+	}
+	return line
+}
+
+// printWatches prints the watch values included in the result of a command
+// that stopped the script
+func printWatches(v *view.View, watches interface{}) {
+	list, _ := watches.([]interface{})
+	for _, item := range list {
+		if watchMap, ok := item.(map[string]interface{}); ok {
+			v.PrintLn("  " + formatWatchLine(watchMap))
+		}
+	}
+}
+
+// printBreakpointWarning prints the warning of a breakpoint that was moved
+// to, or may belong on, another line
+func printBreakpointWarning(v *view.View, bpMap map[string]interface{}) {
+	if warning, ok := bpMap["warning"].(string); ok && warning != "" {
+		v.PrintLn("  Warning: " + warning)
+	}
+}
+
+// printException prints an exception with its getPrevious() chain and trace
+func printException(v *view.View, exceptionMap map[string]interface{}) {
+	v.PrintLn(formatExceptionLine(exceptionMap))
+	if previous, ok := exceptionMap["previous"].([]interface{}); ok {
+		for _, item := range previous {
+			if previousMap, ok := item.(map[string]interface{}); ok {
+				v.PrintLn("  Caused by " + formatExceptionLine(previousMap))
+			}
+		}
+	}
+	if trace, ok := exceptionMap["trace"].([]interface{}); ok {
+		for i, item := range trace {
+			if frameMap, ok := item.(map[string]interface{}); ok {
+				v.PrintLn(fmt.Sprintf("  #%d %v at %v:%d", i, frameMap["function"], frameMap["filename"], int(frameMap["line"].(float64))))
+			}
+		}
+	}
+	if errMsg, ok := exceptionMap["error"].(string); ok {
+		v.PrintLn("  (" + errMsg + ")")
+	}
+}
+
+// formatExceptionLine formats an exception as "Class: message (code N) at file:line"
+func formatExceptionLine(exceptionMap map[string]interface{}) string {
+	line := fmt.Sprintf("%v: %v", exceptionMap["class"], exceptionMap["message"])
+	if code, _ := exceptionMap["code"].(string); code != "" && code != "0" {
+		line += fmt.Sprintf(" (code %s)", code)
+	}
+	return line + fmt.Sprintf(" at %v:%d", exceptionMap["filename"], int(exceptionMap["line"].(float64)))
+}
+
+// formatWatchLine formats a watch as "id: expression = value", marking
+// values that changed since the previous stop
+func formatWatchLine(watchMap map[string]interface{}) string {
+	line := fmt.Sprintf("%d: %v", int(watchMap["id"].(float64)), watchMap["expression"])
+	if errMsg, ok := watchMap["error"].(string); ok {
+		return line + " = <error: " + errMsg + ">"
+	}
+	valueMap, ok := watchMap["value"].(map[string]interface{})
+	if !ok {
+		return line
 	}
+
+	prop := mapToJSONProperty(valueMap)
+	if prop.NumChildren > 0 {
+		line += fmt.Sprintf(" = (%s) [%d children]", prop.Type, prop.NumChildren)
+	} else {
+		line += fmt.Sprintf(" = (%s) %s", prop.Type, prop.Value)
+	}
+	if changed, _ := watchMap["changed"].(bool); changed {
+		line += " (changed)"
+	}
+	return line
+}
+
+// formatSessionLine formats one entry of the 'sessions' list, marking the
+// session that commands go to by default with '*'
+func formatSessionLine(sessionMap map[string]interface{}) string {
+	marker := " "
+	if current, _ := sessionMap["current"].(bool); current {
+		marker = "*"
+	}
+	id := int(sessionMap["id"].(float64))
+	state, _ := sessionMap["state"].(string)
+	ideKey, _ := sessionMap["ide_key"].(string)
+
+	location, _ := sessionMap["fileuri"].(string)
+	if filename, _ := sessionMap["filename"].(string); filename != "" {
+		location = fmt.Sprintf("%s:%d", filename, int(sessionMap["line"].(float64)))
+	}
+
+	return fmt.Sprintf("%s [%d] %s (IDE key: %s) %s", marker, id, state, ideKey, location)
+}
+
+// formatNotificationLine formats one entry of the 'notifications' list
+func formatNotificationLine(notification map[string]interface{}) string {
+	name, _ := notification["name"].(string)
+	line := fmt.Sprintf("[%s]", name)
+
+	if id, _ := notification["breakpoint_id"].(string); id != "" {
+		line += fmt.Sprintf(" breakpoint %s", id)
+	}
+	if filename, _ := notification["filename"].(string); filename != "" {
+		lineNo, _ := notification["line"].(float64)
+		line += fmt.Sprintf(" at %s:%d", filename, int(lineNo))
+	}
+	if value, _ := notification["value"].(string); value != "" {
+		line += ": " + value
+	}
+	return line
+}
+
+// mapToSourceLines converts the decoded lines of a list or source result
+func mapToSourceLines(items interface{}) []view.JSONSourceLine {
+	list, _ := items.([]interface{})
+	lines := make([]view.JSONSourceLine, 0, len(list))
+	for _, item := range list {
+		lineMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		number, _ := lineMap["line"].(float64)
+		text, _ := lineMap["text"].(string)
+		current, _ := lineMap["current"].(bool)
+		breakpoint, _ := lineMap["breakpoint"].(string)
+		lines = append(lines, view.JSONSourceLine{Line: int(number), Text: text, Current: current, Breakpoint: breakpoint})
+	}
+	return lines
 }
 
 // mapToJSONProperty converts a map[string]interface{} to a view.JSONProperty
@@ -283,6 +739,18 @@ func mapToJSONProperty(m map[string]interface{}) view.JSONProperty {
 	if numChildren, ok := m["num_children"].(float64); ok {
 		prop.NumChildren = int(numChildren)
 	}
+	if truncated, ok := m["truncated"].(bool); ok {
+		prop.Truncated = truncated
+	}
+	if size, ok := m["size"].(float64); ok {
+		prop.Size = int(size)
+	}
+	if paging, ok := m["paging"].(map[string]interface{}); ok {
+		page, _ := paging["page"].(float64)
+		pageSize, _ := paging["page_size"].(float64)
+		pages, _ := paging["pages"].(float64)
+		prop.Paging = &view.JSONPaging{Page: int(page), PageSize: int(pageSize), Pages: int(pages)}
+	}
 
 	// Handle children recursively
 	if children, ok := m["children"].([]interface{}); ok {
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/console/xdebug-cli/internal/cfg"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the xdebug-cli configuration",
+	Long: `Inspect the configuration merged from config files, environment and flags.
+
+Configuration sources, highest precedence first:
+  1. Command-line flags
+  2. XDEBUG_CLI_* environment variables (e.g. XDEBUG_CLI_PORT=9004)
+  3. Project config: .xdebug-cli.yaml in the current directory or a parent
+  4. User config: ~/.xdebug-cli/config.yaml
+
+Within a config file, the profile selected with --profile (or
+XDEBUG_CLI_PROFILE) overrides the top-level values.
+
+Available subcommands:
+  show      Print the effective configuration and the source of each value`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Long: `Print the effective value of every setting and where it came from:
+a config file, an environment variable, a flag, or the built-in default.
+
+Example usage:
+  xdebug-cli config show
+  xdebug-cli config show --profile api
+  xdebug-cli config show --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigShow(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// configEntry is one effective setting as printed by config show
+type configEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Env    string `json:"env"`
+	Flag   string `json:"flag"`
+}
+
+// runConfigShow prints the effective configuration
+func runConfigShow(cmd *cobra.Command) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]configEntry, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		entry := configEntry{
+			Key:    key.Name,
+			Env:    key.EnvName(),
+			Flag:   "--" + key.FlagName(),
+			Source: cfg.SourceDefault,
+		}
+
+		flag := cmd.Flags().Lookup(key.FlagName())
+		if setting, ok := config.Get(key.Name); ok && (flag == nil || !flag.Changed || configuredFlags[flag.Name]) {
+			entry.Value = setting.String()
+			entry.Source = setting.Source
+		} else if flag != nil && flag.Changed {
+			entry.Value = flag.Value.String()
+			entry.Source = cfg.SourceFlag
+		} else {
+			entry.Value = flagDefault(rootCmd, key)
+		}
+		if key.Secret && entry.Value != "" {
+			entry.Value = "(hidden)"
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if CLIArgs.JSON {
+		data, err := json.Marshal(map[string]interface{}{
+			"profile":  config.Profile,
+			"files":    config.Files,
+			"settings": entries,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if config.Profile != "" {
+		fmt.Printf("Profile: %s\n", config.Profile)
+	}
+	if len(config.Files) == 0 {
+		fmt.Println("Config files: (none)")
+	} else {
+		fmt.Println("Config files:")
+		for _, file := range config.Files {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+	fmt.Println("")
+
+	width := len("Value")
+	for _, entry := range entries {
+		if len(entry.Value) > width {
+			width = len(entry.Value)
+		}
+	}
+
+	fmt.Printf("%-28s %-*s %s\n", "Key", width, "Value", "Source")
+	for _, entry := range entries {
+		fmt.Printf("%-28s %-*s %s\n", entry.Key, width, entry.Value, entry.Source)
+	}
+
+	return nil
+}
+
+// flagDefault returns the default value of the flag backing a key, searching
+// cmd and its subcommands
+func flagDefault(cmd *cobra.Command, key cfg.Key) string {
+	if key.Command == "" || key.Command == cmd.Name() {
+		if flag := cmd.PersistentFlags().Lookup(key.FlagName()); flag != nil {
+			return flag.DefValue
+		}
+		if flag := cmd.Flags().Lookup(key.FlagName()); flag != nil {
+			return flag.DefValue
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		if value := flagDefault(sub, key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -8,12 +8,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/console/xdebug-cli/internal/daemon"
 	"github.com/console/xdebug-cli/internal/dbgp"
 	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/console/xdebug-cli/internal/view"
 	"github.com/spf13/cobra"
 )
 
@@ -62,6 +64,10 @@ Features:
 - Supports initial breakpoint/command setup via --commands flag
 - Port can be changed with -p/--port flag
 - Auto-appends XDEBUG_TRIGGER cookie to curl command (when using --curl)
+- Keeps listening after a session ends; breakpoints are restored on each new connection
+- --capture-output copies the script's stdout to the debugger ('output' command)
+- --ipc-listen also accepts attach requests over TCP, e.g. from the host when
+  the daemon runs in a dev container; requires --ipc-token or XDEBUG_CLI_IPC_TOKEN
 
 Breakpoint timeout options:
 - Default 30-second timeout handles slow PHP bootstrap (opcache, frameworks)
@@ -99,7 +105,8 @@ Additional examples:
   xdebug-cli daemon start --curl "http://localhost/api -X POST -d 'data'" --commands "break :42"
   xdebug-cli daemon start --enable-external-connection --commands "break /app/file.php:42"
   xdebug-cli daemon start --enable-external-connection -p 9004 --commands "break :100"
-  xdebug-cli daemon start --curl "http://localhost/app.php" --wait-forever --commands "break :42"`,
+  xdebug-cli daemon start --curl "http://localhost/app.php" --wait-forever --commands "break :42"
+  XDEBUG_CLI_IPC_TOKEN=secret xdebug-cli daemon start --enable-external-connection --ipc-listen 0.0.0.0:9103`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runDaemonStart(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -114,7 +121,13 @@ var statusCmd = &cobra.Command{
 	Long: `Display information about the active daemon session on the current port.
 
 Shows PID, port, socket path, and start time for daemon sessions.
-For daemon sessions, provides information about the background process.`,
+For daemon sessions, also reports the session state:
+  waiting    No Xdebug connection has arrived yet
+  connected  A PHP request is being debugged
+  ended      The last request finished; waiting for the next connection
+
+With --ipc-addr, asks the daemon listening there (see daemon start
+--ipc-listen) instead of the one registered for --port on this machine.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runDaemonStatus()
 	},
@@ -184,6 +197,13 @@ func init() {
 	startCmd.Flags().StringArrayVar(&CLIArgs.Commands, "commands", []string{}, "Commands to execute when connection established (optional)")
 	startCmd.Flags().IntVar(&CLIArgs.BreakpointTimeout, "breakpoint-timeout", 30, "Timeout in seconds to wait for breakpoint hit (0 = disabled, default handles slow bootstrap)")
 	startCmd.Flags().BoolVar(&CLIArgs.WaitForever, "wait-forever", false, "Disable breakpoint timeout (wait indefinitely, useful for cold starts)")
+	startCmd.Flags().BoolVar(&CLIArgs.CaptureOutput, "capture-output", false, "Capture the script's stdout (read it with the 'output' command)")
+	startCmd.Flags().StringVar(&CLIArgs.IPCListen, "ipc-listen", "", "Also accept IPC requests on this TCP address, e.g. 127.0.0.1:9103")
+	startCmd.Flags().StringVar(&CLIArgs.IPCToken, "ipc-token", "", "Shared token clients of --ipc-listen must send (env: XDEBUG_CLI_IPC_TOKEN)")
+
+	// Add flags to reach a daemon over TCP
+	addIPCFlags(statusCmd)
+	addIPCFlags(killCmd)
 
 	// Add flags to list subcommand
 	listCmd.Flags().BoolVar(&CLIArgs.JSON, "json", false, "Output in JSON format")
@@ -253,7 +273,7 @@ func killAllDaemonsSilent() {
 		}
 
 		// Try IPC kill first
-		client := ipc.NewClient(session.SocketPath)
+		client := session.IPCClient()
 		resp, err := client.Kill()
 		if err != nil || !resp.Success {
 			// IPC failed, try direct process kill
@@ -282,6 +302,16 @@ func runDaemonStart() error {
 		CLIArgs.BreakpointTimeout = 0
 	}
 
+	// Validate path mappings before forking so errors reach the user
+	if _, err := pathMapperFromArgs(); err != nil {
+		return err
+	}
+
+	// A TCP listener is only protected by the shared token
+	if CLIArgs.IPCListen != "" && CLIArgs.IPCToken == "" {
+		return fmt.Errorf("--ipc-listen requires --ipc-token (or XDEBUG_CLI_IPC_TOKEN)")
+	}
+
 	// Check if we're already in daemon mode (child process)
 	// If so, run the daemon directly - don't do parent-only validation
 	if daemon.IsDaemonMode() {
@@ -296,6 +326,9 @@ func runDaemonStart() error {
 		if err != nil {
 			return fmt.Errorf("failed to create daemon: %w", err)
 		}
+		if CLIArgs.IPCListen != "" {
+			d.ListenTCP(CLIArgs.IPCListen, CLIArgs.IPCToken)
+		}
 
 		return runDaemonProcess(d, server)
 	}
@@ -358,7 +391,7 @@ func forkDaemon() error {
 	// Check if we have breakpoint commands that need validation
 	hasBreakpointCommand := false
 	for _, cmd := range CLIArgs.Commands {
-		if strings.HasPrefix(cmd, "break ") || strings.HasPrefix(cmd, "b ") {
+		if len(daemon.BreakpointLocations(cmd)) > 0 {
 			hasBreakpointCommand = true
 			break
 		}
@@ -411,13 +444,13 @@ func forkDaemon() error {
 		// Show pending breakpoint commands
 		fmt.Fprintf(os.Stderr, "\nPending breakpoint commands:\n")
 		for _, cmd := range CLIArgs.Commands {
-			if strings.HasPrefix(cmd, "break ") || strings.HasPrefix(cmd, "b ") {
+			if len(daemon.BreakpointLocations(cmd)) > 0 {
 				fmt.Fprintf(os.Stderr, "  - %s\n", cmd)
 			}
 		}
 
 		// Check log file for more info
-		logFile := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.log", CLIArgs.Port)
+		logFile, _ := daemon.LogFilePath(CLIArgs.Port)
 		if logContent, err := os.ReadFile(logFile); err == nil {
 			lines := strings.Split(string(logContent), "\n")
 			// Show last few relevant lines
@@ -505,226 +538,305 @@ func runDaemonProcess(d *daemon.Daemon, server *dbgp.Server) error {
 		logDaemon("No curl specified, waiting for external Xdebug connection")
 	}
 
+	pathMapper, _ := pathMapperFromArgs()
+	for _, mapping := range pathMapper.Mappings() {
+		logDaemon("Path mapping: %s => %s", mapping.Remote, mapping.Local)
+	}
+
 	logDaemon("Waiting for Xdebug connection on port %d...", CLIArgs.Port)
 
-	// Accept first connection (blocking)
-	var daemonErr error
+	// Accept connections until the daemon is killed. Each connection, including
+	// its handshake, is served in its own goroutine so a slow Xdebug peer does not
+	// hold up the others and parallel PHP requests can be debugged together; later
+	// connections get the breakpoints from earlier sessions restored.
+	var firstSession atomic.Bool
+	firstSession.Store(true)
 	err := server.Accept(func(conn *dbgp.Connection) {
 		logDaemon("Xdebug connection accepted")
 
-		// Create client and initialize
-		client := dbgp.NewClient(conn)
-		_, err := client.Init()
-		if err != nil {
-			logDaemon("Failed to initialize session: %v", err)
-			daemonErr = fmt.Errorf("failed to initialize session: %w", err)
-			return
-		}
-		logDaemon("Session initialized successfully")
-
-		// Check Xdebug configuration for potential issues
-		warnings := client.CheckXdebugConfig()
-		for _, warning := range warnings {
-			logDaemon("Warning: %s", warning.Issue)
-			logDaemon("Fix: %s", warning.FixCommand)
-		}
+		go func() {
+			// Create client and initialize
+			client := dbgp.NewClient(conn)
+			client.SetPathMapper(pathMapper)
+			_, err := client.Init()
+			if err != nil {
+				logDaemon("Failed to initialize session: %v", err)
+				conn.Close()
+				return
+			}
+			logDaemon("Session initialized successfully")
 
-		// Update global session state
-		setActiveSession(client)
-		defer clearActiveSession()
-
-		// Set the client for the daemon (now that connection is established)
-		d.SetClient(client)
-
-		// Execute initial commands if provided, otherwise step_into to pause at first line
-		if len(CLIArgs.Commands) > 0 {
-			logDaemon("Executing %d initial command(s): %v", len(CLIArgs.Commands), CLIArgs.Commands)
-			executor := daemon.NewCommandExecutor(client)
-
-			// Check if any command sets a breakpoint and collect breakpoint locations
-			hasBreakpoint := false
-			hasRunCommand := false
-			var breakpointLocations []string
-			for _, cmd := range CLIArgs.Commands {
-				if strings.HasPrefix(cmd, "break ") || strings.HasPrefix(cmd, "b ") {
-					hasBreakpoint = true
-					// Extract the location from the command
-					parts := strings.Fields(cmd)
-					if len(parts) >= 2 {
-						breakpointLocations = append(breakpointLocations, parts[1])
-					}
-				}
-				if cmd == "run" || cmd == "r" {
-					hasRunCommand = true
-				}
+			// Check Xdebug configuration for potential issues
+			warnings := client.CheckXdebugConfig()
+			for _, warning := range warnings {
+				logDaemon("Warning: %s", warning.Issue)
+				logDaemon("Fix: %s", warning.FixCommand)
 			}
 
-			// If breakpoint set without run, automatically add run command
-			commandsToExecute := CLIArgs.Commands
-			if hasBreakpoint && !hasRunCommand {
-				commandsToExecute = append(commandsToExecute, "run")
+			// Have Xdebug resolve line breakpoints to lines with code and report
+			// the line it chose (not supported by older Xdebug versions)
+			if err := client.FeatureSet("resolved_breakpoints", "1"); err != nil {
+				logDaemon("Breakpoint resolution not available: %v", err)
 			}
 
-			// Set up breakpoint validation timeout for ALL breakpoints (not just non-absolute)
-			var timeoutCh <-chan time.Time
-			if hasBreakpoint && CLIArgs.BreakpointTimeout > 0 {
-				timeoutCh = time.After(time.Duration(CLIArgs.BreakpointTimeout) * time.Second)
+			// Copy the script's stdout to the debugger so 'output' can show it
+			if CLIArgs.CaptureOutput {
+				if response, err := client.Stdout(1); err != nil {
+					logDaemon("Failed to enable output capture: %v", err)
+				} else if response.HasError() {
+					logDaemon("Failed to enable output capture: %s", response.GetErrorMessage())
+				}
 			}
 
-			logDaemon("Executing commands: %v", commandsToExecute)
-			results := executor.ExecuteCommands(commandsToExecute, CLIArgs.JSON)
-			logDaemon("Commands executed, %d result(s)", len(results))
-
-			// Check for command failures
-			for _, result := range results {
-				if !result.Success {
-					logDaemon("Command '%s' failed: %v", result.Command, result.Error)
-
-					// If 'run' command failed with EOF, it means Xdebug disconnected
-					// This can happen due to:
-					// 1. Breakpoint path doesn't match and script completes
-					// 2. xdebug.output_dir doesn't exist (trace mode crash)
-					// 3. PHP fatal error
-					if (result.Command == "run" || result.Command == "r" ||
-						result.Command == "step" || result.Command == "s" ||
-						result.Command == "step_into" || result.Command == "into" ||
-						result.Command == "next" || result.Command == "n") &&
-						strings.Contains(result.Error, "EOF") {
-						var errorMsg string
-						if hasBreakpoint {
-							breakpointStr := strings.Join(breakpointLocations, ", ")
-							errorMsg = fmt.Sprintf("Xdebug disconnected (EOF). Breakpoint at '%s' was not hit.", breakpointStr)
-							if hasNonAbsolute {
-								if suggestedPath != "" {
-									lineNum := ""
-									if strings.Contains(nonAbsPath, ":") {
-										lineNum = ":" + strings.Split(nonAbsPath, ":")[1]
-									}
-									errorMsg += fmt.Sprintf(" Use full path: %s%s", suggestedPath, lineNum)
-								} else {
-									errorMsg += " Ensure you use an absolute path (starting with /)."
-								}
-							}
-						} else {
-							errorMsg = "Xdebug disconnected (EOF) during command execution."
-						}
-						errorMsg += "\n\nPossible causes:\n"
-						errorMsg += "  - Breakpoint location not reached during script execution\n"
-						errorMsg += "  - xdebug.output_dir missing (if mode=trace): mkdir -p /tmp/profile && chmod 777 /tmp/profile\n"
-						errorMsg += "  - PHP fatal error or exception\n"
-						errorMsg += "  - Check Xdebug log: docker exec <container> cat /tmp/xdebug.log"
-						d.WriteStatus("error:" + errorMsg)
-						d.Shutdown()
-						os.Exit(1)
-					}
+			first := firstSession.Swap(false)
 
-					daemonErr = fmt.Errorf("command '%s' failed", result.Command)
-					return
+			// Update global session state. Other sessions may outlive this one,
+			// so on exit the global follows the pool instead of being cleared.
+			setActiveSession(client)
+			defer releaseActiveSession(d)
+
+			// Register the connection with the daemon (now that it is established)
+			session := d.AddSession(client)
+			logDaemon("Session %d started (IDE key: %s, file: %s)", session.ID, session.IDEKey, session.FileURI)
+
+			if !first {
+				resumeSession(d, session)
+				if d.WaitSession(session) {
+					logDaemon("Session %d ended", session.ID)
 				}
+				return
 			}
 
-			// After run command, check if we hit a breakpoint (validate for ALL breakpoints)
-			if hasBreakpoint && CLIArgs.BreakpointTimeout > 0 {
-				// Check the status - if we're in "break" status, the breakpoint was hit
-				logDaemon("Checking status after breakpoint commands...")
-				statusResp, err := client.Status()
-				if err != nil {
-					logDaemon("Failed to get status: %v", err)
-					daemonErr = fmt.Errorf("failed to get status: %w", err)
-					return
+			// Execute initial commands if provided, otherwise step_into to pause at first line
+			if len(CLIArgs.Commands) > 0 {
+				logDaemon("Executing %d initial command(s): %v", len(CLIArgs.Commands), CLIArgs.Commands)
+
+				// Check if any command sets a breakpoint and collect breakpoint locations
+				hasBreakpoint := false
+				hasRunCommand := false
+				var breakpointLocations []string
+				for _, cmd := range CLIArgs.Commands {
+					// A break command can set several breakpoints
+					if locations := daemon.BreakpointLocations(cmd); len(locations) > 0 {
+						hasBreakpoint = true
+						breakpointLocations = append(breakpointLocations, locations...)
+					}
+					if cmd == "run" || cmd == "r" {
+						hasRunCommand = true
+					}
 				}
-				logDaemon("Status: %s, Reason: %s", statusResp.Status, statusResp.Reason)
 
-				// Build breakpoint location string for error messages
-				breakpointStr := strings.Join(breakpointLocations, ", ")
+				// If breakpoint set without run, automatically add run command
+				commandsToExecute := CLIArgs.Commands
+				if hasBreakpoint && !hasRunCommand {
+					commandsToExecute = append(commandsToExecute, "run")
+				}
 
-				if statusResp.Status == "break" {
-					// Breakpoint was hit! Save the full path for future suggestions
-					currentFile, currentLine := client.GetSession().GetCurrentLocation()
-					if currentFile != "" && pathStore != nil {
-						pathStore.SaveBreakpointPath(currentFile)
-					}
-					// Signal success to parent process with location
-					d.WriteStatus(fmt.Sprintf("ready:%s:%d", currentFile, currentLine))
-				} else if statusResp.Status == "stopping" || statusResp.Status == "stopped" {
-					// Script ended without hitting breakpoint - this is the fail-fast case
-					errorMsg := fmt.Sprintf("Breakpoint at '%s' was not hit - script completed.", breakpointStr)
-					if hasNonAbsolute {
-						if suggestedPath != "" {
-							lineNum := ""
-							if strings.Contains(nonAbsPath, ":") {
-								lineNum = ":" + strings.Split(nonAbsPath, ":")[1]
+				// Set up breakpoint validation timeout for ALL breakpoints (not just non-absolute)
+				var timeoutCh <-chan time.Time
+				if hasBreakpoint && CLIArgs.BreakpointTimeout > 0 {
+					timeoutCh = time.After(time.Duration(CLIArgs.BreakpointTimeout) * time.Second)
+				}
+
+				logDaemon("Executing commands: %v", commandsToExecute)
+				results := d.ExecuteSessionCommands(session, commandsToExecute, CLIArgs.JSON)
+				logDaemon("Commands executed, %d result(s)", len(results))
+
+				// Check for command failures
+				for _, result := range results {
+					logBreakpointFailures(result)
+					if !result.Success {
+						logDaemon("Command '%s' failed: %v", result.Command, result.Error)
+
+						// If 'run' command failed with EOF, it means Xdebug disconnected
+						// This can happen due to:
+						// 1. Breakpoint path doesn't match and script completes
+						// 2. xdebug.output_dir doesn't exist (trace mode crash)
+						// 3. PHP fatal error
+						if (result.Command == "run" || result.Command == "r" ||
+							result.Command == "step" || result.Command == "s" ||
+							result.Command == "step_into" || result.Command == "into" ||
+							result.Command == "next" || result.Command == "n") &&
+							strings.Contains(result.Error, "EOF") {
+							var errorMsg string
+							if hasBreakpoint {
+								breakpointStr := strings.Join(breakpointLocations, ", ")
+								errorMsg = fmt.Sprintf("Xdebug disconnected (EOF). Breakpoint at '%s' was not hit.", breakpointStr)
+								if hasNonAbsolute {
+									if suggestedPath != "" {
+										lineNum := ""
+										if strings.Contains(nonAbsPath, ":") {
+											lineNum = ":" + strings.Split(nonAbsPath, ":")[1]
+										}
+										errorMsg += fmt.Sprintf(" Use full path: %s%s", suggestedPath, lineNum)
+									} else {
+										errorMsg += " Ensure you use an absolute path (starting with /)."
+									}
+								}
+							} else {
+								errorMsg = "Xdebug disconnected (EOF) during command execution."
 							}
-							errorMsg += fmt.Sprintf(" Use full path: %s%s", suggestedPath, lineNum)
-						} else {
-							errorMsg += " Ensure you use an absolute path (starting with /)."
+							errorMsg += "\n\nPossible causes:\n"
+							errorMsg += "  - Breakpoint location not reached during script execution\n"
+							errorMsg += "  - xdebug.output_dir missing (if mode=trace): mkdir -p /tmp/profile && chmod 777 /tmp/profile\n"
+							errorMsg += "  - PHP fatal error or exception\n"
+							errorMsg += "  - Check Xdebug log: docker exec <container> cat /tmp/xdebug.log"
+							d.WriteStatus("error:" + errorMsg)
+							d.Shutdown()
+							os.Exit(1)
 						}
-					} else {
-						errorMsg += " Verify the breakpoint location is correct and the code path is executed."
+
 					}
-					// Signal error to parent process
-					d.WriteStatus("error:" + errorMsg)
-					d.Shutdown()
-					os.Exit(1)
-				} else {
-					// Still running - wait for timeout or breakpoint hit
-					select {
-					case <-timeoutCh:
-						// Timeout expired - check status one more time
-						statusResp, err := client.Status()
-						if err != nil || statusResp.Status != "break" {
-							// Build timeout error message
-							errorMsg := fmt.Sprintf("Breakpoint not hit within %d seconds. Pending: %s", CLIArgs.BreakpointTimeout, breakpointStr)
-
-							// Write timeout event to log file
-							logFilePath := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.log", CLIArgs.Port)
-							logEntry := fmt.Sprintf("[%s] Timeout: %s\n", time.Now().Format("2006-01-02 15:04:05"), errorMsg)
-							if logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
-								logFile.WriteString(logEntry)
-								logFile.Close()
-							}
+				}
 
-							// Signal timeout error to parent process
-							d.WriteStatus("error:" + errorMsg)
+				// After run command, check if we hit a breakpoint (validate for ALL breakpoints)
+				if hasBreakpoint && CLIArgs.BreakpointTimeout > 0 {
+					// Check the session state - if we're in "break" state, the breakpoint was hit.
+					// The connection may already be closed if the script finished, so the
+					// state recorded from the last response is used instead of a status command.
+					logDaemon("Checking status after breakpoint commands...")
+					state := client.GetSession().GetState()
+					logDaemon("Status: %s", state)
 
-							// Exit with code 124 (Unix timeout convention)
-							d.Shutdown()
-							os.Exit(124)
-						}
-						// Breakpoint was hit in time
+					// Build breakpoint location string for error messages
+					breakpointStr := strings.Join(breakpointLocations, ", ")
+
+					if state == dbgp.StateBreak {
+						// Breakpoint was hit! Save the full path for future suggestions
 						currentFile, currentLine := client.GetSession().GetCurrentLocation()
 						if currentFile != "" && pathStore != nil {
 							pathStore.SaveBreakpointPath(currentFile)
 						}
 						// Signal success to parent process with location
 						d.WriteStatus(fmt.Sprintf("ready:%s:%d", currentFile, currentLine))
-					default:
-						// No timeout yet, continue normally
+					} else if state == dbgp.StateStopping || state == dbgp.StateStopped {
+						// Script ended without hitting breakpoint - this is the fail-fast case
+						errorMsg := fmt.Sprintf("Breakpoint at '%s' was not hit - script completed.", breakpointStr)
+						if hasNonAbsolute {
+							if suggestedPath != "" {
+								lineNum := ""
+								if strings.Contains(nonAbsPath, ":") {
+									lineNum = ":" + strings.Split(nonAbsPath, ":")[1]
+								}
+								errorMsg += fmt.Sprintf(" Use full path: %s%s", suggestedPath, lineNum)
+							} else {
+								errorMsg += " Ensure you use an absolute path (starting with /)."
+							}
+						} else {
+							errorMsg += " Verify the breakpoint location is correct and the code path is executed."
+						}
+						// Signal error to parent process
+						d.WriteStatus("error:" + errorMsg)
+						d.Shutdown()
+						os.Exit(1)
+					} else {
+						// Still running - wait for timeout or breakpoint hit
+						select {
+						case <-timeoutCh:
+							// Timeout expired - check status one more time
+							statusResp, err := client.Status()
+							if err != nil || statusResp.Status != "break" {
+								// Build timeout error message
+								errorMsg := fmt.Sprintf("Breakpoint not hit within %d seconds. Pending: %s", CLIArgs.BreakpointTimeout, breakpointStr)
+
+								// Write timeout event to log file
+								logFilePath, _ := daemon.LogFilePath(CLIArgs.Port)
+								logEntry := fmt.Sprintf("[%s] Timeout: %s\n", time.Now().Format("2006-01-02 15:04:05"), errorMsg)
+								if logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
+									logFile.WriteString(logEntry)
+									logFile.Close()
+								}
+
+								// Signal timeout error to parent process
+								d.WriteStatus("error:" + errorMsg)
+
+								// Exit with code 124 (Unix timeout convention)
+								d.Shutdown()
+								os.Exit(124)
+							}
+							// Breakpoint was hit in time
+							currentFile, currentLine := client.GetSession().GetCurrentLocation()
+							if currentFile != "" && pathStore != nil {
+								pathStore.SaveBreakpointPath(currentFile)
+							}
+							// Signal success to parent process with location
+							d.WriteStatus(fmt.Sprintf("ready:%s:%d", currentFile, currentLine))
+						default:
+							// No timeout yet, continue normally
+						}
 					}
 				}
-			}
-		} else {
-			// No initial commands - send step_into to pause at first line
-			// This prevents Xdebug from timing out and continuing execution
-			logDaemon("No initial commands, sending step_into to pause at first line")
-			_, err := client.Step()
-			if err != nil {
-				logDaemon("Failed to step_into: %v", err)
-				// Don't fail - session is still usable
 			} else {
-				logDaemon("Paused at first line, ready for attach commands")
+				// No initial commands - send step_into to pause at first line
+				// This prevents Xdebug from timing out and continuing execution
+				logDaemon("No initial commands, sending step_into to pause at first line")
+				results := d.ExecuteSessionCommands(session, []string{"step_into"}, false)
+				if len(results) > 0 && !results[0].Success {
+					logDaemon("Failed to step_into: %v", results[0].Error)
+					// Don't fail - session is still usable
+				} else {
+					logDaemon("Paused at first line, ready for attach commands")
+				}
 			}
-		}
 
-		// Wait for daemon shutdown
-		d.Wait()
+			// Keep the connection open until the session ends
+			if d.WaitSession(session) {
+				logDaemon("Session %d ended", session.ID)
+			}
+		}()
 	})
 
-	if daemonErr != nil {
-		return daemonErr
+	return err
+}
+
+// logBreakpointFailures logs the locations a multi-location break command
+// could not set; the other breakpoints of the command are still in place.
+// Breakpoints moved to or suggested for another line are logged as warnings.
+func logBreakpointFailures(result ipc.CommandResult) {
+	resultMap, ok := result.Result.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if warning, ok := resultMap["warning"].(string); ok {
+		logDaemon("Warning: breakpoint at %v: %s", resultMap["location"], warning)
 	}
+	breakpoints, ok := resultMap["breakpoints"].([]view.JSONBreakpointResult)
+	if !ok {
+		return
+	}
+	for _, bp := range breakpoints {
+		if bp.Error != "" {
+			logDaemon("Breakpoint at %s not set: %s", bp.Location, bp.Error)
+		}
+		if bp.Warning != "" {
+			logDaemon("Warning: breakpoint at %s: %s", bp.Location, bp.Warning)
+		}
+	}
+}
 
-	return err
+// resumeSession prepares a follow-up Xdebug connection: breakpoints from earlier
+// sessions are re-applied and execution continues to the first one. Without
+// breakpoints the session pauses at the first line like the initial session.
+func resumeSession(d *daemon.Daemon, session *daemon.DebugSession) {
+	if d.HasBreakpoints() {
+		for _, err := range d.RestoreBreakpoints(session) {
+			logDaemon("Warning: %v", err)
+		}
+		logDaemon("Breakpoints restored, running to first breakpoint")
+		for _, result := range d.ExecuteSessionCommands(session, []string{"run"}, false) {
+			if !result.Success {
+				logDaemon("Command '%s' failed: %v", result.Command, result.Error)
+			}
+		}
+		return
+	}
+
+	logDaemon("No breakpoints to restore, sending step_into to pause at first line")
+	for _, result := range d.ExecuteSessionCommands(session, []string{"step_into"}, false) {
+		if !result.Success {
+			logDaemon("Failed to step_into: %v", result.Error)
+		}
+	}
 }
 
 // activeSession holds the currently active debugging session
@@ -751,6 +863,20 @@ func clearActiveSession() {
 	activeSession.active = false
 }
 
+// releaseActiveSession points the global active session at the daemon's
+// current session once a session ends, and clears it when none is left
+func releaseActiveSession(d *daemon.Daemon) {
+	activeSession.Lock()
+	defer activeSession.Unlock()
+	if current := d.Sessions().Current(); current != nil {
+		activeSession.client = current.Client
+		activeSession.active = true
+		return
+	}
+	activeSession.client = nil
+	activeSession.active = false
+}
+
 // getActiveSession returns the current active client and whether it's active
 func getActiveSession() (*dbgp.Client, bool) {
 	activeSession.RLock()
@@ -908,6 +1034,11 @@ func parseShellArgs(s string) ([]string, error) {
 
 // runDaemonStatus displays the current daemon status
 func runDaemonStatus() {
+	if CLIArgs.IPCAddr != "" {
+		runRemoteDaemonStatus()
+		return
+	}
+
 	// First check if there's a daemon session on the current port
 	registry, err := daemon.NewSessionRegistry()
 	if err == nil {
@@ -919,7 +1050,11 @@ func runDaemonStatus() {
 			fmt.Printf("PID: %d\n", sessionInfo.PID)
 			fmt.Printf("Port: %d\n", sessionInfo.Port)
 			fmt.Printf("Socket Path: %s\n", sessionInfo.SocketPath)
+			if sessionInfo.IPCAddr != "" {
+				fmt.Printf("IPC Address: %s\n", sessionInfo.IPCAddr)
+			}
 			fmt.Printf("Started: %s\n", sessionInfo.StartedAt.Format("2006-01-02 15:04:05"))
+			printDaemonSessionState(sessionInfo.IPCClient())
 			fmt.Println("")
 			fmt.Println("This session is running as a daemon in the background.")
 			fmt.Println("Use 'xdebug-cli daemon kill' to terminate the daemon.")
@@ -964,6 +1099,78 @@ func runDaemonStatus() {
 	fmt.Println("")
 }
 
+// runRemoteDaemonStatus shows the status of the daemon at --ipc-addr
+func runRemoteDaemonStatus() {
+	client, err := daemonClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	resp, err := client.DaemonStatus()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: no daemon responding at %s: %v\n", client.Address(), err)
+		os.Exit(1)
+	}
+	if !resp.Success {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+
+	fmt.Println("Connection Status: Daemon Mode")
+	fmt.Println("")
+	fmt.Printf("IPC Address: %s\n", client.Address())
+	if len(resp.Results) > 0 {
+		if result, ok := resp.Results[0].Result.(map[string]interface{}); ok {
+			fmt.Printf("PID: %v\n", result["pid"])
+			fmt.Printf("Port: %v\n", result["port"])
+		}
+	}
+	printSessionState(resp)
+	fmt.Println("")
+	fmt.Println("Use 'xdebug-cli daemon kill --ipc-addr ...' to terminate the daemon.")
+}
+
+// printDaemonSessionState queries the daemon over IPC for its session lifecycle
+func printDaemonSessionState(client *ipc.Client) {
+	resp, err := client.DaemonStatus()
+	if err != nil || !resp.Success {
+		fmt.Println("Session State: unknown (daemon not responding)")
+		return
+	}
+	printSessionState(resp)
+}
+
+// printSessionState prints the session lifecycle from a daemon_status response
+func printSessionState(resp *ipc.CommandResponse) {
+	if len(resp.Results) == 0 {
+		fmt.Println("Session State: unknown (daemon not responding)")
+		return
+	}
+
+	result, ok := resp.Results[0].Result.(map[string]interface{})
+	if !ok {
+		fmt.Println("Session State: unknown")
+		return
+	}
+
+	fmt.Printf("Session State: %v\n", result["session_state"])
+	fmt.Printf("Sessions Served: %v\n", result["sessions_served"])
+	fmt.Printf("Active Sessions: %v\n", result["active_sessions"])
+	fmt.Printf("Remembered Breakpoints: %v\n", result["breakpoints"])
+
+	if result["session_state"] == daemon.SessionConnected {
+		fmt.Printf("Current Session: %v\n", result["session_id"])
+		fmt.Printf("Execution State: %v\n", result["execution_state"])
+		if ideKey, _ := result["ide_key"].(string); ideKey != "" {
+			fmt.Printf("IDE Key: %s\n", ideKey)
+		}
+		if file, _ := result["filename"].(string); file != "" {
+			fmt.Printf("Current Location: %s:%v\n", file, result["line"])
+		}
+	}
+}
+
 // runDaemonList lists all active daemon sessions
 func runDaemonList() {
 	registry, err := daemon.NewSessionRegistry()
@@ -1011,6 +1218,9 @@ func runDaemonList() {
 			session.StartedAt.Format("2006-01-02 15:04:05"),
 			session.SocketPath,
 		)
+		if session.IPCAddr != "" {
+			fmt.Printf("%-8s %-8s %-20s tcp://%s\n", "", "", "", session.IPCAddr)
+		}
 	}
 
 	fmt.Println("")
@@ -1019,7 +1229,14 @@ func runDaemonList() {
 
 // outputSessionListJSON outputs session list in JSON format
 func outputSessionListJSON(sessions []daemon.SessionInfo) error {
-	data, err := json.Marshal(sessions)
+	// The tokens stay in the registry; listings are often pasted around
+	listed := make([]daemon.SessionInfo, len(sessions))
+	for i, session := range sessions {
+		session.Token = ""
+		listed[i] = session
+	}
+
+	data, err := json.Marshal(listed)
 	if err != nil {
 		return err
 	}
@@ -1057,6 +1274,11 @@ func runDaemonIsAlive() {
 
 // runDaemonKill terminates the active session
 func runDaemonKill() {
+	if CLIArgs.IPCAddr != "" {
+		runRemoteDaemonKill()
+		return
+	}
+
 	// Handle --all flag
 	if CLIArgs.KillAll {
 		runDaemonKillAll()
@@ -1069,7 +1291,7 @@ func runDaemonKill() {
 		sessionInfo, err := registry.Get(CLIArgs.Port)
 		if err == nil {
 			// Daemon session found - use IPC to kill it
-			client := ipc.NewClient(sessionInfo.SocketPath)
+			client := sessionInfo.IPCClient()
 
 			fmt.Printf("Sending kill request to daemon (PID %d)...\n", sessionInfo.PID)
 
@@ -1158,6 +1380,34 @@ func runDaemonKill() {
 	fmt.Println("Session terminated.")
 }
 
+// runRemoteDaemonKill terminates the daemon at --ipc-addr. There is no
+// signal fallback: the daemon's process is not on this machine.
+func runRemoteDaemonKill() {
+	if CLIArgs.KillAll {
+		fmt.Fprintf(os.Stderr, "Error: --all cannot be combined with --ipc-addr\n")
+		os.Exit(1)
+	}
+
+	client, err := daemonClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Sending kill request to daemon at %s...\n", client.Address())
+	resp, err := client.Kill()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to send kill request to daemon: %v\n", err)
+		os.Exit(1)
+	}
+	if !resp.Success {
+		fmt.Fprintf(os.Stderr, "Kill request failed: %s\n", resp.Error)
+		os.Exit(1)
+	}
+
+	fmt.Println("Daemon terminated successfully.")
+}
+
 // runDaemonKillAll terminates all daemon sessions
 func runDaemonKillAll() {
 	registry, err := daemon.NewSessionRegistry()
@@ -1200,7 +1450,7 @@ func runDaemonKillAll() {
 	for _, session := range activeSessions {
 		fmt.Printf("Killing daemon on port %d (PID %d)... ", session.Port, session.PID)
 
-		client := ipc.NewClient(session.SocketPath)
+		client := session.IPCClient()
 		resp, err := client.Kill()
 
 		if err != nil || !resp.Success {
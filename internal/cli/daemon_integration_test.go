@@ -217,9 +217,13 @@ func TestDaemonIntegration_StaleFileCleanup(t *testing.T) {
 	port := 9102
 
 	// Create stale PID file with non-existent PID
-	stalePIDFile := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.pid", port)
+	runtimeDir, err := daemon.RuntimeDir()
+	if err != nil {
+		t.Fatalf("RuntimeDir() error = %v", err)
+	}
+	stalePIDFile := filepath.Join(runtimeDir, fmt.Sprintf("daemon-%d.pid", port))
 	stalePID := 999999
-	err := os.WriteFile(stalePIDFile, []byte(fmt.Sprintf("%d", stalePID)), 0600)
+	err = os.WriteFile(stalePIDFile, []byte(fmt.Sprintf("%d", stalePID)), 0600)
 	if err != nil {
 		t.Fatalf("Failed to create stale PID file: %v", err)
 	}
@@ -288,7 +292,15 @@ func TestDaemonIntegration_SessionEndBehavior(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Verify daemon is running
-	client := ipc.NewClient(d.GetSocketPath())
+	registry, err := daemon.NewSessionRegistry()
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	sessionInfo, err := registry.Get(port)
+	if err != nil {
+		t.Fatalf("Daemon should be registered: %v", err)
+	}
+	client := sessionInfo.IPCClient()
 	err = client.Ping()
 	if err != nil {
 		t.Errorf("Daemon should be running: %v", err)
@@ -323,7 +335,7 @@ func TestDaemonIntegration_SessionEndBehavior(t *testing.T) {
 	}
 
 	// Verify registry cleaned up
-	registry, err := daemon.NewSessionRegistry()
+	registry, err = daemon.NewSessionRegistry()
 	if err != nil {
 		t.Fatalf("Failed to create registry: %v", err)
 	}
@@ -678,8 +690,9 @@ func setupTestEnv(t *testing.T) string {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 
-	// Set HOME to temp dir for registry
+	// Set HOME to temp dir for registry, and keep daemon files there too
 	os.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_RUNTIME_DIR", tmpDir)
 
 	// Create .xdebug-cli directory
 	registryDir := filepath.Join(tmpDir, ".xdebug-cli")
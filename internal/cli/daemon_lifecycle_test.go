@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/console/xdebug-cli/internal/daemon"
 	"github.com/console/xdebug-cli/internal/dbgp"
 )
 
@@ -214,3 +215,31 @@ func TestOutputSessionListJSON(t *testing.T) {
 	// For now, just verify function signature compiles
 	_ = sessions
 }
+
+// TestReleaseActiveSession tests that the active session follows the daemon's
+// remaining sessions when one ends, and is only cleared when none is left
+func TestReleaseActiveSession(t *testing.T) {
+	tmpDir := setupTestEnv(t)
+	defer cleanupTestEnv(tmpDir)
+	defer clearActiveSession()
+
+	d, err := daemon.NewDaemon(nil, 9130)
+	if err != nil {
+		t.Fatalf("NewDaemon() error = %v", err)
+	}
+	first := d.AddSession(createMockClient())
+	second := d.AddSession(createMockClient())
+	setActiveSession(second.Client)
+
+	d.EndSession(second)
+	releaseActiveSession(d)
+	if client, active := getActiveSession(); !active || client != first.Client {
+		t.Errorf("active session after one of two ended = %p, %v, want %p, true", client, active, first.Client)
+	}
+
+	d.EndSession(first)
+	releaseActiveSession(d)
+	if client, active := getActiveSession(); active || client != nil {
+		t.Errorf("active session after all ended = %p, %v, want nil, false", client, active)
+	}
+}
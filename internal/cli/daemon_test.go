@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/console/xdebug-cli/internal/cfg"
+	"github.com/spf13/cobra"
 )
 
 // TestDaemonCommand tests the daemon parent command
@@ -360,3 +361,34 @@ func TestParseShellArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestDaemonClient_IPCAddr(t *testing.T) {
+	saved := CLIArgs
+	defer func() { CLIArgs = saved }()
+
+	CLIArgs.IPCAddr = "127.0.0.1:9103"
+	CLIArgs.IPCToken = ""
+	if _, err := daemonClient(); err == nil {
+		t.Error("--ipc-addr without a token should fail")
+	}
+
+	CLIArgs.IPCToken = "secret"
+	client, err := daemonClient()
+	if err != nil {
+		t.Fatalf("daemonClient() error = %v", err)
+	}
+	if client.Address() != "127.0.0.1:9103" {
+		t.Errorf("Address() = %s, want 127.0.0.1:9103", client.Address())
+	}
+}
+
+func TestIPCFlagsRegistered(t *testing.T) {
+	if startCmd.Flags().Lookup("ipc-listen") == nil || startCmd.Flags().Lookup("ipc-token") == nil {
+		t.Error("daemon start should have --ipc-listen and --ipc-token")
+	}
+	for _, cmd := range []*cobra.Command{attachCmd, statusCmd, killCmd} {
+		if cmd.Flags().Lookup("ipc-addr") == nil || cmd.Flags().Lookup("ipc-token") == nil {
+			t.Errorf("%s should have --ipc-addr and --ipc-token", cmd.Name())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/console/xdebug-cli/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Print daemon events as they happen",
+	Long: `Subscribe to a running daemon and print one line per event until the
+daemon shuts down.
+
+Events:
+  connection           Xdebug connected a script
+  break                A script stopped, with its location and reason
+  running              A script continued
+  stopped              A script finished or its connection dropped
+  breakpoint_added     A breakpoint was set
+  breakpoint_removed   A breakpoint was deleted
+  output               The script wrote to stdout or stderr (see 'output')
+  shutdown             The daemon is shutting down
+
+With --json each event is printed as a JSON object on its own line, with
+"type", "time" and "session" plus the fields of the event's type.
+
+Examples:
+  xdebug-cli events
+  xdebug-cli events --json | while read -r event; do ...; done
+  xdebug-cli events --json | jq -r 'select(.type == "break") | "\(.filename):\(.line)"'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runEventsCmd(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addIPCFlags(eventsCmd)
+	rootCmd.AddCommand(eventsCmd)
+}
+
+// runEventsCmd prints the events of the daemon on CLIArgs.Port
+func runEventsCmd() error {
+	client, err := daemonClient()
+	if err != nil {
+		return err
+	}
+	return client.Events(nil, func(line []byte) error {
+		if CLIArgs.JSON {
+			_, err := os.Stdout.Write(line)
+			return err
+		}
+
+		var event daemon.Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("failed to parse event: %w", err)
+		}
+		fmt.Println(formatEvent(event))
+		return nil
+	})
+}
+
+// formatEvent returns the human-readable line for an event
+func formatEvent(event daemon.Event) string {
+	var b strings.Builder
+	b.WriteString(event.Time.Local().Format("15:04:05"))
+	if event.Session != 0 {
+		fmt.Fprintf(&b, " [%d]", event.Session)
+	}
+
+	switch event.Type {
+	case daemon.EventConnection:
+		fmt.Fprintf(&b, " connection %s", event.FileURI)
+		if event.IDEKey != "" {
+			fmt.Fprintf(&b, " (idekey %s)", event.IDEKey)
+		}
+	case daemon.EventBreak:
+		fmt.Fprintf(&b, " break at %s:%d", event.Filename, event.Line)
+		if event.Exception != "" {
+			fmt.Fprintf(&b, " (%s %s)", event.Reason, event.Exception)
+		} else if event.Reason != "" && event.Reason != "ok" {
+			fmt.Fprintf(&b, " (%s)", event.Reason)
+		}
+	case daemon.EventBreakpointAdded, daemon.EventBreakpointRemoved:
+		fmt.Fprintf(&b, " %s %s: %s", event.Type, event.BreakpointID, event.Location)
+	case daemon.EventOutput:
+		fmt.Fprintf(&b, " %s: %s", event.Stream, strings.TrimSuffix(event.Data, "\n"))
+	default:
+		b.WriteString(" " + event.Type)
+	}
+	return b.String()
+}
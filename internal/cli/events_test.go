@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/daemon"
+)
+
+func TestFormatEvent(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 30, 0, 0, time.Local)
+	tests := []struct {
+		event daemon.Event
+		want  string
+	}{
+		{daemon.Event{Type: daemon.EventConnection, Session: 1, FileURI: "file:///app/index.php", IDEKey: "PHPSTORM"},
+			"10:30:00 [1] connection file:///app/index.php (idekey PHPSTORM)"},
+		{daemon.Event{Type: daemon.EventBreak, Session: 1, Filename: "/app/index.php", Line: 12, Reason: "ok"},
+			"10:30:00 [1] break at /app/index.php:12"},
+		{daemon.Event{Type: daemon.EventBreak, Session: 2, Filename: "/app/a.php", Line: 3, Reason: "exception", Exception: "RuntimeException"},
+			"10:30:00 [2] break at /app/a.php:3 (exception RuntimeException)"},
+		{daemon.Event{Type: daemon.EventBreakpointAdded, Session: 1, BreakpointID: "4", Location: "/app/a.php:7"},
+			"10:30:00 [1] breakpoint_added 4: /app/a.php:7"},
+		{daemon.Event{Type: daemon.EventOutput, Session: 1, Stream: "stdout", Data: "hello\n"},
+			"10:30:00 [1] stdout: hello"},
+		{daemon.Event{Type: daemon.EventShutdown}, "10:30:00 shutdown"},
+	}
+	for _, tt := range tests {
+		tt.event.Time = at
+		if got := formatEvent(tt.event); got != tt.want {
+			t.Errorf("formatEvent(%s) = %q, want %q", tt.event.Type, got, tt.want)
+		}
+	}
+
+	if got := formatEvent(daemon.Event{Type: daemon.EventStopped, Session: 3, Time: at}); !strings.HasSuffix(got, "[3] stopped") {
+		t.Errorf("formatEvent(stopped) = %q", got)
+	}
+}
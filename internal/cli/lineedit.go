@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// lineEditor reads lines from a terminal in raw mode. It supports cursor
+// movement, the usual Emacs control keys, history and tab completion.
+type lineEditor struct {
+	in      io.Reader
+	out     io.Writer
+	prompt  string
+	history *replHistory
+
+	// complete is called on tab with the line and the byte offset of the
+	// cursor. It returns the new line and cursor, or false to keep the line.
+	complete func(line string, pos int) (string, int, bool)
+
+	pending []byte // input read but not handled yet, e.g. from a paste
+	line    []rune
+	pos     int // cursor position in line
+}
+
+// ReadLine shows the prompt and returns the line once enter is pressed.
+// Ctrl-D on an empty line and Ctrl-C return io.EOF.
+func (l *lineEditor) ReadLine() (string, error) {
+	l.line, l.pos = nil, 0
+	historyIdx := -1
+	var editing []rune // the line being edited while browsing history
+	l.redraw()
+
+	for {
+		key, err := l.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case "enter":
+			fmt.Fprint(l.out, "\r\n")
+			line := string(l.line)
+			if l.history != nil {
+				l.history.Add(line)
+			}
+			return line, nil
+		case "ctrl-c":
+			fmt.Fprint(l.out, "^C")
+			return "", io.EOF
+		case "ctrl-d":
+			if len(l.line) == 0 {
+				return "", io.EOF
+			}
+			l.deleteRunes(l.pos, l.pos+1)
+		case "delete":
+			l.deleteRunes(l.pos, l.pos+1)
+		case "backspace":
+			if l.pos > 0 {
+				l.deleteRunes(l.pos-1, l.pos)
+				l.pos--
+			}
+		case "ctrl-w":
+			start := l.pos
+			for start > 0 && l.line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && l.line[start-1] != ' ' {
+				start--
+			}
+			l.deleteRunes(start, l.pos)
+			l.pos = start
+		case "ctrl-u":
+			l.deleteRunes(0, l.pos)
+			l.pos = 0
+		case "ctrl-k":
+			l.deleteRunes(l.pos, len(l.line))
+		case "left":
+			if l.pos > 0 {
+				l.pos--
+			}
+		case "right":
+			if l.pos < len(l.line) {
+				l.pos++
+			}
+		case "home":
+			l.pos = 0
+		case "end":
+			l.pos = len(l.line)
+		case "up", "down":
+			if l.history == nil {
+				continue
+			}
+			idx := historyIdx + 1
+			if key == "down" {
+				idx = historyIdx - 1
+			}
+			if idx < -1 || idx >= l.history.Len() {
+				continue
+			}
+			if historyIdx == -1 {
+				editing = l.line
+			}
+			historyIdx = idx
+			if idx == -1 {
+				l.line = editing
+			} else {
+				l.line = []rune(l.history.At(idx))
+			}
+			l.pos = len(l.line)
+		case "tab":
+			if l.complete == nil {
+				continue
+			}
+			pos := len(string(l.line[:l.pos]))
+			line, pos, ok := l.complete(string(l.line), pos)
+			if !ok {
+				continue
+			}
+			l.line = []rune(line)
+			l.pos = utf8.RuneCountInString(line[:pos])
+		default:
+			if utf8.RuneCountInString(key) != 1 {
+				continue
+			}
+			r, _ := utf8.DecodeRuneInString(key)
+			l.line = append(l.line[:l.pos], append([]rune{r}, l.line[l.pos:]...)...)
+			l.pos++
+		}
+		l.redraw()
+	}
+}
+
+// Write prints text above the line being edited, e.g. completion choices
+func (l *lineEditor) Write(p []byte) (int, error) {
+	fmt.Fprint(l.out, "\r\x1b[K")
+	if _, err := l.out.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	l.redraw()
+	return len(p), nil
+}
+
+// deleteRunes removes the runes from start up to end of the line
+func (l *lineEditor) deleteRunes(start, end int) {
+	if end > len(l.line) {
+		end = len(l.line)
+	}
+	if start >= end {
+		return
+	}
+	l.line = append(l.line[:start], l.line[end:]...)
+}
+
+// redraw prints the prompt and the line, and moves the cursor into place
+func (l *lineEditor) redraw() {
+	fmt.Fprintf(l.out, "\r%s%s\x1b[K", l.prompt, string(l.line))
+	if back := len(l.line) - l.pos; back > 0 {
+		fmt.Fprintf(l.out, "\x1b[%dD", back)
+	}
+}
+
+// readKey returns the next key, reading more input when needed
+func (l *lineEditor) readKey() (string, error) {
+	buf := make([]byte, 256)
+	for {
+		if key, n := decodeLineKey(l.pending); n > 0 {
+			l.pending = l.pending[n:]
+			return key, nil
+		}
+		n, err := l.in.Read(buf)
+		if n > 0 {
+			l.pending = append(l.pending, buf[:n]...)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// decodeLineKey decodes the first key of data. It returns the key name or the
+// typed character, and the bytes it used; 0 bytes means data ends in the
+// middle of a key. Keys the editor does not handle decode to "".
+func decodeLineKey(data []byte) (string, int) {
+	if len(data) == 0 {
+		return "", 0
+	}
+
+	switch b := data[0]; {
+	case b == 0x1b:
+		if len(data) == 1 {
+			return "esc", 1
+		}
+		if data[1] != '[' && data[1] != 'O' {
+			return "esc", 1
+		}
+		// CSI and SS3 sequences end with a byte in the range @ to ~
+		for i := 2; i < len(data); i++ {
+			if data[i] < 0x40 || data[i] > 0x7e {
+				continue
+			}
+			switch string(data[2 : i+1]) {
+			case "A":
+				return "up", i + 1
+			case "B":
+				return "down", i + 1
+			case "C":
+				return "right", i + 1
+			case "D":
+				return "left", i + 1
+			case "H", "1~", "7~":
+				return "home", i + 1
+			case "F", "4~", "8~":
+				return "end", i + 1
+			case "3~":
+				return "delete", i + 1
+			}
+			return "", i + 1
+		}
+		return "", 0
+	case b == '\r' || b == '\n':
+		return "enter", 1
+	case b == '\t':
+		return "tab", 1
+	case b == 0x7f || b == 0x08:
+		return "backspace", 1
+	case b < 0x20:
+		keys := map[byte]string{
+			0x01: "home", 0x02: "left", 0x03: "ctrl-c", 0x04: "ctrl-d",
+			0x05: "end", 0x06: "right", 0x0b: "ctrl-k", 0x0e: "down",
+			0x10: "up", 0x15: "ctrl-u", 0x17: "ctrl-w",
+		}
+		return keys[b], 1
+	}
+
+	if !utf8.FullRune(data) {
+		return "", 0
+	}
+	_, size := utf8.DecodeRune(data)
+	return string(data[:size]), size
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor_ReadLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		history []string // oldest first
+		want    string
+		wantErr error
+	}{
+		{name: "plain line", input: "run\r", want: "run"},
+		{name: "backspace", input: "runx\x7f\r", want: "run"},
+		{name: "insert after cursor move", input: "bak\x1b[D\x1b[Dre\x1b[F :42\r", want: "break :42"},
+		{name: "home and kill to end", input: "step\x01next\x0b\r", want: "next"},
+		{name: "delete word", input: "print $a\x17$b\r", want: "print $b"},
+		{name: "delete under cursor", input: "stepp\x1b[D\x1b[3~\r", want: "step"},
+		{name: "multibyte characters", input: "eval 'é'\x1b[D\x7f\r", want: "eval ''"},
+		{name: "history up", input: "\x1b[A\x1b[A\r", history: []string{"run", "step"}, want: "run"},
+		{name: "history back to edited line", input: "ne\x1b[A\x1b[Bxt\r", history: []string{"run"}, want: "next"},
+		{name: "ctrl-d on empty line", input: "\x04", wantErr: io.EOF},
+		{name: "ctrl-c", input: "run\x03", wantErr: io.EOF},
+		{name: "input ends", input: "run", wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := loadReplHistory(filepath.Join(t.TempDir(), "history"))
+			for _, line := range tt.history {
+				history.Add(line)
+			}
+			l := &lineEditor{in: strings.NewReader(tt.input), out: io.Discard, history: history}
+
+			line, err := l.ReadLine()
+			if err != tt.wantErr || line != tt.want {
+				t.Errorf("ReadLine() = %q, %v, want %q, %v", line, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLineEditor_Complete(t *testing.T) {
+	r := &repl{breakpointIDs: []string{"12", "15"}}
+	var out bytes.Buffer
+	l := &lineEditor{in: strings.NewReader("tbr\tfoo.php:3\rdelete 1\t\r"), out: &out, prompt: "> "}
+	l.complete = func(line string, pos int) (string, int, bool) {
+		return r.complete(l, line, pos)
+	}
+
+	if line, err := l.ReadLine(); err != nil || line != "tbreak foo.php:3" {
+		t.Errorf("ReadLine() = %q, %v, want %q", line, err, "tbreak foo.php:3")
+	}
+
+	// Ambiguous completions are listed above the prompt, which is redrawn
+	out.Reset()
+	if line, err := l.ReadLine(); err != nil || line != "delete 1" {
+		t.Errorf("ReadLine() = %q, %v, want %q", line, err, "delete 1")
+	}
+	if !strings.Contains(out.String(), "12  15\r\n\r> delete 1") {
+		t.Errorf("completion output = %q", out.String())
+	}
+}
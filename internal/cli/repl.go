@@ -0,0 +1,353 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/daemon"
+	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+// replHistorySize caps the lines kept in the history file
+const replHistorySize = 1000
+
+// defaultIPCTimeout is the IPC timeout for commands that do not block
+const defaultIPCTimeout = 5 * time.Second
+
+// replHistory is the prompt's command history. Every line is appended to a
+// file so it survives across attach -i sessions.
+type replHistory struct {
+	path    string
+	entries []string // oldest first
+}
+
+// loadReplHistory reads the history file; a missing file is an empty history
+func loadReplHistory(path string) *replHistory {
+	h := &replHistory{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > replHistorySize {
+		h.entries = h.entries[len(h.entries)-replHistorySize:]
+	}
+	return h
+}
+
+// Add records a line, skipping repeats of the previous line
+func (h *replHistory) Add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == line) {
+		return
+	}
+	h.entries = append(h.entries, line)
+
+	// Rewrite the file when it outgrows the limit, append otherwise
+	if len(h.entries) > replHistorySize {
+		h.entries = h.entries[len(h.entries)-replHistorySize:]
+		os.WriteFile(h.path, []byte(strings.Join(h.entries, "\n")+"\n"), 0600)
+		return
+	}
+	if f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
+		f.WriteString(line + "\n")
+		f.Close()
+	}
+}
+
+// Len returns the number of lines in the history
+func (h *replHistory) Len() int {
+	return len(h.entries)
+}
+
+// At returns a line of the history, 0 being the most recent
+func (h *replHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// replHistoryPath returns ~/.xdebug-cli/history, creating the directory
+func replHistoryPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(homeDir, ".xdebug-cli")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history"), nil
+}
+
+// repl is an interactive attach session over one IPC connection
+type repl struct {
+	v      *view.View
+	client *ipc.Client
+	conn   *ipc.Conn
+
+	// Completion candidates fetched from the daemon, cleared after every
+	// command since they change when the script moves
+	variables     []string
+	breakpointIDs []string
+}
+
+// runAttachREPL runs the given commands, then reads further commands from a
+// prompt and sends them over a single IPC connection until the user quits or
+// the daemon goes away
+func runAttachREPL(v *view.View, client *ipc.Client, commands []string) error {
+	conn, err := client.Open(CLIArgs.RetryAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer conn.Close()
+
+	r := &repl{v: v, client: client, conn: conn}
+	for _, command := range commands {
+		if quit, err := r.handleLine(command); quit || err != nil {
+			return err
+		}
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !isTerminal(fd) {
+		return r.runLines(os.Stdin)
+	}
+
+	historyPath, err := replHistoryPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate history file: %w", err)
+	}
+
+	editor := &lineEditor{in: os.Stdin, out: os.Stdout, history: loadReplHistory(historyPath)}
+	editor.complete = func(line string, pos int) (string, int, bool) {
+		return r.complete(editor, line, pos)
+	}
+
+	v.PrintLn("Interactive mode. Type 'help' for commands, 'quit' or Ctrl-D to leave.")
+	for {
+		editor.prompt = r.prompt()
+
+		// The terminal is raw only while a line is edited, so command output
+		// is printed normally
+		state, err := makeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("failed to set up terminal: %w", err)
+		}
+		line, err := editor.ReadLine()
+		restoreTerminal(fd, state)
+
+		if err == io.EOF {
+			v.PrintLn("")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if quit, err := r.handleLine(line); quit || err != nil {
+			return err
+		}
+	}
+}
+
+// runLines executes commands read line by line when stdin is not a terminal
+func (r *repl) runLines(input io.Reader) error {
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		if quit, err := r.handleLine(scanner.Text()); quit || err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handleLine executes one line of input. It reports whether the user asked
+// to quit; an error means the daemon can no longer be reached.
+func (r *repl) handleLine(line string) (bool, error) {
+	line = strings.TrimSpace(line)
+	switch line {
+	case "":
+		return false, nil
+	case "quit", "exit", "q":
+		return true, nil
+	}
+
+	r.variables, r.breakpointIDs = nil, nil
+
+	response, err := r.send(line, CLIArgs.JSON)
+	if err != nil {
+		return true, fmt.Errorf("lost connection to daemon: %w", err)
+	}
+	if !response.Success && len(response.Results) == 0 {
+		r.v.PrintErrorLn(response.Error)
+		return false, nil
+	}
+
+	for _, result := range response.Results {
+		switch {
+		case CLIArgs.JSON:
+			r.v.OutputJSON(result.Command, result.Success, result.Error, result.Result)
+		case !result.Success:
+			r.v.PrintErrorLn(fmt.Sprintf("Command '%s' failed: %s", result.Command, result.Error))
+		default:
+			displayCommandResult(r.v, result)
+		}
+	}
+	return false, nil
+}
+
+// send executes a line of commands on the open connection, allowing for
+// commands such as 'wait' that hold the request open
+func (r *repl) send(line string, jsonOutput bool) (*ipc.CommandResponse, error) {
+	timeout := defaultIPCTimeout
+	if longest := daemon.RequestTimeout([]string{line}); longest > 0 {
+		timeout = longest + defaultIPCTimeout
+	}
+	r.client.SetTimeout(timeout)
+	return r.conn.SendCommands([]string{line}, jsonOutput)
+}
+
+// query runs a command for the prompt or completion and returns its result
+// as decoded JSON, or nil if it failed
+func (r *repl) query(command string) interface{} {
+	response, err := r.send(command, true)
+	if err != nil || len(response.Results) == 0 || !response.Results[0].Success {
+		return nil
+	}
+	return response.Results[0].Result
+}
+
+// prompt shows where the script is stopped, e.g. "(Order.php:42) "
+func (r *repl) prompt() string {
+	statusMap, ok := r.query("status").(map[string]interface{})
+	if !ok {
+		return "(no session) "
+	}
+	status, _ := statusMap["status"].(string)
+	filename, _ := statusMap["filename"].(string)
+	line, _ := statusMap["line"].(float64)
+	if status != "break" || filename == "" {
+		return fmt.Sprintf("(%s) ", status)
+	}
+	return fmt.Sprintf("(%s:%d) ", filepath.Base(strings.TrimPrefix(filename, "file://")), int(line))
+}
+
+// complete completes the word before the cursor: a command name, a
+// breakpoint ID for commands that take one, or a variable name. Ambiguous
+// choices are listed on out.
+func (r *repl) complete(out io.Writer, line string, pos int) (string, int, bool) {
+	before := line[:pos]
+	start := strings.LastIndexAny(before, " ;") + 1
+	word := before[start:]
+
+	var candidates []string
+	command := ""
+	if fields := strings.Fields(before[strings.LastIndex(before, ";")+1:]); len(fields) > 0 {
+		command = fields[0]
+	}
+	switch {
+	case strings.TrimSpace(before[strings.LastIndex(before, ";")+1:start]) == "":
+		candidates = daemon.CommandNames
+	case strings.HasPrefix(word, "$"):
+		candidates = r.completionVariables()
+	case command == "delete" || command == "del" || command == "breakpoint_remove" ||
+		command == "disable" || command == "enable":
+		candidates = r.completionBreakpointIDs()
+	case command == "help" || command == "h":
+		candidates = daemon.CommandNames
+	case command == "print" || command == "p" || command == "eval" || command == "e" ||
+		command == "set" || command == "watch" || command == "property_get":
+		candidates = r.completionVariables()
+	}
+
+	matches := matchingCandidates(candidates, word)
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	completion := commonPrefix(matches)
+	if len(matches) == 1 {
+		completion += " "
+	} else if completion == word {
+		// Nothing to add: show the choices above the prompt
+		fmt.Fprintf(out, "%s\n", strings.Join(matches, "  "))
+		return "", 0, false
+	}
+
+	newLine := line[:start] + completion + line[pos:]
+	return newLine, start + len(completion), true
+}
+
+// completionVariables returns the variable names of the local context at
+// the current stop
+func (r *repl) completionVariables() []string {
+	if r.variables != nil {
+		return r.variables
+	}
+	r.variables = []string{}
+	if ctxMap, ok := r.query("context local").(map[string]interface{}); ok {
+		vars, _ := ctxMap["variables"].([]interface{})
+		for _, item := range vars {
+			if varMap, ok := item.(map[string]interface{}); ok {
+				if name, ok := varMap["name"].(string); ok && name != "" {
+					r.variables = append(r.variables, name)
+				}
+			}
+		}
+	}
+	return r.variables
+}
+
+// completionBreakpointIDs returns the IDs of the session's breakpoints
+func (r *repl) completionBreakpointIDs() []string {
+	if r.breakpointIDs != nil {
+		return r.breakpointIDs
+	}
+	r.breakpointIDs = []string{}
+	if infoMap, ok := r.query("info breakpoints").(map[string]interface{}); ok {
+		bps, _ := infoMap["breakpoints"].([]interface{})
+		for _, item := range bps {
+			if bpMap, ok := item.(map[string]interface{}); ok {
+				if id, ok := bpMap["id"].(string); ok && id != "" {
+					r.breakpointIDs = append(r.breakpointIDs, id)
+				}
+			}
+		}
+	}
+	return r.breakpointIDs
+}
+
+// matchingCandidates returns the sorted, distinct candidates starting with prefix
+func matchingCandidates(candidates []string, prefix string) []string {
+	seen := make(map[string]bool)
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) && !seen[candidate] {
+			seen[candidate] = true
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// commonPrefix returns the longest prefix shared by all words
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReplHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h := loadReplHistory(path)
+	h.Add("break :42")
+	h.Add("run")
+	h.Add("run")
+	h.Add("  ")
+
+	if h.Len() != 2 || h.At(0) != "run" || h.At(1) != "break :42" {
+		t.Fatalf("history = %v, want [break :42 run]", h.entries)
+	}
+
+	// The history survives into the next session
+	reloaded := loadReplHistory(path)
+	if !reflect.DeepEqual(reloaded.entries, h.entries) {
+		t.Errorf("reloaded history = %v, want %v", reloaded.entries, h.entries)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "break :42\nrun\n" {
+		t.Errorf("history file = %q", data)
+	}
+}
+
+func TestReplHistoryPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path, err := replHistoryPath()
+	if err != nil {
+		t.Fatalf("replHistoryPath() error = %v", err)
+	}
+	if want := filepath.Join(home, ".xdebug-cli", "history"); path != want {
+		t.Errorf("replHistoryPath() = %q, want %q", path, want)
+	}
+
+	// The history holds the commands typed, so only the user may read it
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("history directory mode = %o, want 700", perm)
+	}
+}
+
+func TestReplComplete_CommandNames(t *testing.T) {
+	r := &repl{}
+
+	tests := []struct {
+		name     string
+		line     string
+		wantLine string
+		wantOK   bool
+	}{
+		{name: "unique command", line: "tbr", wantLine: "tbreak ", wantOK: true},
+		{name: "common prefix", line: "wat", wantLine: "watch", wantOK: true}, // watch and watches share a prefix
+		{name: "command after separator", line: "step; unw", wantLine: "step; unwatch ", wantOK: true},
+		{name: "help topic", line: "help feat", wantLine: "help feature ", wantOK: true},
+		{name: "no match", line: "zzz", wantLine: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, pos, ok := r.complete(nil, tt.line, len(tt.line))
+			if ok != tt.wantOK || line != tt.wantLine {
+				t.Errorf("complete(%q) = %q, %v, want %q, %v", tt.line, line, ok, tt.wantLine, tt.wantOK)
+			}
+			if ok && pos != len(line) {
+				t.Errorf("complete(%q) cursor = %d, want %d", tt.line, pos, len(line))
+			}
+		})
+	}
+}
+
+func TestReplComplete_CachedCandidates(t *testing.T) {
+	r := &repl{variables: []string{"$user", "$userId", "$total"}, breakpointIDs: []string{"12", "15"}}
+
+	if line, _, ok := r.complete(nil, "print $to", 9); !ok || line != "print $total " {
+		t.Errorf("variable completion = %q, %v", line, ok)
+	}
+	if line, _, ok := r.complete(nil, "eval $us", 8); !ok || line != "eval $user" {
+		t.Errorf("common prefix completion = %q, %v", line, ok)
+	}
+	var out bytes.Buffer
+	if _, _, ok := r.complete(&out, "delete 1", 8); ok || out.String() != "12  15\n" {
+		t.Errorf("ambiguous completion = %v, listed %q", ok, out.String())
+	}
+	if line, _, ok := r.complete(nil, "disable 15", 10); !ok || line != "disable 15 " {
+		t.Errorf("breakpoint ID completion = %q, %v", line, ok)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"os"
 
 	"github.com/console/xdebug-cli/internal/cfg"
+	"github.com/console/xdebug-cli/internal/daemon"
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
 	"github.com/spf13/cobra"
 )
 
@@ -16,10 +19,24 @@ var (
 // CLIArgs holds global command-line parameters
 var CLIArgs cfg.CLIParameter
 
+// configuredFlags records the flags that applyConfig filled from config files or the environment
+var configuredFlags = map[string]bool{}
+
 var rootCmd = &cobra.Command{
 	Use:   "xdebug-cli",
 	Short: "A CLI tool for PHP debugging with Xdebug/DBGp protocol",
-	Long:  `xdebug-cli is a command-line DBGp client for debugging PHP applications via Xdebug.`,
+	Long: `xdebug-cli is a command-line DBGp client for debugging PHP applications via Xdebug.
+
+Settings can also come from a config file: .xdebug-cli.yaml in the current
+directory or a parent, and ~/.xdebug-cli/config.yaml. XDEBUG_CLI_* environment
+variables override config files, and flags override both. Run
+'xdebug-cli config show' to see the effective configuration.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := applyConfig(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
 }
 
 var versionCmd = &cobra.Command{
@@ -35,10 +52,94 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&CLIArgs.Host, "host", "l", "0.0.0.0", "Host address to listen on for Xdebug connections")
 	rootCmd.PersistentFlags().IntVarP(&CLIArgs.Port, "port", "p", 9003, "Port number to listen on for Xdebug connections")
 	rootCmd.PersistentFlags().BoolVar(&CLIArgs.JSON, "json", false, "Output results in JSON format")
+	rootCmd.PersistentFlags().StringArrayVar(&CLIArgs.PathMappings, "path-map", []string{}, "Map a remote (Xdebug) path to a local path, as remote=local (repeatable)")
+
+	rootCmd.PersistentFlags().StringVar(&CLIArgs.Profile, "profile", "", "Config file profile to apply (env: XDEBUG_CLI_PROFILE)")
 
 	rootCmd.AddCommand(versionCmd)
 }
 
+// loadConfig loads the config files and environment overrides for the
+// current directory and user
+func loadConfig() (*cfg.Config, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	homeDir, _ := os.UserHomeDir()
+
+	return cfg.Load(cfg.LoadOptions{
+		WorkDir: workDir,
+		HomeDir: homeDir,
+		Profile: CLIArgs.Profile,
+	})
+}
+
+// applyConfig sets every flag of cmd that was not given on the command line
+// from the config files and environment
+func applyConfig(cmd *cobra.Command) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range config.Settings() {
+		if setting.Key.Command != "" && setting.Key.Command != cmd.Name() {
+			continue
+		}
+		flag := cmd.Flags().Lookup(setting.Key.FlagName())
+		if flag == nil || flag.Changed {
+			continue
+		}
+		for _, value := range setting.Values {
+			if err := cmd.Flags().Set(flag.Name, value); err != nil {
+				return fmt.Errorf("invalid %s from %s: %w", setting.Key.Name, setting.Source, err)
+			}
+		}
+		configuredFlags[flag.Name] = true
+	}
+
+	return nil
+}
+
+// pathMapperFromArgs builds the path mapper from --path-map flags.
+// Returns nil if no mappings are configured.
+func pathMapperFromArgs() (*dbgp.PathMapper, error) {
+	if len(CLIArgs.PathMappings) == 0 {
+		return nil, nil
+	}
+	return dbgp.ParsePathMappings(CLIArgs.PathMappings)
+}
+
+// addIPCFlags adds the flags for reaching a daemon over TCP to cmd
+func addIPCFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&CLIArgs.IPCAddr, "ipc-addr", "", "Connect to the daemon's TCP listener at host:port instead of looking up --port locally")
+	cmd.Flags().StringVar(&CLIArgs.IPCToken, "ipc-token", "", "Shared token of the daemon's TCP listener (env: XDEBUG_CLI_IPC_TOKEN)")
+}
+
+// daemonClient returns an IPC client for the daemon at --ipc-addr, or for
+// the daemon registered for --port on this machine
+func daemonClient() (*ipc.Client, error) {
+	if CLIArgs.IPCAddr != "" {
+		if CLIArgs.IPCToken == "" {
+			return nil, fmt.Errorf("--ipc-addr requires --ipc-token (or XDEBUG_CLI_IPC_TOKEN)")
+		}
+		client := ipc.NewTCPClient(CLIArgs.IPCAddr)
+		client.SetToken(CLIArgs.IPCToken)
+		return client, nil
+	}
+
+	registry, err := daemon.NewSessionRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session registry: %w", err)
+	}
+	sessionInfo, err := registry.Get(CLIArgs.Port)
+	if err != nil {
+		return nil, fmt.Errorf("no daemon running on port %d. Start with:\n  xdebug-cli daemon start", CLIArgs.Port)
+	}
+	return sessionInfo.IPCClient(), nil
+}
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
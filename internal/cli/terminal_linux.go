@@ -0,0 +1,9 @@
+package cli
+
+import "syscall"
+
+// Terminal mode ioctl requests, see terminal.go
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/daemon"
+	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/spf13/cobra"
+)
+
+var traceCmd = &cobra.Command{
+	Use:   "trace",
+	Short: "Record executed lines of a stopped script as JSON lines",
+	Long: `Step through the script from where it is stopped and write one JSON line
+per executed statement (step, filename, line, depth and the values of --vars).
+
+Stepping stops at the first condition that is met: --to line reached, the
+current function returned (--return), --when expression true, --steps
+statements executed, --timeout elapsed, or the script ended. This records
+execution without enabling Xdebug's trace mode.
+
+Examples:
+  # Trace until the current function returns, recording two variables
+  xdebug-cli trace --return --vars '$total,$item'
+
+  # Trace at most 200 statements into a file, stepping over calls
+  xdebug-cli trace --over --steps 200 --output trace.jsonl
+
+  # Trace until a condition holds
+  xdebug-cli trace --when '$total > 100'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runTraceCmd(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	traceCmd.Flags().BoolVar(&CLIArgs.TraceOver, "over", false, "Step over function calls instead of into them")
+	traceCmd.Flags().StringVar(&CLIArgs.TraceTo, "to", "", "Stop at this line (:line or file:line)")
+	traceCmd.Flags().BoolVar(&CLIArgs.TraceReturn, "return", false, "Stop when the current function returns")
+	traceCmd.Flags().IntVar(&CLIArgs.TraceSteps, "steps", daemon.DefaultRecordSteps, "Maximum number of statements to execute")
+	traceCmd.Flags().StringVar(&CLIArgs.TraceWhen, "when", "", "Stop once this PHP expression is true")
+	traceCmd.Flags().StringSliceVar(&CLIArgs.TraceVars, "vars", []string{}, "Variables to record with every statement (comma-separated)")
+	traceCmd.Flags().IntVar(&CLIArgs.TraceTimeout, "timeout", int(daemon.DefaultRecordTimeout/time.Second), "Seconds to trace before stopping")
+	traceCmd.Flags().StringVarP(&CLIArgs.TraceOutput, "output", "o", "", "File to write JSON lines to (default: stdout)")
+	traceCmd.Flags().StringVar(&CLIArgs.Session, "session", "", "ID of the debug session to trace (default: most recently broken session)")
+	traceCmd.Flags().IntVar(&CLIArgs.RetryAttempts, "retry", ipc.DefaultRetryAttempts, "Number of connection retry attempts (with exponential backoff)")
+	addIPCFlags(traceCmd)
+	rootCmd.AddCommand(traceCmd)
+}
+
+// buildRecordCommand translates the trace flags into a 'record' command
+func buildRecordCommand() string {
+	parts := []string{"record"}
+	if CLIArgs.TraceOver {
+		parts = append(parts, "--over")
+	}
+	if CLIArgs.TraceTo != "" {
+		parts = append(parts, "--to", CLIArgs.TraceTo)
+	}
+	if CLIArgs.TraceReturn {
+		parts = append(parts, "--return")
+	}
+	parts = append(parts, "--steps", fmt.Sprint(CLIArgs.TraceSteps))
+	if len(CLIArgs.TraceVars) > 0 {
+		parts = append(parts, "--vars", strings.Join(CLIArgs.TraceVars, ","))
+	}
+	parts = append(parts, "--timeout", fmt.Sprint(CLIArgs.TraceTimeout))
+	// --when consumes the rest of the command, so it goes last
+	if CLIArgs.TraceWhen != "" {
+		parts = append(parts, "--when", CLIArgs.TraceWhen)
+	}
+	return strings.Join(parts, " ")
+}
+
+// runTraceCmd sends a 'record' command to the daemon and writes the
+// recorded statements as JSON lines
+func runTraceCmd() error {
+	if CLIArgs.TraceSteps < 1 {
+		return fmt.Errorf("--steps must be at least 1")
+	}
+	if CLIArgs.TraceTimeout < 1 {
+		return fmt.Errorf("--timeout must be at least 1 second")
+	}
+
+	client, err := daemonClient()
+	if err != nil {
+		return err
+	}
+	client.SetSession(CLIArgs.Session)
+	client.SetTimeout(time.Duration(CLIArgs.TraceTimeout)*time.Second + 5*time.Second)
+
+	response, err := client.SendCommandsWithRetry([]string{buildRecordCommand()}, true, CLIArgs.RetryAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon at %s\nThe daemon may have crashed or ended.", client.Address())
+	}
+	if !response.Success {
+		return fmt.Errorf("command execution failed: %s", response.Error)
+	}
+	if len(response.Results) == 0 {
+		return fmt.Errorf("no result returned by the daemon")
+	}
+	result := response.Results[0]
+	if !result.Success {
+		return fmt.Errorf("trace failed: %s", result.Error)
+	}
+	recordMap, ok := result.Result.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected trace result: %v", result.Result)
+	}
+
+	var out io.Writer = os.Stdout
+	if CLIArgs.TraceOutput != "" {
+		file, err := os.Create(CLIArgs.TraceOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", CLIArgs.TraceOutput, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeTraceLines(out, recordMap); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Recorded %v steps, stopped: %v\n", recordMap["steps"], recordMap["stopped"])
+	if errMsg, ok := recordMap["error"].(string); ok {
+		return fmt.Errorf("stepping failed: %s", errMsg)
+	}
+	return nil
+}
+
+// writeTraceLines writes each recorded statement as one JSON line
+func writeTraceLines(out io.Writer, recordMap map[string]interface{}) error {
+	trace, _ := recordMap["trace"].([]interface{})
+	encoder := json.NewEncoder(out)
+	for _, statement := range trace {
+		if err := encoder.Encode(statement); err != nil {
+			return fmt.Errorf("failed to write trace: %w", err)
+		}
+	}
+	return nil
+}
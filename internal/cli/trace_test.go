@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/cfg"
+)
+
+func TestBuildRecordCommand(t *testing.T) {
+	saved := CLIArgs
+	defer func() { CLIArgs = saved }()
+
+	tests := []struct {
+		name string
+		args cfg.CLIParameter
+		want string
+	}{
+		{
+			name: "defaults",
+			args: cfg.CLIParameter{TraceSteps: 1000, TraceTimeout: 60},
+			want: "record --steps 1000 --timeout 60",
+		},
+		{
+			name: "all flags with expression last",
+			args: cfg.CLIParameter{TraceOver: true, TraceTo: "a.php:9", TraceReturn: true, TraceSteps: 5,
+				TraceVars: []string{"$a", "$b"}, TraceTimeout: 10, TraceWhen: "$a > 1"},
+			want: "record --over --to a.php:9 --return --steps 5 --vars $a,$b --timeout 10 --when $a > 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CLIArgs = tt.args
+			if got := buildRecordCommand(); got != tt.want {
+				t.Errorf("buildRecordCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTraceLines(t *testing.T) {
+	recordMap := map[string]interface{}{
+		"trace": []interface{}{
+			map[string]interface{}{"step": 0.0, "filename": "/app/a.php", "line": 3.0, "depth": 1.0},
+			map[string]interface{}{"step": 1.0, "filename": "/app/a.php", "line": 4.0, "depth": 1.0},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := writeTraceLines(&out, recordMap); err != nil {
+		t.Fatalf("writeTraceLines failed: %v", err)
+	}
+
+	want := `{"depth":1,"filename":"/app/a.php","line":3,"step":0}
+{"depth":1,"filename":"/app/a.php","line":4,"step":1}
+`
+	if out.String() != want {
+		t.Errorf("writeTraceLines wrote %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,892 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/daemon"
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/console/xdebug-cli/internal/view"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Full-screen debugger for a running daemon session",
+	Long: `Open a full-screen view of a running daemon session.
+
+The screen shows the source around the current line, the local variables,
+the call stack and the breakpoints. Everything goes through the daemon's
+commands, so it works with daemons started any way.
+
+Keys:
+  s / n / o      Step into, step over, step out (finish)
+  r / p          Continue to the next breakpoint, pause a running script
+  b              Toggle a breakpoint on the source cursor line
+  tab            Switch between source, locals, stack and breakpoints
+  up/down, j/k   Move the cursor (pgup/pgdown, g/G jump)
+  enter          Expand a variable, select a stack frame, or show a
+                 breakpoint's line
+  q              Quit (the daemon keeps running)
+
+Source is read from local files, falling back to Xdebug's copy. Use
+--path-map when the script runs in a container.
+
+Examples:
+  xdebug-cli tui
+  xdebug-cli tui -p 9004 --session 2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runTUICmd(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	tuiCmd.Flags().IntVar(&CLIArgs.RetryAttempts, "retry", ipc.DefaultRetryAttempts, "Number of connection retry attempts (with exponential backoff)")
+	tuiCmd.Flags().StringVar(&CLIArgs.Session, "session", "", "ID of the debug session to show (default: most recently broken session)")
+	addIPCFlags(tuiCmd)
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// tuiPollInterval is how often the screen checks the terminal size and, if
+// the daemon's events are not available, whether a running script stopped
+const tuiPollInterval = 250 * time.Millisecond
+
+// tuiPane identifies a pane of the full-screen view
+type tuiPane int
+
+const (
+	paneSource tuiPane = iota
+	paneLocals
+	paneStack
+	paneBreakpoints
+	paneCount
+)
+
+// tuiFrame is a stack frame as listed by the stack command
+type tuiFrame struct {
+	depth    int
+	function string
+	file     string
+	line     int
+}
+
+// tuiBreakpoint is a breakpoint as listed by info breakpoints
+type tuiBreakpoint struct {
+	id       string
+	typ      string
+	state    string
+	file     string
+	line     int
+	function string
+}
+
+// tuiVar is a variable of the locals pane. Children are fetched the first
+// time the variable is expanded.
+type tuiVar struct {
+	prop     view.JSONProperty
+	level    int
+	expanded bool
+	children []*tuiVar
+}
+
+// tui is a full-screen session over one IPC connection
+type tui struct {
+	v      *view.View
+	client *ipc.Client
+	conn   *ipc.Conn
+	mapper *dbgp.PathMapper
+	out    io.Writer
+
+	width, height int
+	focus         tuiPane
+	message       string // last error or note, shown on the status line
+	fatal         error  // set when the daemon can no longer be reached
+
+	status string
+	file   string // file of the selected frame, as a local path
+	line   int    // line of the selected frame
+
+	sourceFile string
+	source     []string
+	cursor     int // source line under the cursor, 1-indexed
+
+	stack       []tuiFrame
+	frame       int // index of the selected frame
+	stackCursor int
+
+	vars      []*tuiVar
+	varCursor int
+
+	breakpoints []tuiBreakpoint
+	bpCursor    int
+}
+
+// runTUICmd connects to a running daemon and runs the full-screen view
+func runTUICmd() error {
+	if !isTerminal(int(os.Stdin.Fd())) || !isTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("tui needs a terminal; use 'xdebug-cli attach -i' for piped input")
+	}
+
+	v := view.NewView()
+	pathMapper, err := pathMapperFromArgs()
+	if err != nil {
+		return err
+	}
+	v.SetPathMapper(pathMapper)
+
+	client, err := daemonClient()
+	if err != nil {
+		return err
+	}
+	client.SetSession(CLIArgs.Session)
+	conn, err := client.Open(CLIArgs.RetryAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer conn.Close()
+
+	t := &tui{v: v, client: client, conn: conn, mapper: pathMapper, out: os.Stdout}
+	return t.run()
+}
+
+// run switches the terminal to the alternate screen and handles keys until
+// the user quits
+func (t *tui) run() error {
+	fd := int(os.Stdin.Fd())
+	state, err := makeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer restoreTerminal(fd, state)
+
+	fmt.Fprint(t.out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(t.out, "\x1b[?25h\x1b[?1049l")
+
+	// Stop the key reader and the event subscription when the screen closes
+	stop := make(chan struct{})
+	keys := make(chan string)
+	input, restoreInput := openKeyInput(fd)
+	go readKeys(input, keys, stop)
+	defer func() {
+		close(stop)
+		restoreInput()
+	}()
+	events := t.subscribe(stop)
+
+	ticker := time.NewTicker(tuiPollInterval)
+	defer ticker.Stop()
+
+	t.refresh()
+	t.draw()
+	for t.fatal == nil {
+		select {
+		case key, ok := <-keys:
+			if !ok || t.handleKey(key) {
+				return nil
+			}
+			t.draw()
+		case event, ok := <-events:
+			if !ok {
+				// Without events, fall back to polling
+				events = nil
+				continue
+			}
+			if t.status == dbgp.StateRunning.String() && (event.Type == daemon.EventBreak || event.Type == daemon.EventStopped) {
+				t.refresh()
+				t.draw()
+			}
+		case <-ticker.C:
+			if events == nil && t.status == dbgp.StateRunning.String() {
+				t.pollRunning()
+				t.draw()
+			} else if width, height := t.size(); width != t.width || height != t.height {
+				t.draw()
+			}
+		}
+	}
+	return t.fatal
+}
+
+// subscribe streams the daemon's events until stop is closed. The channel is
+// closed when the stream ends, e.g. for a daemon without events.
+func (t *tui) subscribe(stop <-chan struct{}) <-chan daemon.Event {
+	events := make(chan daemon.Event)
+	go func() {
+		defer close(events)
+		_ = t.client.Events(stop, func(line []byte) error {
+			var event daemon.Event
+			if err := json.Unmarshal(line, &event); err != nil {
+				return err
+			}
+			select {
+			case events <- event:
+				return nil
+			case <-stop:
+				return errTUIClosed
+			}
+		})
+	}()
+	return events
+}
+
+// errTUIClosed ends the goroutines of a closed screen
+var errTUIClosed = errors.New("tui closed")
+
+// openKeyInput returns the terminal input for readKeys and a function that
+// interrupts a pending read and restores the terminal. Reads go through a
+// non-blocking duplicate of fd so that a read deadline can interrupt them;
+// if that is not possible, stdin is read directly.
+func openKeyInput(fd int) (*os.File, func()) {
+	dup, err := syscall.Dup(fd)
+	if err != nil {
+		return os.Stdin, func() {}
+	}
+	if err := syscall.SetNonblock(dup, true); err != nil {
+		syscall.Close(dup)
+		return os.Stdin, func() {}
+	}
+	input := os.NewFile(uintptr(dup), "stdin")
+	return input, func() {
+		_ = input.SetReadDeadline(time.Now())
+		input.Close()
+		// The duplicate shares the non-blocking flag with the terminal
+		_ = syscall.SetNonblock(fd, false)
+	}
+}
+
+// readKeys sends the keys read from input until reading fails or stop is
+// closed, then closes keys
+func readKeys(input io.Reader, keys chan<- string, stop <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := input.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			select {
+			case keys <- key:
+			case <-stop:
+				return
+			}
+		}
+	}
+}
+
+// parseKeys names the keys in a chunk of raw terminal input: "up", "down",
+// "pgup", "pgdown", "home", "end", "enter", "tab", "backtab", "esc",
+// "ctrl-c", or the character typed
+func parseKeys(data []byte) []string {
+	sequences := []struct{ seq, name string }{
+		{"[A", "up"}, {"[B", "down"}, {"[C", "right"}, {"[D", "left"},
+		{"[5~", "pgup"}, {"[6~", "pgdown"}, {"[H", "home"}, {"[F", "end"},
+		{"[1~", "home"}, {"[4~", "end"}, {"OA", "up"}, {"OB", "down"},
+		{"OH", "home"}, {"OF", "end"}, {"[Z", "backtab"},
+	}
+
+	var keys []string
+	for i := 0; i < len(data); i++ {
+		switch b := data[i]; b {
+		case 0x1b:
+			key := "esc"
+			for _, s := range sequences {
+				if strings.HasPrefix(string(data[i+1:]), s.seq) {
+					key = s.name
+					i += len(s.seq)
+					break
+				}
+			}
+			keys = append(keys, key)
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case '\t':
+			keys = append(keys, "tab")
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		default:
+			if b >= 0x20 && b < 0x7f {
+				keys = append(keys, string(rune(b)))
+			}
+		}
+	}
+	return keys
+}
+
+// handleKey performs the action bound to a key and reports whether to quit
+func (t *tui) handleKey(key string) bool {
+	t.message = ""
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "tab":
+		t.focus = (t.focus + 1) % paneCount
+	case "backtab":
+		t.focus = (t.focus + paneCount - 1) % paneCount
+	case "up", "k":
+		t.moveCursor(-1)
+	case "down", "j":
+		t.moveCursor(1)
+	case "pgup":
+		t.moveCursor(-t.paneHeight())
+	case "pgdown":
+		t.moveCursor(t.paneHeight())
+	case "home", "g":
+		t.moveCursor(-1 << 30)
+	case "end", "G":
+		t.moveCursor(1 << 30)
+	case "s":
+		t.resume("step")
+	case "n":
+		t.resume("next")
+	case "o":
+		t.resume("finish")
+	case "r":
+		if _, ok := t.exec("run --async"); ok {
+			t.status = dbgp.StateRunning.String()
+		}
+	case "p":
+		if _, ok := t.exec("pause"); ok {
+			t.refresh()
+		}
+	case "b":
+		t.toggleBreakpoint()
+	case "enter":
+		t.open()
+	}
+	return false
+}
+
+// moveCursor moves the cursor of the focused pane by delta rows
+func (t *tui) moveCursor(delta int) {
+	switch t.focus {
+	case paneSource:
+		t.cursor = clampCursor(t.cursor-1+delta, len(t.source)) + 1
+	case paneLocals:
+		t.varCursor = clampCursor(t.varCursor+delta, len(visibleVars(t.vars)))
+	case paneStack:
+		t.stackCursor = clampCursor(t.stackCursor+delta, len(t.stack))
+	case paneBreakpoints:
+		t.bpCursor = clampCursor(t.bpCursor+delta, len(t.breakpoints))
+	}
+}
+
+// clampCursor keeps a row index within a list of count rows
+func clampCursor(cursor, count int) int {
+	if cursor >= count {
+		cursor = count - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	return cursor
+}
+
+// open acts on the row under the cursor: it expands a variable, selects a
+// stack frame, or shows the line of a breakpoint
+func (t *tui) open() {
+	switch t.focus {
+	case paneLocals:
+		t.toggleVar()
+	case paneStack:
+		if t.stackCursor < len(t.stack) {
+			if _, ok := t.exec(fmt.Sprintf("frame %d", t.stack[t.stackCursor].depth)); ok {
+				t.refresh()
+			}
+		}
+	case paneBreakpoints:
+		if t.bpCursor < len(t.breakpoints) && t.breakpoints[t.bpCursor].file != "" {
+			bp := t.breakpoints[t.bpCursor]
+			t.loadSource(bp.file)
+			t.cursor = bp.line
+			t.focus = paneSource
+		}
+	}
+}
+
+// resume runs a step command and shows where the script stopped
+func (t *tui) resume(command string) {
+	t.exec(command)
+	t.refresh()
+}
+
+// pollRunning checks whether a running script stopped and refreshes the
+// panes once it did
+func (t *tui) pollRunning() {
+	statusMap, ok := t.query("status").(map[string]interface{})
+	if !ok {
+		return
+	}
+	if status, _ := statusMap["status"].(string); status != dbgp.StateRunning.String() {
+		t.refresh()
+	}
+}
+
+// send executes a command on the open connection
+func (t *tui) send(command string) (*ipc.CommandResult, error) {
+	timeout := defaultIPCTimeout
+	if longest := daemon.RequestTimeout([]string{command}); longest > 0 {
+		timeout = longest + defaultIPCTimeout
+	}
+	t.client.SetTimeout(timeout)
+
+	response, err := t.conn.SendCommands([]string{command}, true)
+	if err != nil {
+		t.fatal = fmt.Errorf("lost connection to daemon: %w", err)
+		return nil, err
+	}
+	if len(response.Results) == 0 {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	result := response.Results[0]
+	if !result.Success {
+		return nil, fmt.Errorf("%s", result.Error)
+	}
+	return &result, nil
+}
+
+// exec runs a command the user asked for; a failure is shown on the status line
+func (t *tui) exec(command string) (interface{}, bool) {
+	result, err := t.send(command)
+	if err != nil {
+		t.message = fmt.Sprintf("%s: %v", command, err)
+		return nil, false
+	}
+	return result.Result, true
+}
+
+// query runs a command to fill a pane and returns its result, or nil if it failed
+func (t *tui) query(command string) interface{} {
+	result, err := t.send(command)
+	if err != nil {
+		return nil
+	}
+	return result.Result
+}
+
+// refresh reloads every pane from the daemon
+func (t *tui) refresh() {
+	t.status, t.file, t.line = "no session", "", 0
+	t.stack, t.vars = nil, nil
+	t.frame, t.stackCursor, t.varCursor = 0, 0, 0
+
+	if statusMap, ok := t.query("status").(map[string]interface{}); ok {
+		t.status, _ = statusMap["status"].(string)
+		filename, _ := statusMap["filename"].(string)
+		line, _ := statusMap["line"].(float64)
+		t.file, t.line = t.localPath(filename), int(line)
+	}
+
+	if t.status == dbgp.StateBreak.String() {
+		t.loadStack()
+		t.loadLocals()
+	}
+	t.loadBreakpoints()
+
+	if t.file != "" {
+		t.loadSource(t.file)
+		t.cursor = t.line
+	}
+}
+
+// loadStack lists the stack and takes the current location from the
+// selected frame
+func (t *tui) loadStack() {
+	frames, _ := t.query("stack").([]interface{})
+	for i, item := range frames {
+		frameMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		depthStr, _ := frameMap["depth"].(string)
+		depth, _ := strconv.Atoi(depthStr)
+		function, _ := frameMap["function"].(string)
+		file, _ := frameMap["file"].(string)
+		line, _ := frameMap["line"].(float64)
+
+		frame := tuiFrame{depth: depth, function: function, file: t.localPath(file), line: int(line)}
+		t.stack = append(t.stack, frame)
+		if selected, _ := frameMap["selected"].(bool); selected {
+			t.frame, t.stackCursor = i, i
+			t.file, t.line = frame.file, frame.line
+		}
+	}
+}
+
+// loadLocals lists the variables of the selected frame
+func (t *tui) loadLocals() {
+	ctxMap, ok := t.query("context local").(map[string]interface{})
+	if !ok {
+		return
+	}
+	vars, _ := ctxMap["variables"].([]interface{})
+	props := make([]view.JSONProperty, 0, len(vars))
+	for _, item := range vars {
+		if varMap, ok := item.(map[string]interface{}); ok {
+			props = append(props, mapToJSONProperty(varMap))
+		}
+	}
+	t.vars = newTUIVars(props, 0)
+}
+
+// loadBreakpoints lists the session's breakpoints
+func (t *tui) loadBreakpoints() {
+	t.breakpoints = nil
+	if infoMap, ok := t.query("info breakpoints").(map[string]interface{}); ok {
+		bps, _ := infoMap["breakpoints"].([]interface{})
+		for _, item := range bps {
+			bpMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			bp := tuiBreakpoint{}
+			bp.id, _ = bpMap["id"].(string)
+			bp.typ, _ = bpMap["type"].(string)
+			bp.state, _ = bpMap["state"].(string)
+			bp.function, _ = bpMap["function"].(string)
+			filename, _ := bpMap["filename"].(string)
+			bp.file = t.localPath(filename)
+			line, _ := bpMap["line"].(float64)
+			bp.line = int(line)
+			t.breakpoints = append(t.breakpoints, bp)
+		}
+	}
+	t.bpCursor = clampCursor(t.bpCursor, len(t.breakpoints))
+}
+
+// loadSource reads a file for the source pane, from disk when it is
+// accessible locally and from Xdebug otherwise
+func (t *tui) loadSource(file string) {
+	if file == t.sourceFile && t.source != nil {
+		return
+	}
+	t.sourceFile, t.source = file, nil
+
+	if lines, err := t.v.SourceLines(file); err == nil {
+		t.source = lines
+		return
+	}
+	if sourceMap, ok := t.query("source " + file).(map[string]interface{}); ok {
+		if source, _ := sourceMap["source"].(string); source != "" {
+			t.source = strings.Split(source, "\n")
+		}
+	}
+}
+
+// localPath maps a file reported by the daemon to a local path
+func (t *tui) localPath(file string) string {
+	file = t.mapper.ToLocal(file)
+	if strings.HasPrefix(file, "file://") {
+		if parsedURL, err := url.Parse(file); err == nil {
+			return parsedURL.Path
+		}
+	}
+	return file
+}
+
+// toggleBreakpoint removes the line breakpoint under the source cursor, or
+// sets one if there is none
+func (t *tui) toggleBreakpoint() {
+	if t.sourceFile == "" || t.cursor < 1 {
+		t.message = "no source file to set a breakpoint in"
+		return
+	}
+
+	for _, bp := range t.breakpoints {
+		if bp.typ == "line" && bp.file == t.sourceFile && bp.line == t.cursor {
+			if _, ok := t.exec("delete " + bp.id); ok {
+				t.message = fmt.Sprintf("Breakpoint %s removed", bp.id)
+			}
+			t.loadBreakpoints()
+			return
+		}
+	}
+
+	result, ok := t.exec(fmt.Sprintf("break %s:%d", t.sourceFile, t.cursor))
+	if !ok {
+		return
+	}
+	if resultMap, ok := result.(map[string]interface{}); ok {
+		t.message = fmt.Sprintf("Breakpoint %v set at line %d", resultMap["id"], t.cursor)
+		if warning, _ := resultMap["warning"].(string); warning != "" {
+			t.message = "Warning: " + warning
+		}
+	}
+	t.loadBreakpoints()
+}
+
+// toggleVar expands or collapses the variable under the cursor
+func (t *tui) toggleVar() {
+	rows := visibleVars(t.vars)
+	if t.varCursor >= len(rows) {
+		return
+	}
+	v := rows[t.varCursor]
+	if v.prop.NumChildren == 0 {
+		return
+	}
+	if v.expanded {
+		v.expanded = false
+		return
+	}
+
+	if v.children == nil {
+		props := v.prop.Children
+		if len(props) == 0 {
+			result, ok := t.exec("print " + v.prop.FullName)
+			if !ok {
+				return
+			}
+			if propMap, ok := result.(map[string]interface{}); ok {
+				props = mapToJSONProperty(propMap).Children
+			}
+		}
+		v.children = newTUIVars(props, v.level+1)
+	}
+	v.expanded = true
+}
+
+// newTUIVars wraps properties for the locals pane
+func newTUIVars(props []view.JSONProperty, level int) []*tuiVar {
+	vars := make([]*tuiVar, 0, len(props))
+	for _, prop := range props {
+		vars = append(vars, &tuiVar{prop: prop, level: level})
+	}
+	return vars
+}
+
+// visibleVars returns the variables shown in the locals pane, with the
+// children of expanded variables after their parent
+func visibleVars(vars []*tuiVar) []*tuiVar {
+	var rows []*tuiVar
+	for _, v := range vars {
+		rows = append(rows, v)
+		if v.expanded {
+			rows = append(rows, visibleVars(v.children)...)
+		}
+	}
+	return rows
+}
+
+// formatTUIVar formats a row of the locals pane
+func formatTUIVar(v *tuiVar) string {
+	indent := strings.Repeat("  ", v.level)
+	prop := v.prop
+	switch {
+	case prop.NumChildren > 0 && v.expanded:
+		return fmt.Sprintf("%s- %s (%s) [%d children]", indent, prop.Name, prop.Type, prop.NumChildren)
+	case prop.NumChildren > 0:
+		return fmt.Sprintf("%s+ %s (%s) [%d children]", indent, prop.Name, prop.Type, prop.NumChildren)
+	case prop.Value == "":
+		return fmt.Sprintf("%s  %s (%s)", indent, prop.Name, prop.Type)
+	default:
+		return fmt.Sprintf("%s  %s (%s) = %s", indent, prop.Name, prop.Type, view.TryDecodeBase64(prop.Value, prop.Type))
+	}
+}
+
+// size returns the terminal size, or 80x24 if it is unknown
+func (t *tui) size() (int, int) {
+	width, height, err := terminalSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 80, 24
+	}
+	return width, height
+}
+
+// paneHeight returns the rows of the focused pane, used to page through it
+func (t *tui) paneHeight() int {
+	_, _, sourceHeight, localsHeight, stackHeight, bpHeight := tuiLayout(t.width, t.height)
+	heights := map[tuiPane]int{
+		paneSource: sourceHeight, paneLocals: localsHeight,
+		paneStack: stackHeight, paneBreakpoints: bpHeight,
+	}
+	if heights[t.focus] < 2 {
+		return 1
+	}
+	return heights[t.focus] - 1
+}
+
+// draw renders the screen
+func (t *tui) draw() {
+	t.width, t.height = t.size()
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range t.render() {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+	}
+	fmt.Fprint(t.out, b.String())
+}
+
+// tuiLayout splits the screen into the source pane on the left and the
+// locals, stack and breakpoints panes stacked on the right, leaving two rows
+// for the status line and key help. Heights include each pane's title row.
+func tuiLayout(width, height int) (leftWidth, rightWidth, sourceHeight, localsHeight, stackHeight, bpHeight int) {
+	leftWidth = width * 3 / 5
+	rightWidth = width - leftWidth - 1
+	sourceHeight = height - 2
+	if sourceHeight < 0 {
+		sourceHeight = 0
+	}
+	localsHeight = sourceHeight / 2
+	stackHeight = (sourceHeight - localsHeight) / 2
+	bpHeight = sourceHeight - localsHeight - stackHeight
+	return
+}
+
+// render returns the lines of the screen
+func (t *tui) render() []string {
+	leftWidth, rightWidth, sourceHeight, localsHeight, stackHeight, bpHeight := tuiLayout(t.width, t.height)
+
+	// Source pane
+	breakpointLines := make(map[int]bool)
+	for _, bp := range t.breakpoints {
+		if bp.typ == "line" && bp.file == t.sourceFile {
+			breakpointLines[bp.line] = true
+		}
+	}
+	sourceRows := make([]string, len(t.source))
+	for i, content := range t.source {
+		sourceRows[i] = formatSourceRow(i+1, content, i+1 == t.line && t.sourceFile == t.file, breakpointLines[i+1])
+	}
+	sourceTitle := "Source"
+	if t.sourceFile != "" {
+		sourceTitle = "Source: " + t.sourceFile
+		if t.source == nil {
+			sourceRows = []string{"(source not available)"}
+		}
+	}
+	left := renderPane(sourceTitle, sourceRows, t.cursor-1, t.focus == paneSource, leftWidth, sourceHeight)
+
+	// Locals pane
+	var localRows []string
+	for _, v := range visibleVars(t.vars) {
+		localRows = append(localRows, formatTUIVar(v))
+	}
+	right := renderPane("Locals", localRows, t.varCursor, t.focus == paneLocals, rightWidth, localsHeight)
+
+	// Stack pane
+	var stackRows []string
+	for i, frame := range t.stack {
+		marker := " "
+		if i == t.frame {
+			marker = ">"
+		}
+		stackRows = append(stackRows, fmt.Sprintf("%s #%d %s at %s:%d", marker, frame.depth, frame.function, filepath.Base(frame.file), frame.line))
+	}
+	right = append(right, renderPane("Stack", stackRows, t.stackCursor, t.focus == paneStack, rightWidth, stackHeight)...)
+
+	// Breakpoints pane
+	var bpRows []string
+	for _, bp := range t.breakpoints {
+		location := strings.TrimSpace(bp.typ + " " + bp.function)
+		if bp.file != "" {
+			location = fmt.Sprintf("%s:%d", filepath.Base(bp.file), bp.line)
+		}
+		bpRows = append(bpRows, fmt.Sprintf("%-3s %s (%s)", bp.id, location, bp.state))
+	}
+	right = append(right, renderPane("Breakpoints", bpRows, t.bpCursor, t.focus == paneBreakpoints, rightWidth, bpHeight)...)
+
+	lines := make([]string, 0, t.height)
+	for i := 0; i < sourceHeight; i++ {
+		lines = append(lines, left[i]+"│"+right[i])
+	}
+
+	status := t.status
+	if t.status == dbgp.StateBreak.String() && t.file != "" {
+		status = fmt.Sprintf("break at %s:%d", filepath.Base(t.file), t.line)
+	}
+	if t.message != "" {
+		status += " | " + t.message
+	}
+	lines = append(lines, "\x1b[7m"+fitWidth(status, t.width)+"\x1b[0m")
+	lines = append(lines, fitWidth("s step  n next  o out  r run  p pause  b breakpoint  tab pane  enter open  q quit", t.width))
+	return lines[:min(len(lines), t.height)]
+}
+
+// formatSourceRow formats a line of the source pane with markers for the
+// current line (">") and breakpoints ("*")
+func formatSourceRow(lineNum int, content string, current, breakpoint bool) string {
+	marker := " "
+	if current {
+		marker = ">"
+	}
+	bpMarker := " "
+	if breakpoint {
+		bpMarker = "*"
+	}
+	return fmt.Sprintf("%s%s%4d | %s", bpMarker, marker, lineNum, content)
+}
+
+// renderPane renders a titled pane of exactly width columns and height
+// rows, scrolled so the cursor row is visible. The cursor row is
+// highlighted when the pane has the focus.
+func renderPane(title string, rows []string, cursor int, focused bool, width, height int) []string {
+	lines := make([]string, 0, height)
+	if height == 0 {
+		return lines
+	}
+
+	titleStyle := "\x1b[1m"
+	if focused {
+		titleStyle = "\x1b[1;4m"
+	}
+	lines = append(lines, titleStyle+fitWidth(title, width)+"\x1b[0m")
+
+	visible := height - 1
+	offset := scrollOffset(cursor, len(rows), visible)
+	for i := offset; i < offset+visible; i++ {
+		switch {
+		case i >= len(rows):
+			lines = append(lines, fitWidth("", width))
+		case i == cursor && focused:
+			lines = append(lines, "\x1b[7m"+fitWidth(rows[i], width)+"\x1b[0m")
+		default:
+			lines = append(lines, fitWidth(rows[i], width))
+		}
+	}
+	return lines
+}
+
+// scrollOffset returns the first of count rows to show in height rows so
+// the cursor row is centered where possible
+func scrollOffset(cursor, count, height int) int {
+	if count <= height || height <= 0 {
+		return 0
+	}
+	offset := cursor - height/2
+	if offset > count-height {
+		offset = count - height
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+// fitWidth expands tabs and cuts or pads s to exactly width columns
+func fitWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(strings.ReplaceAll(s, "\t", "    "))
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
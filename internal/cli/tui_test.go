@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"s", []string{"s"}},
+		{"\x1b[A\x1b[B", []string{"up", "down"}},
+		{"\x1b[5~j\r", []string{"pgup", "j", "enter"}},
+		{"\t\x1b[Z", []string{"tab", "backtab"}},
+		{"\x1b", []string{"esc"}},
+		{"\x03", []string{"ctrl-c"}},
+	}
+	for _, tt := range tests {
+		if got := parseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestReadKeysStop tests that the key reader ends when the screen closes,
+// even while it waits for input
+func TestReadKeysStop(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	input, restore := openKeyInput(int(r.Fd()))
+	keys := make(chan string)
+	stop := make(chan struct{})
+	go readKeys(input, keys, stop)
+
+	w.Write([]byte("j"))
+	select {
+	case key := <-keys:
+		if key != "j" {
+			t.Errorf("key = %q, want j", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no key read")
+	}
+
+	close(stop)
+	restore()
+	select {
+	case _, ok := <-keys:
+		if ok {
+			t.Error("got a key after stop")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("readKeys did not return after stop")
+	}
+}
+
+func TestScrollOffset(t *testing.T) {
+	tests := []struct {
+		name                  string
+		cursor, count, height int
+		want                  int
+	}{
+		{"fits", 3, 5, 10, 0},
+		{"top", 1, 100, 10, 0},
+		{"centered", 50, 100, 10, 45},
+		{"bottom", 99, 100, 10, 90},
+	}
+	for _, tt := range tests {
+		if got := scrollOffset(tt.cursor, tt.count, tt.height); got != tt.want {
+			t.Errorf("%s: scrollOffset(%d, %d, %d) = %d, want %d", tt.name, tt.cursor, tt.count, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestFitWidth(t *testing.T) {
+	if got := fitWidth("abc", 5); got != "abc  " {
+		t.Errorf("fitWidth pads to %q", got)
+	}
+	if got := fitWidth("\techo", 6); got != "    ec" {
+		t.Errorf("fitWidth cuts to %q", got)
+	}
+	if got := fitWidth("äöü", 2); got != "äö" {
+		t.Errorf("fitWidth counts runes, got %q", got)
+	}
+}
+
+func TestRenderPane(t *testing.T) {
+	rows := []string{"one", "two", "three"}
+	lines := renderPane("Stack", rows, 1, true, 8, 5)
+
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5", len(lines))
+	}
+	if !strings.Contains(lines[0], "Stack   ") {
+		t.Errorf("title = %q", lines[0])
+	}
+	if lines[2] != "\x1b[7mtwo     \x1b[0m" {
+		t.Errorf("cursor row = %q, want it highlighted", lines[2])
+	}
+	if lines[4] != "        " {
+		t.Errorf("padding row = %q", lines[4])
+	}
+
+	// Without the focus the cursor row is plain
+	if lines := renderPane("Stack", rows, 1, false, 8, 5); lines[2] != "two     " {
+		t.Errorf("unfocused cursor row = %q", lines[2])
+	}
+}
+
+func TestTUIRender(t *testing.T) {
+	ui := &tui{
+		width: 100, height: 12,
+		status: "break", file: "/app/index.php", line: 2,
+		sourceFile: "/app/index.php",
+		source:     []string{"<?php", "$a = 1;", "$b = 2;"},
+		cursor:     2,
+		stack:      []tuiFrame{{depth: 0, function: "{main}", file: "/app/index.php", line: 2}},
+		vars: newTUIVars([]view.JSONProperty{
+			{Name: "$a", Type: "int", Value: "1"},
+		}, 0),
+		breakpoints: []tuiBreakpoint{{id: "7", typ: "line", state: "enabled", file: "/app/index.php", line: 3}},
+	}
+
+	lines := ui.render()
+	if len(lines) != 12 {
+		t.Fatalf("got %d lines, want 12", len(lines))
+	}
+	screen := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"Source: /app/index.php",
+		" >   2 | $a = 1;",
+		"*    3 | $b = 2;",
+		"$a (int) = 1",
+		"> #0 {main} at index.php:2",
+		"7   index.php:3 (enabled)",
+		"break at index.php:2",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen lacks %q:\n%s", want, screen)
+		}
+	}
+}
+
+func TestTUIToggleVar(t *testing.T) {
+	ui := &tui{
+		focus: paneLocals,
+		vars: newTUIVars([]view.JSONProperty{
+			{Name: "$items", FullName: "$items", Type: "array", NumChildren: 2, Children: []view.JSONProperty{
+				{Name: "0", FullName: "$items[0]", Type: "string", Value: "a"},
+				{Name: "1", FullName: "$items[1]", Type: "string", Value: "b"},
+			}},
+			{Name: "$n", FullName: "$n", Type: "int", Value: "2"},
+		}, 0),
+	}
+
+	if got := len(visibleVars(ui.vars)); got != 2 {
+		t.Fatalf("collapsed rows = %d, want 2", got)
+	}
+
+	ui.toggleVar()
+	rows := visibleVars(ui.vars)
+	if len(rows) != 4 {
+		t.Fatalf("expanded rows = %d, want 4", len(rows))
+	}
+	if got := formatTUIVar(rows[0]); got != "- $items (array) [2 children]" {
+		t.Errorf("expanded row = %q", got)
+	}
+	if got := formatTUIVar(rows[1]); got != "    0 (string) = a" {
+		t.Errorf("child row = %q", got)
+	}
+
+	ui.toggleVar()
+	if got := formatTUIVar(visibleVars(ui.vars)[0]); got != "+ $items (array) [2 children]" {
+		t.Errorf("collapsed row = %q", got)
+	}
+}
@@ -0,0 +1,183 @@
+package daemon
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+)
+
+// BreakpointSpec describes a breakpoint independently of the DBGp connection it
+// was set on, so it can be re-applied when Xdebug opens a new connection.
+// Key identifies the spec within the store; Xdebug IDs differ per connection.
+type BreakpointSpec struct {
+	Key       int
+	Type      string
+	File      string
+	Line      int
+	Condition string
+	Function  string
+	Exception string
+	State     string
+
+	// HitValue and HitCondition restrict a line breakpoint to some hits
+	HitValue     int
+	HitCondition string
+
+	// RequestedLine is the line asked for when the breakpoint was moved to
+	// an executable Line
+	RequestedLine int
+
+	// Message makes a line breakpoint a logpoint: the message is logged and
+	// execution continues
+	Message string
+}
+
+// Location returns a human-readable location for the breakpoint
+func (b BreakpointSpec) Location() string {
+	switch b.Type {
+	case "call", "return":
+		return fmt.Sprintf("%s %s", b.Type, b.Function)
+	case "conditional":
+		return fmt.Sprintf("when %s", b.Condition)
+	case "exception":
+		if b.Exception == "" {
+			return "exception"
+		}
+		return fmt.Sprintf("exception %s", b.Exception)
+	default:
+		return fmt.Sprintf("%s:%d", b.File, b.Line)
+	}
+}
+
+// BreakpointStore remembers the breakpoints set during a daemon's lifetime.
+// Breakpoint IDs are assigned by Xdebug per connection, so each executor maps
+// the IDs of its own connection to store keys.
+type BreakpointStore struct {
+	mu      sync.RWMutex
+	specs   []BreakpointSpec
+	nextKey int
+}
+
+// NewBreakpointStore creates an empty breakpoint store
+func NewBreakpointStore() *BreakpointStore {
+	return &BreakpointStore{
+		specs: make([]BreakpointSpec, 0),
+	}
+}
+
+// Add records a breakpoint that was set successfully and returns its key
+func (s *BreakpointStore) Add(spec BreakpointSpec) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if spec.State == "" {
+		spec.State = "enabled"
+	}
+	s.nextKey++
+	spec.Key = s.nextKey
+	s.specs = append(s.specs, spec)
+	return spec.Key
+}
+
+// Remove forgets the breakpoint with the given key, returning true if it was known
+func (s *BreakpointStore) Remove(key int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, spec := range s.specs {
+		if spec.Key == key {
+			s.specs = append(s.specs[:i], s.specs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetState updates the enabled/disabled state of a breakpoint
+func (s *BreakpointStore) SetState(key int, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.specs {
+		if s.specs[i].Key == key {
+			s.specs[i].State = state
+			return
+		}
+	}
+}
+
+// Get returns the breakpoint with the given key
+func (s *BreakpointStore) Get(key int) (BreakpointSpec, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, spec := range s.specs {
+		if spec.Key == key {
+			return spec, true
+		}
+	}
+	return BreakpointSpec{}, false
+}
+
+// List returns a copy of all remembered breakpoints
+func (s *BreakpointStore) List() []BreakpointSpec {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	specs := make([]BreakpointSpec, len(s.specs))
+	copy(specs, s.specs)
+	return specs
+}
+
+// Len returns the number of remembered breakpoints
+func (s *BreakpointStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.specs)
+}
+
+// Apply sets every remembered breakpoint on a new client and returns the IDs
+// Xdebug assigned, mapped to store keys. Breakpoints that fail to apply are
+// kept so a later connection can retry them; the failures are returned for logging.
+func (s *BreakpointStore) Apply(client *dbgp.Client) (map[string]int, []error) {
+	specs := s.List()
+
+	ids := make(map[string]int, len(specs))
+	var errs []error
+	for _, spec := range specs {
+		response, err := setBreakpointFromSpec(client, spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore breakpoint %s: %w", spec.Location(), err))
+			continue
+		}
+		if response.HasError() {
+			errs = append(errs, fmt.Errorf("failed to restore breakpoint %s: %s", spec.Location(), response.GetErrorMessage()))
+			continue
+		}
+		ids[response.ID] = spec.Key
+
+		if spec.State == "disabled" {
+			if _, err := client.UpdateBreakpoint(response.ID, "disabled"); err != nil {
+				errs = append(errs, fmt.Errorf("failed to disable breakpoint %s: %w", spec.Location(), err))
+			}
+		}
+	}
+
+	return ids, errs
+}
+
+// setBreakpointFromSpec issues the DBGp command matching the breakpoint type
+func setBreakpointFromSpec(client *dbgp.Client, spec BreakpointSpec) (*dbgp.ProtocolResponse, error) {
+	switch spec.Type {
+	case "call":
+		return client.SetBreakpointToCall(spec.Function)
+	case "return":
+		return client.SetBreakpointToReturn(spec.Function)
+	case "conditional":
+		return client.SetConditionalBreakpoint(spec.Condition)
+	case "exception":
+		return client.SetExceptionBreakpoint(spec.Exception)
+	default:
+		return client.SetLineBreakpoint(spec.File, spec.Line, dbgp.BreakpointOptions{
+			Condition:    spec.Condition,
+			HitValue:     spec.HitValue,
+			HitCondition: spec.HitCondition,
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+// dbgpMessage frames an XML response the way Xdebug sends it
+func dbgpMessage(xml string) string {
+	return fmt.Sprintf("%d\x00%s\x00", len(xml), xml)
+}
+
+func TestBreakpointStore_AddRemoveSetState(t *testing.T) {
+	store := NewBreakpointStore()
+
+	lineKey := store.Add(BreakpointSpec{Type: "line", File: "/var/www/index.php", Line: 10})
+	callKey := store.Add(BreakpointSpec{Type: "call", Function: "main"})
+
+	if lineKey == callKey {
+		t.Errorf("Add() returned duplicate key %d", lineKey)
+	}
+	if store.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", store.Len())
+	}
+
+	specs := store.List()
+	if specs[0].State != "enabled" {
+		t.Errorf("default State = %q, want 'enabled'", specs[0].State)
+	}
+
+	store.SetState(callKey, "disabled")
+	if got := store.List()[1].State; got != "disabled" {
+		t.Errorf("State after SetState = %q, want 'disabled'", got)
+	}
+
+	if !store.Remove(lineKey) {
+		t.Error("Remove() = false, want true")
+	}
+	if store.Remove(lineKey) {
+		t.Error("Remove() second call = true, want false")
+	}
+	if store.Len() != 1 {
+		t.Errorf("Len() after Remove = %d, want 1", store.Len())
+	}
+}
+
+func TestBreakpointSpec_Location(t *testing.T) {
+	tests := []struct {
+		spec BreakpointSpec
+		want string
+	}{
+		{BreakpointSpec{Type: "line", File: "/app/a.php", Line: 42}, "/app/a.php:42"},
+		{BreakpointSpec{Type: "call", Function: "handle"}, "call handle"},
+		{BreakpointSpec{Type: "return", Function: "Order::save"}, "return Order::save"},
+		{BreakpointSpec{Type: "conditional", Condition: "$total < 0"}, "when $total < 0"},
+		{BreakpointSpec{Type: "exception", Exception: "RuntimeException"}, "exception RuntimeException"},
+		{BreakpointSpec{Type: "exception"}, "exception"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.spec.Location(); got != tt.want {
+			t.Errorf("Location() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestBreakpointStore_Apply(t *testing.T) {
+	store := NewBreakpointStore()
+	lineKey := store.Add(BreakpointSpec{Type: "line", File: "/var/www/index.php", Line: 10, State: "disabled"})
+	callKey := store.Add(BreakpointSpec{Type: "call", Function: "main"})
+
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+
+	// A new connection assigns new IDs
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="17"/>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_update" transaction_id="2"/>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="3" id="18"/>`))
+
+	ids, errs := store.Apply(client)
+	if len(errs) != 0 {
+		t.Fatalf("Apply() errors = %v", errs)
+	}
+
+	if ids["17"] != lineKey || ids["18"] != callKey {
+		t.Errorf("Apply() ids = %v, want 17->%d, 18->%d", ids, lineKey, callKey)
+	}
+
+	written := mockConn.writeBuf.String()
+	for _, want := range []string{
+		"-t line -f file:///var/www/index.php -n 10",
+		"breakpoint_update -i 2 -d 17 -s disabled",
+		"-t call -m main",
+	} {
+		if !strings.Contains(written, want) {
+			t.Errorf("Apply() did not send %q, sent: %q", want, written)
+		}
+	}
+}
+
+func TestBreakpointStore_ApplyKeepsFailedBreakpoints(t *testing.T) {
+	store := NewBreakpointStore()
+	store.Add(BreakpointSpec{Type: "line", File: "/var/www/index.php", Line: 10})
+
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1">
+<error code="200"><message>breakpoint could not be set</message></error>
+</response>`))
+
+	_, errs := store.Apply(client)
+	if len(errs) != 1 {
+		t.Fatalf("Apply() errors = %d, want 1", len(errs))
+	}
+	if store.Len() != 1 {
+		t.Errorf("Len() after failed Apply = %d, want 1", store.Len())
+	}
+}
+
+func TestCommandExecutor_RecordsBreakpoints(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+	store := NewBreakpointStore()
+	executor.SetBreakpointStore(store)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="5"/>`))
+
+	result := executor.executeCommand("break", []string{"/var/www/index.php:10"})
+	if !result.Success {
+		t.Fatalf("break failed: %s", result.Error)
+	}
+	if store.Len() != 1 {
+		t.Fatalf("store Len() = %d, want 1", store.Len())
+	}
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_remove" transaction_id="2"/>`))
+
+	result = executor.executeCommand("delete", []string{"5"})
+	if !result.Success {
+		t.Fatalf("delete failed: %s", result.Error)
+	}
+	if store.Len() != 0 {
+		t.Errorf("store Len() after delete = %d, want 0", store.Len())
+	}
+}
+
+func TestCommandExecutor_BreakWithHitCondition(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+	store := NewBreakpointStore()
+	executor.SetBreakpointStore(store)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="5"/>`))
+
+	result := executor.executeCommand("break", []string{"/app/a.php:42", "hits", "%", "10", "if", "$i", ">", "0"})
+	if !result.Success {
+		t.Fatalf("break failed: %s", result.Error)
+	}
+
+	sent := mockConn.writeBuf.String()
+	if !strings.Contains(sent, "-t line -f file:///app/a.php -n 42 -h 10 -o % --") {
+		t.Errorf("break sent %q, want hit value and condition before the expression", sent)
+	}
+	if hits := result.Result.(map[string]interface{})["hits"]; hits != "% 10" {
+		t.Errorf("result hits = %v, want %q", hits, "% 10")
+	}
+
+	specs := store.List()
+	if len(specs) != 1 || specs[0].HitValue != 10 || specs[0].HitCondition != "%" || specs[0].Condition != "$i > 0" {
+		t.Errorf("stored spec = %+v, want hits %% 10 with condition", specs)
+	}
+}
+
+func TestParseHitArgs(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantLocations []string
+		wantValue     int
+		wantCondition string
+		wantErr       bool
+	}{
+		{"no hits", []string{"a.php:1"}, []string{"a.php:1"}, 0, "", false},
+		{"greater or equal", []string{"a.php:1", "hits", ">=", "100"}, []string{"a.php:1"}, 100, ">=", false},
+		{"equal", []string{"a.php:1", "hits", "==", "5"}, []string{"a.php:1"}, 5, "==", false},
+		{"modulo", []string{"a.php:1", "hits", "%", "10"}, []string{"a.php:1"}, 10, "%", false},
+		{"default operator", []string{"a.php:1", "hits", "3"}, []string{"a.php:1"}, 3, ">=", false},
+		{"unknown operator", []string{"a.php:1", "hits", "<", "3"}, nil, 0, "", true},
+		{"missing count", []string{"a.php:1", "hits"}, nil, 0, "", true},
+		{"zero count", []string{"a.php:1", "hits", "0"}, nil, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locations, value, condition, err := parseHitArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHitArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strings.Join(locations, " ") != strings.Join(tt.wantLocations, " ") || value != tt.wantValue || condition != tt.wantCondition {
+				t.Errorf("parseHitArgs(%v) = %v, %d, %q; want %v, %d, %q",
+					tt.args, locations, value, condition, tt.wantLocations, tt.wantValue, tt.wantCondition)
+			}
+		})
+	}
+}
+
+func TestCommandExecutor_TBreakIsNotRemembered(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+	store := NewBreakpointStore()
+	executor.SetBreakpointStore(store)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="7"/>`))
+
+	result := executor.executeCommand("tbreak", []string{"/app/a.php:42"})
+	if !result.Success {
+		t.Fatalf("tbreak failed: %s", result.Error)
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "-t line -f file:///app/a.php -n 42 -r 1") {
+		t.Errorf("tbreak sent %q, want temporary flag -r 1", sent)
+	}
+	if temporary := result.Result.(map[string]interface{})["temporary"]; temporary != true {
+		t.Errorf("result temporary = %v, want true", temporary)
+	}
+	if store.Len() != 0 {
+		t.Errorf("store Len() = %d, want 0: temporary breakpoints are not re-applied", store.Len())
+	}
+}
+
+func TestCommandExecutor_BreakMultipleLocations(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	client.GetSession().SetCurrentLocation("file:///app/c.php", 5)
+	executor := NewCommandExecutor(client)
+	store := NewBreakpointStore()
+	executor.SetBreakpointStore(store)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="1"/>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="2">
+<error code="200"><message>breakpoint could not be set</message></error>
+</response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="3" id="3"/>`))
+
+	result := executor.executeCommand("break", []string{"/app/a.php:10", "/app/b.php:20", ":30", "if", "$x", ">", "1"})
+	if !result.Success {
+		t.Fatalf("break failed: %s", result.Error)
+	}
+
+	resultMap := result.Result.(map[string]interface{})
+	bps := resultMap["breakpoints"].([]view.JSONBreakpointResult)
+	if len(bps) != 3 || resultMap["failed"] != 1 {
+		t.Fatalf("result = %+v, want 3 breakpoints with 1 failure", resultMap)
+	}
+
+	want := []view.JSONBreakpointResult{
+		{ID: "1", Location: "/app/a.php:10", Condition: "$x > 1", Line: 10},
+		{Location: "/app/b.php:20", Condition: "$x > 1", Error: "breakpoint could not be set"},
+		{ID: "3", Location: "file:///app/c.php:30", Condition: "$x > 1", Line: 30},
+	}
+	for i := range want {
+		if bps[i] != want[i] {
+			t.Errorf("breakpoint %d = %+v, want %+v", i, bps[i], want[i])
+		}
+	}
+
+	if strings.Count(mockConn.writeBuf.String(), "breakpoint_set") != 3 {
+		t.Errorf("sent %q, want one breakpoint_set per location", mockConn.writeBuf.String())
+	}
+	if store.Len() != 2 {
+		t.Errorf("store Len() = %d, want 2 (failed location not remembered)", store.Len())
+	}
+}
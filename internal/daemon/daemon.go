@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -15,21 +16,37 @@ import (
 	"github.com/console/xdebug-cli/internal/ipc"
 )
 
+// Session lifecycle states reported by the daemon
+const (
+	// SessionWaiting means the daemon is listening and no Xdebug connection has arrived yet
+	SessionWaiting = "waiting"
+	// SessionConnected means at least one Xdebug connection is active
+	SessionConnected = "connected"
+	// SessionEnded means all connections ended and the daemon is listening for the next one
+	SessionEnded = "ended"
+)
+
 // Daemon represents a background daemon process that manages debug sessions
 type Daemon struct {
-	server     *dbgp.Server
-	ipcServer  *ipc.Server
-	client     *dbgp.Client
-	executor   *CommandExecutor
-	registry   *SessionRegistry
-	port       int
-	pidFile    string
-	socketPath string
-	statusFile string
-	shutdown   chan os.Signal
-	ctx        context.Context
-	cancel     context.CancelFunc
-	mu         sync.Mutex
+	server         *dbgp.Server
+	ipcServer      *ipc.Server
+	sessions       *SessionPool
+	breakpoints    *BreakpointStore
+	logs           *LogBuffer
+	events         *EventBus
+	registry       *SessionRegistry
+	port           int
+	pidFile        string
+	socketPath     string
+	statusFile     string
+	token          string
+	tcpAddr        string // optional TCP address for IPC requests
+	tcpToken       string // shared token of TCP requests
+	sessionsServed int
+	shutdown       chan os.Signal
+	ctx            context.Context
+	cancel         context.CancelFunc
+	mu             sync.Mutex
 }
 
 // NewDaemon creates a new daemon instance
@@ -41,39 +58,62 @@ func NewDaemon(server *dbgp.Server, port int) (*Daemon, error) {
 	}
 
 	// Generate PID file path
-	pidFile := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.pid", port)
+	pidFile, err := runtimeFile("daemon-%d.pid", port)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate socket path
-	socketPath := fmt.Sprintf("/tmp/xdebug-cli-session-%d.sock", port)
+	socketPath, err := runtimeFile("session-%d.sock", port)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate status file path (for parent-child communication)
-	statusFile := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.status", port)
+	statusFile, err := runtimeFile("daemon-%d.status", port)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate the token IPC clients authenticate with
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Daemon{
-		server:     server,
-		registry:   registry,
-		port:       port,
-		pidFile:    pidFile,
-		socketPath: socketPath,
-		statusFile: statusFile,
-		shutdown:   make(chan os.Signal, 1),
-		ctx:        ctx,
-		cancel:     cancel,
+		server:      server,
+		sessions:    NewSessionPool(),
+		breakpoints: NewBreakpointStore(),
+		logs:        NewLogBuffer(),
+		events:      NewEventBus(),
+		registry:    registry,
+		port:        port,
+		pidFile:     pidFile,
+		socketPath:  socketPath,
+		statusFile:  statusFile,
+		token:       token,
+		shutdown:    make(chan os.Signal, 1),
+		ctx:         ctx,
+		cancel:      cancel,
 	}, nil
 }
 
 // WriteStatus writes the daemon status to the status file
 // Status can be "ready" (breakpoint hit) or "error:message" (failure)
 func (d *Daemon) WriteStatus(status string) error {
-	return os.WriteFile(d.statusFile, []byte(status), 0644)
+	return os.WriteFile(d.statusFile, []byte(status), 0600)
 }
 
 // ReadStatus reads the daemon status from the status file
 // Returns status string, exists bool, error
 func ReadStatus(port int) (string, bool, error) {
-	statusFile := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.status", port)
+	statusFile, err := runtimeFile("daemon-%d.status", port)
+	if err != nil {
+		return "", false, err
+	}
 	data, err := os.ReadFile(statusFile)
 	if os.IsNotExist(err) {
 		return "", false, nil
@@ -86,8 +126,9 @@ func ReadStatus(port int) (string, bool, error) {
 
 // CleanupStatusFile removes the status file
 func CleanupStatusFile(port int) {
-	statusFile := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.status", port)
-	os.Remove(statusFile)
+	if statusFile, err := runtimeFile("daemon-%d.status", port); err == nil {
+		os.Remove(statusFile)
+	}
 }
 
 // CheckExisting checks if a daemon is already running on this port
@@ -143,6 +184,8 @@ func (d *Daemon) Initialize() error {
 		PID:        os.Getpid(),
 		Port:       d.port,
 		SocketPath: d.socketPath,
+		Token:      d.token,
+		IPCAddr:    d.tcpAddr,
 		StartedAt:  time.Now(),
 	}
 	if err := d.registry.Add(sessionInfo); err != nil {
@@ -155,6 +198,8 @@ func (d *Daemon) Initialize() error {
 
 	// Create IPC server
 	ipcServer := ipc.NewServer(d.socketPath, d.handleIPCRequest)
+	ipcServer.SetToken(d.token)
+	ipcServer.HandleStream("events", d.streamEvents)
 	d.ipcServer = ipcServer
 
 	// Start IPC server
@@ -162,6 +207,12 @@ func (d *Daemon) Initialize() error {
 		d.Shutdown()
 		return fmt.Errorf("failed to start IPC server: %w", err)
 	}
+	if d.tcpAddr != "" {
+		if err := ipcServer.ListenTCP(d.tcpAddr, d.tcpToken); err != nil {
+			d.Shutdown()
+			return fmt.Errorf("failed to start IPC TCP listener: %w", err)
+		}
+	}
 
 	// Start IPC server in background
 	go func() {
@@ -175,13 +226,161 @@ func (d *Daemon) Initialize() error {
 	return nil
 }
 
+// ListenTCP makes the daemon also accept IPC requests on a TCP address,
+// authenticated with a shared token, so clients without access to the session
+// registry can connect. The Unix socket keeps its random token and never
+// accepts the shared one. Call before Initialize.
+func (d *Daemon) ListenTCP(addr, token string) {
+	d.tcpAddr = addr
+	d.tcpToken = token
+}
+
+// AddSession registers a new Xdebug connection with the daemon.
+// This should be called after the connection's init packet was read.
+func (d *Daemon) AddSession(client *dbgp.Client) *DebugSession {
+	executor := NewCommandExecutor(client)
+	executor.SetBreakpointStore(d.breakpoints)
+	executor.SetLogBuffer(d.logs)
+	executor.SetSessionPool(d.sessions)
+
+	session := d.sessions.Add(client, executor)
+	executor.SetEventBus(d.events, session.ID)
+
+	d.events.Publish(Event{Type: EventConnection, Session: session.ID, IDEKey: session.IDEKey, FileURI: session.FileURI})
+	client.GetSession().SetObserver(func(change dbgp.SessionEvent) {
+		if event, ok := sessionEvent(session, change); ok {
+			d.events.Publish(event)
+		}
+	})
+
+	d.mu.Lock()
+	d.sessionsServed++
+	d.mu.Unlock()
+
+	return session
+}
+
 // SetClient sets the active DBGp client for this daemon
 // This should be called after an Xdebug connection is established
 func (d *Daemon) SetClient(client *dbgp.Client) {
+	d.AddSession(client)
+}
+
+// Sessions returns the pool of active Xdebug connections
+func (d *Daemon) Sessions() *SessionPool {
+	return d.sessions
+}
+
+// RestoreBreakpoints re-applies breakpoints set during earlier sessions to a new session
+func (d *Daemon) RestoreBreakpoints(session *DebugSession) []error {
+	return session.Executor.RestoreBreakpoints()
+}
+
+// HasBreakpoints reports whether any breakpoints are remembered across sessions
+func (d *Daemon) HasBreakpoints() bool {
+	return d.breakpoints.Len() > 0
+}
+
+// ExecuteCommands runs commands against the default session: the selected
+// one, or the one that most recently stopped at a breakpoint
+func (d *Daemon) ExecuteCommands(commands []string, jsonOutput bool) []ipc.CommandResult {
+	return d.ExecuteSessionCommands(d.sessions.Current(), commands, jsonOutput)
+}
+
+// ExecuteSessionCommands runs commands against the given session. A 'session <id>'
+// command switches the remaining commands of the batch to that session. Sessions
+// whose script finished or whose connection dropped are ended.
+func (d *Daemon) ExecuteSessionCommands(session *DebugSession, commands []string, jsonOutput bool) []ipc.CommandResult {
+	var results []ipc.CommandResult
+
+	for _, batch := range splitAtSessionSwitch(expandCommands(commands)) {
+		if session == nil {
+			return append(results, ipc.CommandResult{Success: false, Error: "no active debug session"})
+		}
+
+		batchResults := session.Executor.ExecuteCommands(batch, jsonOutput)
+		results = append(results, batchResults...)
+
+		if sessionFinished(session.Client, batchResults) {
+			d.EndSession(session)
+		}
+
+		// Stop on failure or session-ending commands, like the executor does
+		if len(batchResults) < len(batch) || !batchResults[len(batchResults)-1].Success {
+			break
+		}
+
+		session = d.sessions.Current()
+	}
+
+	return results
+}
+
+// splitAtSessionSwitch splits a command list after each 'session <id>' command
+// so the following commands can be sent to the newly selected session
+func splitAtSessionSwitch(commands []string) [][]string {
+	var batches [][]string
+	start := 0
+	for i, cmd := range commands {
+		parts := strings.Fields(cmd)
+		if len(parts) > 1 && parts[0] == "session" {
+			batches = append(batches, commands[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(commands) {
+		batches = append(batches, commands[start:])
+	}
+	return batches
+}
+
+// EndSession closes the session's connection and removes it from the pool
+// so the daemon can accept further connections
+func (d *Daemon) EndSession(session *DebugSession) {
+	if !d.sessions.Remove(session.ID) {
+		return
+	}
+
+	// Let Xdebug finish the request before dropping the connection
+	state := session.State()
+	if state == dbgp.StateStopping {
+		_, _ = session.Client.Finish()
+	}
+	_ = session.Client.Close()
+
+	// A dropped connection stops the script without Xdebug saying so
+	if state != dbgp.StateStopping && state != dbgp.StateStopped {
+		d.events.Publish(Event{Type: EventStopped, Session: session.ID})
+	}
+
+	close(session.done)
+}
+
+// WaitSession blocks until the session ends or the daemon shuts down.
+// Returns false if the daemon is shutting down.
+func (d *Daemon) WaitSession(session *DebugSession) bool {
+	select {
+	case <-session.Done():
+		return d.ctx.Err() == nil
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+// SessionState returns the lifecycle state of the daemon's debug sessions
+func (d *Daemon) SessionState() string {
 	d.mu.Lock()
-	d.client = client
-	d.executor = NewCommandExecutor(client)
+	served := d.sessionsServed
 	d.mu.Unlock()
+
+	switch {
+	case d.sessions.Len() > 0:
+		return SessionConnected
+	case served > 0:
+		return SessionEnded
+	default:
+		return SessionWaiting
+	}
 }
 
 // Start starts the daemon process in the current process (no fork)
@@ -246,8 +445,11 @@ func (d *Daemon) Fork(args []string) error {
 	defer devNull.Close()
 
 	// Open log file for stderr (to capture daemon errors)
-	logFile := fmt.Sprintf("/tmp/xdebug-cli-daemon-%d.log", d.port)
-	stderrLog, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	logFile, err := LogFilePath(d.port)
+	if err != nil {
+		return err
+	}
+	stderrLog, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
 		// Fall back to /dev/null if log file can't be created
 		stderrLog = devNull
@@ -299,7 +501,7 @@ func (d *Daemon) handleShutdownSignal() {
 func (d *Daemon) handleIPCRequest(req *ipc.CommandRequest) *ipc.CommandResponse {
 	// Validate request type first
 	switch req.Type {
-	case "execute_commands", "kill":
+	case "execute_commands", "kill", "daemon_status":
 		// Valid request types
 	default:
 		return ipc.NewErrorResponse(fmt.Sprintf("unknown request type: %s", req.Type))
@@ -320,21 +522,90 @@ func (d *Daemon) handleIPCRequest(req *ipc.CommandRequest) *ipc.CommandResponse
 		})
 	}
 
-	// For execute_commands, check if client is available
-	d.mu.Lock()
-	executor := d.executor
-	client := d.client
-	d.mu.Unlock()
+	if req.Type == "daemon_status" {
+		return ipc.NewSuccessResponse([]ipc.CommandResult{d.statusResult()})
+	}
 
-	if client == nil || executor == nil {
+	// For execute_commands, resolve the target session
+	session := d.sessions.Current()
+	if req.Session != "" {
+		var err error
+		session, err = d.sessions.Lookup(req.Session)
+		if err != nil {
+			return ipc.NewErrorResponse(err.Error())
+		}
+	}
+
+	if session == nil {
 		return ipc.NewErrorResponse("no active debug session")
 	}
 
-	// Execute commands
-	results := executor.ExecuteCommands(req.Commands, req.JSONOutput)
+	results := d.ExecuteSessionCommands(session, req.Commands, req.JSONOutput)
 	return ipc.NewSuccessResponse(results)
 }
 
+// statusResult describes the daemon's session lifecycle for 'daemon status'
+func (d *Daemon) statusResult() ipc.CommandResult {
+	d.mu.Lock()
+	served := d.sessionsServed
+	d.mu.Unlock()
+
+	result := map[string]interface{}{
+		"pid":             os.Getpid(),
+		"port":            d.port,
+		"session_state":   d.SessionState(),
+		"sessions_served": served,
+		"active_sessions": d.sessions.Len(),
+		"breakpoints":     d.breakpoints.Len(),
+	}
+	if d.tcpAddr != "" {
+		result["ipc_addr"] = d.tcpAddr
+	}
+
+	if current := d.sessions.Current(); current != nil {
+		file, line := current.Client.GetSession().GetCurrentLocation()
+		result["session_id"] = current.ID
+		result["execution_state"] = current.State().String()
+		result["ide_key"] = current.IDEKey
+		result["filename"] = file
+		result["line"] = line
+	}
+
+	return ipc.CommandResult{
+		Command: "daemon_status",
+		Success: true,
+		Result:  result,
+	}
+}
+
+// sessionFinished reports whether the Xdebug connection is finished after a
+// batch of commands: the script ended, the session was detached, or the
+// connection dropped
+func sessionFinished(client *dbgp.Client, results []ipc.CommandResult) bool {
+	switch client.GetSession().GetState() {
+	case dbgp.StateStopping, dbgp.StateStopped:
+		return true
+	}
+
+	for _, result := range results {
+		if result.Command == "detach" && result.Success {
+			return true
+		}
+		if !result.Success && isConnectionError(result.Error) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConnectionError checks whether an error message indicates a dropped DBGp connection
+func isConnectionError(msg string) bool {
+	return strings.Contains(msg, "EOF") ||
+		strings.Contains(msg, "broken pipe") ||
+		strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "use of closed network connection")
+}
+
 // Shutdown performs graceful shutdown of the daemon with timeout
 func (d *Daemon) Shutdown() error {
 	d.mu.Lock()
@@ -350,6 +621,10 @@ func (d *Daemon) Shutdown() error {
 	var errors []error
 	done := make(chan bool, 1)
 
+	// Tell subscribers before their connections are closed
+	d.events.Publish(Event{Type: EventShutdown})
+	d.events.Close()
+
 	// Perform shutdown in goroutine with timeout
 	go func() {
 		// Close IPC server (this also removes Unix socket)
@@ -359,9 +634,9 @@ func (d *Daemon) Shutdown() error {
 			}
 		}
 
-		// Close DBGp client (sends stop command)
-		if d.client != nil {
-			if err := d.client.Close(); err != nil {
+		// Close DBGp connections of all sessions
+		for _, session := range d.sessions.List() {
+			if err := session.Client.Close(); err != nil {
 				errors = append(errors, fmt.Errorf("client close error: %w", err))
 			}
 		}
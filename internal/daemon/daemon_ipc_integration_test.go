@@ -75,6 +75,7 @@ func TestDaemon_IPC_Integration_ServerCreation(t *testing.T) {
 	// 4. Test command execution via IPC
 	t.Run("ExecuteCommands", func(t *testing.T) {
 		ipcClient := ipc.NewClient(daemon.socketPath)
+		ipcClient.SetToken(daemon.token)
 
 		// Send test commands
 		commands := []string{"help"}
@@ -105,6 +106,7 @@ func TestDaemon_IPC_Integration_ServerCreation(t *testing.T) {
 		for i := 0; i < 2; i++ {
 			go func(id int) {
 				ipcClient := ipc.NewClient(daemon.socketPath)
+				ipcClient.SetToken(daemon.token)
 				commands := []string{"help"}
 				response, err := ipcClient.SendCommands(commands, false)
 				if err != nil {
@@ -167,6 +169,7 @@ func TestDaemon_IPC_Integration_NoSession(t *testing.T) {
 
 	// Try to execute commands without an active session
 	client := ipc.NewClient(daemon.socketPath)
+	client.SetToken(daemon.token)
 	commands := []string{"run"}
 	response, err := client.SendCommands(commands, false)
 	if err != nil {
@@ -216,6 +219,7 @@ func TestDaemon_IPC_Integration_KillRequest(t *testing.T) {
 
 	// Send kill request via IPC
 	ipcClient := ipc.NewClient(daemon.socketPath)
+	ipcClient.SetToken(daemon.token)
 	response, err := ipcClient.Kill()
 	if err != nil {
 		t.Fatalf("Failed to send kill request: %v", err)
@@ -284,3 +288,60 @@ func createMockConnection() *dbgp.Connection {
 
 	return conn
 }
+
+// TestDaemon_IPC_Integration_TCP verifies that a daemon with a TCP listener
+// serves clients that only know its address and the shared token
+func TestDaemon_IPC_Integration_TCP(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("HOME", tempDir)
+	defer os.Unsetenv("HOME")
+	t.Setenv("XDG_RUNTIME_DIR", tempDir)
+
+	// Find a free port for the TCP listener
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	addr := probe.Addr().String()
+	probe.Close()
+
+	daemon, err := NewDaemon(nil, 9022)
+	if err != nil {
+		t.Fatalf("NewDaemon() error = %v", err)
+	}
+	daemon.ListenTCP(addr, "shared-secret")
+	if err := daemon.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	defer daemon.Shutdown()
+
+	client := ipc.NewTCPClient(addr)
+	client.SetToken("shared-secret")
+	resp, err := client.DaemonStatus()
+	if err != nil || !resp.Success {
+		t.Fatalf("DaemonStatus() over TCP = %+v, %v", resp, err)
+	}
+	result := resp.Results[0].Result.(map[string]interface{})
+	if result["ipc_addr"] != addr || result["port"] != float64(9022) {
+		t.Errorf("status = %v, want ipc_addr %s and port 9022", result, addr)
+	}
+
+	// The registry entry names the TCP address but keeps the random socket
+	// token, which the shared token does not replace
+	info, err := daemon.registry.Get(9022)
+	if err != nil {
+		t.Fatalf("registry.Get() error = %v", err)
+	}
+	if info.IPCAddr != addr || info.Token != daemon.token || info.Token == "shared-secret" {
+		t.Errorf("registry entry = %+v", info)
+	}
+	if resp, err := info.IPCClient().DaemonStatus(); err != nil || !resp.Success {
+		t.Errorf("DaemonStatus() over the socket = %+v, %v", resp, err)
+	}
+
+	socketClient := info.IPCClient()
+	socketClient.SetToken("shared-secret")
+	if resp, err := socketClient.DaemonStatus(); err != nil || resp.Success {
+		t.Errorf("DaemonStatus() over the socket with the shared token = %+v, %v, want unauthorized", resp, err)
+	}
+}
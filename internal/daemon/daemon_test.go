@@ -3,6 +3,7 @@ package daemon
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -18,6 +19,7 @@ func TestNewDaemon(t *testing.T) {
 	tempDir := t.TempDir()
 	os.Setenv("HOME", tempDir)
 	defer os.Unsetenv("HOME")
+	t.Setenv("XDG_RUNTIME_DIR", tempDir)
 
 	server := dbgp.NewServer("127.0.0.1", 9003)
 	daemon, err := NewDaemon(server, 9003)
@@ -30,12 +32,12 @@ func TestNewDaemon(t *testing.T) {
 		t.Errorf("port = %d, want 9003", daemon.port)
 	}
 
-	expectedPIDFile := "/tmp/xdebug-cli-daemon-9003.pid"
+	expectedPIDFile := filepath.Join(tempDir, "xdebug-cli", "daemon-9003.pid")
 	if daemon.pidFile != expectedPIDFile {
 		t.Errorf("pidFile = %s, want %s", daemon.pidFile, expectedPIDFile)
 	}
 
-	expectedSocketPath := "/tmp/xdebug-cli-session-9003.sock"
+	expectedSocketPath := filepath.Join(tempDir, "xdebug-cli", "session-9003.sock")
 	if daemon.socketPath != expectedSocketPath {
 		t.Errorf("socketPath = %s, want %s", daemon.socketPath, expectedSocketPath)
 	}
@@ -262,10 +264,88 @@ func TestDaemon_HandleIPCRequest_UnknownType(t *testing.T) {
 	}
 }
 
+func TestDaemon_SessionLifecycle(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("HOME", tempDir)
+	defer os.Unsetenv("HOME")
+
+	server := dbgp.NewServer("127.0.0.1", 9003)
+	daemon, err := NewDaemon(server, 9003)
+	if err != nil {
+		t.Fatalf("NewDaemon() error = %v", err)
+	}
+
+	if state := daemon.SessionState(); state != SessionWaiting {
+		t.Errorf("SessionState() = %s, want %s", state, SessionWaiting)
+	}
+
+	mockConn := newMockConn()
+	session := daemon.AddSession(dbgp.NewClient(dbgp.NewConnection(mockConn)))
+	if state := daemon.SessionState(); state != SessionConnected {
+		t.Errorf("SessionState() after AddSession = %s, want %s", state, SessionConnected)
+	}
+
+	// A session whose script finished ends after the next command batch
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="run" transaction_id="1" status="stopping" reason="ok"/>`))
+
+	daemon.ExecuteCommands([]string{"run"}, false)
+
+	if state := daemon.SessionState(); state != SessionEnded {
+		t.Errorf("SessionState() after script end = %s, want %s", state, SessionEnded)
+	}
+
+	done := make(chan bool, 1)
+	go func() { done <- daemon.WaitSession(session) }()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("WaitSession() = false, want true for an ended session")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitSession() did not return after the session ended")
+	}
+
+	// Commands after the session ended report no session
+	resp := daemon.handleIPCRequest(ipc.NewExecuteCommandsRequest([]string{"run"}, false))
+	if resp.Error != "no active debug session" {
+		t.Errorf("handleIPCRequest() error = %q, want 'no active debug session'", resp.Error)
+	}
+}
+
+func TestDaemon_HandleIPCRequest_DaemonStatus(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("HOME", tempDir)
+	defer os.Unsetenv("HOME")
+
+	server := dbgp.NewServer("127.0.0.1", 9003)
+	daemon, err := NewDaemon(server, 9003)
+	if err != nil {
+		t.Fatalf("NewDaemon() error = %v", err)
+	}
+
+	resp := daemon.handleIPCRequest(ipc.NewDaemonStatusRequest())
+	if !resp.Success || len(resp.Results) != 1 {
+		t.Fatalf("handleIPCRequest(daemon_status) = %+v", resp)
+	}
+
+	result, ok := resp.Results[0].Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Result type = %T, want map", resp.Results[0].Result)
+	}
+	if result["session_state"] != SessionWaiting {
+		t.Errorf("session_state = %v, want %s", result["session_state"], SessionWaiting)
+	}
+	if result["sessions_served"] != 0 {
+		t.Errorf("sessions_served = %v, want 0", result["sessions_served"])
+	}
+}
+
 func TestDaemon_Getters(t *testing.T) {
 	tempDir := t.TempDir()
 	os.Setenv("HOME", tempDir)
 	defer os.Unsetenv("HOME")
+	t.Setenv("XDG_RUNTIME_DIR", tempDir)
 
 	server := dbgp.NewServer("127.0.0.1", 9003)
 	daemon, err := NewDaemon(server, 9003)
@@ -277,12 +357,12 @@ func TestDaemon_Getters(t *testing.T) {
 		t.Errorf("GetPort() = %d, want 9003", daemon.GetPort())
 	}
 
-	expectedPIDFile := "/tmp/xdebug-cli-daemon-9003.pid"
+	expectedPIDFile := filepath.Join(tempDir, "xdebug-cli", "daemon-9003.pid")
 	if daemon.GetPIDFile() != expectedPIDFile {
 		t.Errorf("GetPIDFile() = %s, want %s", daemon.GetPIDFile(), expectedPIDFile)
 	}
 
-	expectedSocketPath := "/tmp/xdebug-cli-session-9003.sock"
+	expectedSocketPath := filepath.Join(tempDir, "xdebug-cli", "session-9003.sock")
 	if daemon.GetSocketPath() != expectedSocketPath {
 		t.Errorf("GetSocketPath() = %s, want %s", daemon.GetSocketPath(), expectedSocketPath)
 	}
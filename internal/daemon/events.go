@@ -0,0 +1,178 @@
+package daemon
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+)
+
+// Event types pushed to 'events' subscribers
+const (
+	EventConnection        = "connection"
+	EventBreak             = "break"
+	EventRunning           = "running"
+	EventStopped           = "stopped"
+	EventBreakpointAdded   = "breakpoint_added"
+	EventBreakpointRemoved = "breakpoint_removed"
+	EventOutput            = "output"
+	EventShutdown          = "shutdown"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind before
+// further events are dropped for it
+const eventBufferSize = 256
+
+// Event is a change in the daemon reported to 'events' subscribers, one JSON
+// line each. Only the fields of the event's type are set.
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Session int       `json:"session,omitempty"`
+
+	// IDEKey and FileURI describe a new connection
+	IDEKey  string `json:"idekey,omitempty"`
+	FileURI string `json:"fileuri,omitempty"`
+
+	// Filename, Line, Reason and Exception describe a break
+	Filename  string `json:"filename,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Exception string `json:"exception,omitempty"`
+
+	// BreakpointID and Location describe an added or removed breakpoint
+	BreakpointID string `json:"breakpoint_id,omitempty"`
+	Location     string `json:"location,omitempty"`
+
+	// Stream and Data carry program output
+	Stream string `json:"stream,omitempty"`
+	Data   string `json:"data,omitempty"`
+}
+
+// EventBus fans events out to subscribers. Publishing never blocks: a
+// subscriber that does not keep up misses events.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+// NewEventBus creates an event bus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving all events published from now on.
+// The channel is closed by Unsubscribe or when the bus is closed.
+func (b *EventBus) Subscribe() chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, eventBufferSize)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe stops delivering events to a subscriber
+func (b *EventBus) Unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Publish sends an event to all subscribers
+func (b *EventBus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Close ends all subscriptions once their buffered events are read
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		close(ch)
+	}
+	b.subscribers = make(map[chan Event]struct{})
+	b.closed = true
+}
+
+// sessionEvent turns a state change or output of a session into an event.
+// It returns false for changes subscribers are not told about.
+func sessionEvent(session *DebugSession, change dbgp.SessionEvent) (Event, bool) {
+	event := Event{Session: session.ID}
+	switch {
+	case change.Stream != "":
+		event.Type = EventOutput
+		event.Stream = change.Stream
+		event.Data = change.Data
+	case change.State == dbgp.StateBreak:
+		event.Type = EventBreak
+		event.Filename = strings.TrimPrefix(session.Client.PathMapper().ToLocal(change.Filename), "file://")
+		event.Line = change.Line
+		event.Reason = change.Reason
+		if exception := session.Client.GetSession().GetException(); exception != nil {
+			event.Exception = exception.Class
+		}
+	case change.State == dbgp.StateRunning:
+		event.Type = EventRunning
+	case change.State == dbgp.StateStopping || change.State == dbgp.StateStopped:
+		// The script stops once, however many states it passes on the way
+		if change.Previous == dbgp.StateStopping || change.Previous == dbgp.StateStopped {
+			return Event{}, false
+		}
+		event.Type = EventStopped
+	default:
+		return Event{}, false
+	}
+	return event, true
+}
+
+// streamEvents serves an 'events' request, pushing events to the client
+// until it disconnects or the daemon shuts down
+func (d *Daemon) streamEvents(req *ipc.CommandRequest, send func(v interface{}) error, done <-chan struct{}) {
+	events := d.events.Subscribe()
+	defer d.events.Unsubscribe(events)
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-done:
+			// Deliver what was published before the connection closed for reading,
+			// such as the shutdown event
+			for {
+				select {
+				case event, ok := <-events:
+					if !ok || send(event) != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"os"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus()
+	first := bus.Subscribe()
+	second := bus.Subscribe()
+
+	bus.Publish(Event{Type: EventRunning})
+	for _, ch := range []chan Event{first, second} {
+		if event := <-ch; event.Type != EventRunning || event.Time.IsZero() {
+			t.Errorf("received %+v, want a timed running event", event)
+		}
+	}
+
+	// A subscriber that falls behind misses events instead of blocking others
+	bus.Unsubscribe(second)
+	for i := 0; i < eventBufferSize+10; i++ {
+		bus.Publish(Event{Type: EventOutput})
+	}
+	if len(first) != eventBufferSize {
+		t.Errorf("buffered %d events, want %d", len(first), eventBufferSize)
+	}
+
+	bus.Close()
+	count := 0
+	for range first {
+		count++
+	}
+	if count != eventBufferSize {
+		t.Errorf("read %d events after close, want %d", count, eventBufferSize)
+	}
+	if _, ok := <-bus.Subscribe(); ok {
+		t.Error("subscribing to a closed bus should return a closed channel")
+	}
+}
+
+func TestDaemon_SessionEvents(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("HOME", tempDir)
+	defer os.Unsetenv("HOME")
+
+	daemon, err := NewDaemon(dbgp.NewServer("127.0.0.1", 9003), 9003)
+	if err != nil {
+		t.Fatalf("NewDaemon() error = %v", err)
+	}
+	events := daemon.events.Subscribe()
+
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	mapper, err := dbgp.ParsePathMappings([]string{"/var/www=/home/dev/app"})
+	if err != nil {
+		t.Fatalf("ParsePathMappings() error = %v", err)
+	}
+	client.SetPathMapper(mapper)
+	session := daemon.AddSession(client)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="step_into" transaction_id="1" status="break" reason="ok">` +
+		`<xdebug:message filename="file:///var/www/index.php" lineno="3"/></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="run" transaction_id="2" status="stopping" reason="ok"/>`))
+	daemon.ExecuteCommands([]string{"step", "run"}, false)
+
+	session.Executor.rememberBreakpoint("5", BreakpointSpec{Type: "line", File: "/app/a.php", Line: 7})
+	session.Executor.forgetBreakpoint("5")
+
+	want := []Event{
+		{Type: EventConnection, Session: 1},
+		{Type: EventRunning, Session: 1},
+		{Type: EventBreak, Session: 1, Filename: "/home/dev/app/index.php", Line: 3, Reason: "ok"},
+		{Type: EventRunning, Session: 1},
+		{Type: EventStopped, Session: 1},
+		{Type: EventBreakpointAdded, Session: 1, BreakpointID: "5", Location: "/app/a.php:7"},
+		{Type: EventBreakpointRemoved, Session: 1, BreakpointID: "5", Location: "/app/a.php:7"},
+	}
+	for i, w := range want {
+		var got Event
+		select {
+		case got = <-events:
+		default:
+			t.Fatalf("event %d missing, want %+v", i, w)
+		}
+		got.Time = w.Time
+		if got != w {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// The session ended, and the stopped event was already sent
+	if len(events) != 0 {
+		t.Errorf("unexpected extra events: %d", len(events))
+	}
+
+	if err := daemon.Shutdown(); err != nil {
+		t.Logf("Shutdown() error = %v", err)
+	}
+	if event := <-events; event.Type != EventShutdown {
+		t.Errorf("last event = %+v, want shutdown", event)
+	}
+	if _, ok := <-events; ok {
+		t.Error("the subscription should end with the shutdown")
+	}
+}
@@ -0,0 +1,177 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+)
+
+// defaultExceptionExpression is the pseudo-variable Xdebug exposes for the
+// exception of an exception breakpoint
+const defaultExceptionExpression = "$__EXCEPTION"
+
+// maxPreviousExceptions caps how far the getPrevious() chain is followed
+const maxPreviousExceptions = 10
+
+// exceptionDetailsPHP evaluates to a JSON description of an exception with
+// its getPrevious() chain and trace. %s is the expression of the exception
+// and %d the maximum length of the chain.
+const exceptionDetailsPHP = `(function ($e) {
+	$describe = function ($e) {
+		return array('class' => get_class($e), 'message' => $e->getMessage(), 'code' => (string) $e->getCode(), 'filename' => $e->getFile(), 'line' => $e->getLine());
+	};
+	$result = $describe($e);
+	$result['previous'] = array();
+	for ($p = $e->getPrevious(), $n = 0; $p !== null && $n < %d; $p = $p->getPrevious(), $n++) {
+		$result['previous'][] = $describe($p);
+	}
+	$result['trace'] = array();
+	foreach ($e->getTrace() as $frame) {
+		$function = isset($frame['class']) ? $frame['class'] . $frame['type'] . $frame['function'] : $frame['function'];
+		$result['trace'][] = array('function' => $function, 'filename' => isset($frame['file']) ? $frame['file'] : '', 'line' => isset($frame['line']) ? $frame['line'] : 0);
+	}
+	return json_encode($result);
+})(%s)`
+
+// exceptionDescription is one exception of the chain
+type exceptionDescription struct {
+	Class    string `json:"class"`
+	Message  string `json:"message"`
+	Code     string `json:"code"`
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+}
+
+// exceptionTraceFrame is one frame of the PHP-side trace of an exception
+type exceptionTraceFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+}
+
+// exceptionDetails is what exceptionDetailsPHP evaluates to
+type exceptionDetails struct {
+	exceptionDescription
+	Previous []exceptionDescription `json:"previous"`
+	Trace    []exceptionTraceFrame  `json:"trace"`
+}
+
+// handleException reports the exception the script stopped on
+// Syntax: exception [expression]
+func (e *CommandExecutor) handleException(args []string) ipc.CommandResult {
+	exception := e.client.GetSession().GetException()
+	if exception == nil {
+		return ipc.CommandResult{
+			Command: "exception",
+			Success: false,
+			Error:   "Not stopped on an exception (set one with 'break exception')",
+		}
+	}
+
+	expression := defaultExceptionExpression
+	if len(args) > 0 {
+		expression = strings.Join(args, " ")
+	}
+
+	return ipc.CommandResult{
+		Command: "exception",
+		Success: true,
+		Result:  e.describeException(exception, expression),
+	}
+}
+
+// addExceptionResult adds the exception details to the result of a command
+// that stopped the script on an exception
+func (e *CommandExecutor) addExceptionResult(result map[string]interface{}) map[string]interface{} {
+	if exception := e.client.GetSession().GetException(); exception != nil {
+		result["exception"] = e.describeException(exception, defaultExceptionExpression)
+	}
+	return result
+}
+
+// describeException combines what Xdebug reported for the break with the
+// getPrevious() chain and trace evaluated from the exception object. When
+// the object cannot be evaluated, the break data is returned with an error.
+func (e *CommandExecutor) describeException(exception *dbgp.ExceptionInfo, expression string) map[string]interface{} {
+	result := map[string]interface{}{
+		"class":    exception.Class,
+		"message":  exception.Message,
+		"code":     exception.Code,
+		"filename": e.localPath(exception.Filename),
+		"line":     exception.Line,
+		"previous": []map[string]interface{}{},
+		"trace":    []map[string]interface{}{},
+	}
+
+	details, err := e.evalExceptionDetails(expression)
+	if err != nil {
+		result["error"] = fmt.Sprintf("previous exceptions and trace unavailable: %v", err)
+		return result
+	}
+
+	result["message"] = details.Message
+	if details.Code != "" {
+		result["code"] = details.Code
+	}
+
+	previous := make([]map[string]interface{}, 0, len(details.Previous))
+	for _, p := range details.Previous {
+		previous = append(previous, map[string]interface{}{
+			"class":    p.Class,
+			"message":  p.Message,
+			"code":     p.Code,
+			"filename": e.localPath(p.Filename),
+			"line":     p.Line,
+		})
+	}
+	result["previous"] = previous
+
+	trace := make([]map[string]interface{}, 0, len(details.Trace))
+	for _, frame := range details.Trace {
+		trace = append(trace, map[string]interface{}{
+			"function": frame.Function,
+			"filename": e.localPath(frame.Filename),
+			"line":     frame.Line,
+		})
+	}
+	result["trace"] = trace
+
+	return result
+}
+
+// evalExceptionDetails evaluates the chain and trace of the exception object.
+// max_data is lifted for the evaluation so the JSON is not cut off.
+func (e *CommandExecutor) evalExceptionDetails(expression string) (*exceptionDetails, error) {
+	if maxData, err := e.client.FeatureGet("max_data"); err == nil && !maxData.HasError() {
+		if previous := strings.TrimSpace(maxData.Source); previous != "" && previous != "0" {
+			if err := e.client.FeatureSet("max_data", "0"); err == nil {
+				defer e.client.FeatureSet("max_data", previous)
+			}
+		}
+	}
+
+	response, err := e.client.Eval(fmt.Sprintf(exceptionDetailsPHP, maxPreviousExceptions, expression))
+	if err != nil {
+		return nil, err
+	}
+	if response.HasError() {
+		return nil, fmt.Errorf("%s", response.GetErrorMessage())
+	}
+	if len(response.Properties) == 0 || response.Properties[0].Type != "string" {
+		return nil, fmt.Errorf("could not describe %s as an exception", expression)
+	}
+
+	value, err := dbgp.DecodePropertyValue(&response.Properties[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var details exceptionDetails
+	if err := json.Unmarshal([]byte(value), &details); err != nil {
+		return nil, fmt.Errorf("failed to parse exception details: %w", err)
+	}
+	return &details, nil
+}
@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+)
+
+func TestRun_ReportsException(t *testing.T) {
+	mockConn := newMockConn()
+	executor := NewCommandExecutor(dbgp.NewClient(dbgp.NewConnection(mockConn)))
+
+	details := `{"class":"RuntimeException","message":"Out of stock","code":"7","filename":"/app/Order.php","line":42,` +
+		`"previous":[{"class":"PDOException","message":"Deadlock","code":"40001","filename":"/app/Db.php","line":9}],` +
+		`"trace":[{"function":"Order->save","filename":"/app/Controller.php","line":15}]}`
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<response xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="1" status="break" reason="ok"><xdebug:message filename="file:///app/Order.php" lineno="42" exception="RuntimeException" code="7"><![CDATA[Out of stock]]></xdebug:message></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="feature_get" transaction_id="2" feature_name="max_data" supported="1"><![CDATA[1024]]></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="feature_set" transaction_id="3" feature="max_data" success="1"/>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="eval" transaction_id="4"><property type="string" encoding="base64"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(details)) + `]]></property></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="feature_set" transaction_id="5" feature="max_data" success="1"/>`))
+
+	result := executor.executeCommand("run", nil)
+	if !result.Success {
+		t.Fatalf("run failed: %s", result.Error)
+	}
+
+	exception, ok := result.Result.(map[string]interface{})["exception"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the run result to include the exception, got %v", result.Result)
+	}
+	if exception["class"] != "RuntimeException" || exception["message"] != "Out of stock" || exception["code"] != "7" || exception["line"] != 42 {
+		t.Errorf("unexpected exception %v", exception)
+	}
+	if previous := exception["previous"].([]map[string]interface{}); len(previous) != 1 || previous[0]["class"] != "PDOException" {
+		t.Errorf("unexpected previous chain %v", exception["previous"])
+	}
+	if trace := exception["trace"].([]map[string]interface{}); len(trace) != 1 || trace[0]["function"] != "Order->save" {
+		t.Errorf("unexpected trace %v", exception["trace"])
+	}
+
+	// max_data is restored after the evaluation
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "feature_set -i 5 -n max_data -v 1024") {
+		t.Errorf("expected max_data to be restored, got %q", sent)
+	}
+}
+
+func TestException_EvalFailureKeepsBreakData(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+
+	result := executor.executeCommand("exception", nil)
+	if result.Success {
+		t.Fatal("expected exception to fail when not stopped on an exception")
+	}
+
+	client.GetSession().SetException(&dbgp.ExceptionInfo{Class: "LogicException", Message: "bad", Code: "0", Filename: "/app/a.php", Line: 3})
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="feature_get" transaction_id="1" feature_name="max_data" supported="1"><![CDATA[0]]></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="eval" transaction_id="2"><error code="206"><message>error evaluating code</message></error></response>`))
+
+	result = executor.executeCommand("exception", []string{"$e"})
+	if !result.Success {
+		t.Fatalf("exception failed: %s", result.Error)
+	}
+	exception := result.Result.(map[string]interface{})
+	if exception["class"] != "LogicException" || exception["line"] != 3 {
+		t.Errorf("unexpected exception %v", exception)
+	}
+	if _, ok := exception["error"]; !ok {
+		t.Error("expected an error explaining the missing chain and trace")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/console/xdebug-cli/internal/cfg"
 	"github.com/console/xdebug-cli/internal/dbgp"
@@ -30,32 +31,190 @@ func expandCommands(commands []string) []string {
 	return expanded
 }
 
+// pauseTimeout bounds how long 'pause' waits for the script to report its break location
+const pauseTimeout = 5 * time.Second
+
+// DefaultWaitTimeout is how long 'wait' blocks when no --timeout is given
+const DefaultWaitTimeout = 30 * time.Second
+
+// runningCommands are the commands accepted while the script runs after
+// 'run --async'; anything else would block until Xdebug stops again
+var runningCommands = map[string]bool{
+	"wait": true, "pause": true, "status": true, "st": true,
+	"help": true, "h": true, "?": true,
+	"sessions": true, "session": true, "output": true, "notifications": true,
+	"watch": true, "watches": true, "unwatch": true, "logs": true,
+}
+
+// CommandNames lists the commands the executor understands, without aliases
+var CommandNames = []string{
+	"run", "step", "next", "out", "break", "tbreak", "until", "logpoint", "logs",
+	"print", "property_get", "context", "list", "source", "info", "breakpoint_list",
+	"status", "stack", "frame", "up", "down", "exception", "eval", "set",
+	"delete", "clear", "disable", "enable", "pause", "wait", "detach", "finish",
+	"sessions", "session", "output", "notifications", "feature", "record",
+	"watch", "watches", "unwatch", "help",
+}
+
+// RequestTimeout returns how long an IPC client should wait for the daemon to
+// answer a batch of commands: the longest 'wait' or 'record' timeout in the
+// batch, or zero if no command blocks beyond the usual response time
+func RequestTimeout(commands []string) time.Duration {
+	var longest time.Duration
+	for _, command := range expandCommands(commands) {
+		parts := strings.Fields(command)
+		if len(parts) == 0 {
+			continue
+		}
+		var timeout time.Duration
+		switch parts[0] {
+		case "wait":
+			timeout, _ = parseWaitArgs(parts[1:])
+		case "record":
+			if opts, err := parseRecordArgs(parts[1:]); err == nil {
+				timeout = opts.timeout
+			}
+		}
+		if timeout > longest {
+			longest = timeout
+		}
+	}
+	return longest
+}
+
 // CommandExecutor executes debug commands and returns structured results
 type CommandExecutor struct {
-	client     *dbgp.Client
-	mu         sync.Mutex
-	jsonOutput bool
+	client         *dbgp.Client
+	breakpoints    *BreakpointStore
+	breakpointKeys map[string]int // Xdebug breakpoint ID -> store key
+	sessions       *SessionPool
+	logs           *LogBuffer
+	events         *EventBus
+	sessionID      int // ID of the connection in the daemon's events
+	watches        []*watch
+	nextWatchID    int
+	lastListing    listing // window shown by the last list, for list + and list -
+	mu             sync.Mutex
+	jsonOutput     bool
 }
 
 // NewCommandExecutor creates a new command executor
 func NewCommandExecutor(client *dbgp.Client) *CommandExecutor {
-	return &CommandExecutor{
-		client: client,
+	e := &CommandExecutor{
+		client:         client,
+		breakpoints:    NewBreakpointStore(),
+		breakpointKeys: make(map[string]int),
+		logs:           NewLogBuffer(),
+		events:         NewEventBus(),
+	}
+	client.SetBreakFilter(logpointFilter{e})
+	return e
+}
+
+// SetBreakpointStore shares a breakpoint store with the executor so that
+// breakpoints outlive the current Xdebug connection
+func (e *CommandExecutor) SetBreakpointStore(store *BreakpointStore) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.breakpoints = store
+}
+
+// SetLogBuffer shares the daemon's logpoint log with the executor
+func (e *CommandExecutor) SetLogBuffer(logs *LogBuffer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.logs = logs
+}
+
+// SetEventBus shares the daemon's event bus with the executor, which
+// publishes breakpoint changes as events of the given session
+func (e *CommandExecutor) SetEventBus(events *EventBus, sessionID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = events
+	e.sessionID = sessionID
+}
+
+// SetSessionPool gives the executor access to the daemon's sessions for the
+// 'sessions' and 'session' commands
+func (e *CommandExecutor) SetSessionPool(pool *SessionPool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sessions = pool
+}
+
+// RestoreBreakpoints applies the remembered breakpoints to this executor's connection
+func (e *CommandExecutor) RestoreBreakpoints() []error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ids, errs := e.breakpoints.Apply(e.client)
+	for id, key := range ids {
+		e.breakpointKeys[id] = key
+	}
+	return errs
+}
+
+// localPath translates a path reported by Xdebug to the local checkout
+func (e *CommandExecutor) localPath(path string) string {
+	return e.client.PathMapper().ToLocal(path)
+}
+
+// currentLocation returns the current file (as a local path) and line
+func (e *CommandExecutor) currentLocation() (string, int) {
+	file, line := e.client.GetSession().GetCurrentLocation()
+	return e.localPath(file), line
+}
+
+// rememberBreakpoint records a breakpoint set on this connection in the shared store
+func (e *CommandExecutor) rememberBreakpoint(id string, spec BreakpointSpec) {
+	e.breakpointKeys[id] = e.breakpoints.Add(spec)
+	e.events.Publish(Event{Type: EventBreakpointAdded, Session: e.sessionID, BreakpointID: id, Location: spec.Location()})
+}
+
+// forgetBreakpoint removes a breakpoint of this connection from the shared store
+func (e *CommandExecutor) forgetBreakpoint(id string) {
+	if key, ok := e.breakpointKeys[id]; ok {
+		location := ""
+		if spec, ok := e.breakpoints.Get(key); ok {
+			location = spec.Location()
+		}
+		e.breakpoints.Remove(key)
+		delete(e.breakpointKeys, id)
+		e.events.Publish(Event{Type: EventBreakpointRemoved, Session: e.sessionID, BreakpointID: id, Location: location})
+	}
+}
+
+// setBreakpointState mirrors an enable/disable of this connection's breakpoint in the shared store
+func (e *CommandExecutor) setBreakpointState(id, state string) {
+	if key, ok := e.breakpointKeys[id]; ok {
+		e.breakpoints.SetState(key, state)
 	}
 }
 
 // ExecuteCommands executes a batch of commands and returns results
 // This is thread-safe and can be called from multiple IPC requests
 func (e *CommandExecutor) ExecuteCommands(commands []string, jsonOutput bool) []ipc.CommandResult {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
 	// Expand semicolon-separated commands before execution
 	commands = expandCommands(commands)
 
-	e.jsonOutput = jsonOutput
 	results := make([]ipc.CommandResult, 0, len(commands))
 
+	// 'pause' must not wait for the lock: a running 'run' holds it until the script breaks
+	if len(commands) > 0 && commands[0] == "pause" {
+		result := e.handlePause()
+		results = append(results, result)
+		if !result.Success {
+			return results
+		}
+		commands = commands[1:]
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.jsonOutput = jsonOutput
+
 	for _, cmdStr := range commands {
 		// Parse command
 		parts := strings.Fields(cmdStr)
@@ -67,7 +226,16 @@ func (e *CommandExecutor) ExecuteCommands(commands []string, jsonOutput bool) []
 		args := parts[1:]
 
 		// Execute command
-		result := e.executeCommand(command, args)
+		var result ipc.CommandResult
+		if e.client.GetSession().GetState() == dbgp.StateRunning && !runningCommands[command] {
+			result = ipc.CommandResult{
+				Command: command,
+				Success: false,
+				Error:   "Script is running; use 'wait' until it stops or 'pause' to interrupt it",
+			}
+		} else {
+			result = e.executeCommand(command, args)
+		}
 		results = append(results, result)
 
 		// If command failed or ended session, stop executing
@@ -88,7 +256,7 @@ func (e *CommandExecutor) ExecuteCommands(commands []string, jsonOutput bool) []
 func (e *CommandExecutor) executeCommand(command string, args []string) ipc.CommandResult {
 	switch command {
 	case "run", "r", "continue", "cont":
-		return e.handleRun()
+		return e.handleRun(args)
 	case "step", "s", "into", "step_into":
 		return e.handleStep()
 	case "next", "n", "over":
@@ -97,12 +265,16 @@ func (e *CommandExecutor) executeCommand(command string, args []string) ipc.Comm
 		return e.handleStepOut()
 	case "break", "b":
 		return e.handleBreak(args)
+	case "tbreak":
+		return e.handleTBreak(args)
+	case "until", "u":
+		return e.handleUntil(args)
 	case "print", "p":
 		return e.handlePrint(args)
 	case "context", "c":
 		return e.handleContext(args)
 	case "list", "l":
-		return e.handleList()
+		return e.handleList(args)
 	case "info", "i":
 		return e.handleInfo(args)
 	case "finish", "f":
@@ -133,6 +305,40 @@ func (e *CommandExecutor) executeCommand(command string, args []string) ipc.Comm
 		return e.handleEnable(args)
 	case "stack":
 		return e.handleStack()
+	case "frame":
+		return e.handleFrame(args)
+	case "up":
+		return e.handleUp(args)
+	case "down":
+		return e.handleDown(args)
+	case "sessions":
+		return e.handleSessions()
+	case "session":
+		return e.handleSession(args)
+	case "pause":
+		return e.handlePause()
+	case "wait":
+		return e.handleWait(args)
+	case "output":
+		return e.handleOutput(args)
+	case "notifications":
+		return e.handleNotifications(args)
+	case "feature":
+		return e.handleFeature(args)
+	case "record":
+		return e.handleRecord(args)
+	case "exception":
+		return e.handleException(args)
+	case "logpoint":
+		return e.handleLogpoint(args)
+	case "logs":
+		return e.handleLogs(args)
+	case "watch":
+		return e.handleWatch(args)
+	case "watches":
+		return e.handleWatches()
+	case "unwatch":
+		return e.handleUnwatch(args)
 	default:
 		return ipc.CommandResult{
 			Command: command,
@@ -149,8 +355,37 @@ func (e *CommandExecutor) isSessionEndingCommand(command string) bool {
 		command == "quit" || command == "q"
 }
 
-// handleRun continues execution to next breakpoint
-func (e *CommandExecutor) handleRun() ipc.CommandResult {
+// handleRun continues execution to next breakpoint. With --async it returns
+// as soon as the command is sent and 'wait' reports where the script stops.
+func (e *CommandExecutor) handleRun(args []string) ipc.CommandResult {
+	if len(args) > 0 {
+		if args[0] != "--async" || len(args) > 1 {
+			return ipc.CommandResult{
+				Command: "run",
+				Success: false,
+				Error:   "Usage: run [--async]",
+			}
+		}
+
+		if err := e.client.RunAsync(); err != nil {
+			return ipc.CommandResult{
+				Command: "run",
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+
+		return ipc.CommandResult{
+			Command: "run",
+			Success: true,
+			Result: map[string]interface{}{
+				"status":   dbgp.StateRunning.String(),
+				"filename": "",
+				"line":     0,
+			},
+		}
+	}
+
 	response, err := e.client.Run()
 	if err != nil {
 		return ipc.CommandResult{
@@ -168,18 +403,133 @@ func (e *CommandExecutor) handleRun() ipc.CommandResult {
 		}
 	}
 
-	file, line := e.client.GetSession().GetCurrentLocation()
+	file, line := e.currentLocation()
 	return ipc.CommandResult{
 		Command: "run",
 		Success: true,
-		Result: map[string]interface{}{
+		Result: e.addExceptionResult(e.addWatchResults(map[string]interface{}{
 			"status":   response.Status,
 			"filename": file,
 			"line":     line,
+		}, response.Status)),
+	}
+}
+
+// handlePause interrupts a running script with DBGp 'break' and reports where
+// it stopped. It never takes the executor lock: a pending continuation command
+// holds it until the script breaks, and so does a batch that runs 'pause'
+// after other commands. Session state is read through the session's own lock.
+func (e *CommandExecutor) handlePause() ipc.CommandResult {
+	if state := e.client.GetSession().GetState(); state != dbgp.StateRunning {
+		return ipc.CommandResult{
+			Command: "pause",
+			Success: false,
+			Error:   fmt.Sprintf("Script is not running (state: %s)", state),
+		}
+	}
+
+	response, err := e.client.Break()
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "pause",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	if response.HasError() {
+		return ipc.CommandResult{
+			Command: "pause",
+			Success: false,
+			Error:   response.GetErrorMessage(),
+		}
+	}
+
+	// The interrupted continuation command, or the unsolicited packet if that
+	// command already gave up waiting, sets the location and then the state
+	state, _ := e.client.GetSession().WaitForState(pauseTimeout, func(state dbgp.SessionStateType) bool {
+		return state != dbgp.StateRunning
+	})
+	file, line := e.currentLocation()
+
+	return ipc.CommandResult{
+		Command: "pause",
+		Success: true,
+		Result: map[string]interface{}{
+			"status":   state.String(),
+			"filename": file,
+			"line":     line,
 		},
 	}
 }
 
+// parseWaitArgs parses 'wait [--timeout N]' where N is in seconds
+func parseWaitArgs(args []string) (time.Duration, error) {
+	timeout := DefaultWaitTimeout
+	for i := 0; i < len(args); i++ {
+		value := ""
+		switch {
+		case args[i] == "--timeout" && i+1 < len(args):
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--timeout="):
+			value = strings.TrimPrefix(args[i], "--timeout=")
+		default:
+			return 0, fmt.Errorf("Usage: wait [--timeout N]")
+		}
+
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 1 {
+			return 0, fmt.Errorf("invalid timeout %q: must be a positive number of seconds", value)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	return timeout, nil
+}
+
+// handleWait blocks until the script enters the break or stopped state and
+// reports the location and the reason Xdebug gave. It is called with the
+// executor lock held and releases it while waiting, so that other clients can
+// run the commands accepted while the script runs.
+func (e *CommandExecutor) handleWait(args []string) ipc.CommandResult {
+	timeout, err := parseWaitArgs(args)
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "wait",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	session := e.client.GetSession()
+	jsonOutput := e.jsonOutput
+	e.mu.Unlock()
+	state, ok := session.WaitForState(timeout, func(state dbgp.SessionStateType) bool {
+		return state == dbgp.StateBreak || state == dbgp.StateStopping || state == dbgp.StateStopped
+	})
+	e.mu.Lock()
+	e.jsonOutput = jsonOutput
+	if !ok {
+		return ipc.CommandResult{
+			Command: "wait",
+			Success: false,
+			Error:   fmt.Sprintf("Timed out after %s waiting for the script to stop (state: %s)", timeout, state),
+		}
+	}
+
+	file, line := e.currentLocation()
+	return ipc.CommandResult{
+		Command: "wait",
+		Success: true,
+		Result: e.addExceptionResult(e.addWatchResults(map[string]interface{}{
+			"status":   state.String(),
+			"reason":   session.GetReason(),
+			"filename": file,
+			"line":     line,
+		}, state.String())),
+	}
+}
+
 // handleStep steps into next statement
 func (e *CommandExecutor) handleStep() ipc.CommandResult {
 	response, err := e.client.Step()
@@ -199,15 +549,15 @@ func (e *CommandExecutor) handleStep() ipc.CommandResult {
 		}
 	}
 
-	file, line := e.client.GetSession().GetCurrentLocation()
+	file, line := e.currentLocation()
 	return ipc.CommandResult{
 		Command: "step",
 		Success: true,
-		Result: map[string]interface{}{
+		Result: e.addWatchResults(map[string]interface{}{
 			"status":   response.Status,
 			"filename": file,
 			"line":     line,
-		},
+		}, response.Status),
 	}
 }
 
@@ -230,15 +580,15 @@ func (e *CommandExecutor) handleNext() ipc.CommandResult {
 		}
 	}
 
-	file, line := e.client.GetSession().GetCurrentLocation()
+	file, line := e.currentLocation()
 	return ipc.CommandResult{
 		Command: "next",
 		Success: true,
-		Result: map[string]interface{}{
+		Result: e.addWatchResults(map[string]interface{}{
 			"status":   response.Status,
 			"filename": file,
 			"line":     line,
-		},
+		}, response.Status),
 	}
 }
 
@@ -261,15 +611,15 @@ func (e *CommandExecutor) handleStepOut() ipc.CommandResult {
 		}
 	}
 
-	file, line := e.client.GetSession().GetCurrentLocation()
+	file, line := e.currentLocation()
 	return ipc.CommandResult{
 		Command: "out",
 		Success: true,
-		Result: map[string]interface{}{
+		Result: e.addWatchResults(map[string]interface{}{
 			"status":   response.Status,
 			"filename": file,
 			"line":     line,
-		},
+		}, response.Status),
 	}
 }
 
@@ -311,27 +661,102 @@ func parseBreakpointArgs(args []string) ([]string, string, error) {
 	return locations, condition, nil
 }
 
+// parseHitArgs splits a trailing "hits [op] <n>" clause off the breakpoint
+// locations. op is >= (break from the nth hit on, the default), == (only the
+// nth hit) or % (every nth hit).
+func parseHitArgs(locations []string) ([]string, int, string, error) {
+	hitsIndex := -1
+	for i, arg := range locations {
+		if arg == "hits" {
+			hitsIndex = i
+			break
+		}
+	}
+	if hitsIndex < 0 {
+		return locations, 0, "", nil
+	}
+
+	clause := locations[hitsIndex+1:]
+	condition := ">="
+	if len(clause) == 2 {
+		condition = clause[0]
+		clause = clause[1:]
+	}
+	if len(clause) != 1 || (condition != ">=" && condition != "==" && condition != "%") {
+		return nil, 0, "", fmt.Errorf("invalid hit condition: use 'hits >= N', 'hits == N' or 'hits %% N'")
+	}
+
+	value, err := strconv.Atoi(clause[0])
+	if err != nil || value < 1 {
+		return nil, 0, "", fmt.Errorf("invalid hit count: %s", clause[0])
+	}
+
+	return locations[:hitsIndex], value, condition, nil
+}
+
 // handleBreak sets breakpoints
 func (e *CommandExecutor) handleBreak(args []string) ipc.CommandResult {
 	if len(args) == 0 {
 		return ipc.CommandResult{
 			Command: "break",
 			Success: false,
-			Error:   "Usage: break <line> | break :<line> | break <file>:<line> | break call <function> | break exception",
+			Error:   "Usage: break <line> | break :<line> | break <file>:<line> [hits [>=|==|%] N] [if <cond>] | break call|return <function> | break when <expr> | break exception",
 		}
 	}
 
-	// Handle "break call <function>"
-	if args[0] == "call" {
+	// Handle "break call <function>" and "break return <function>"
+	if args[0] == "call" || args[0] == "return" {
+		bpType := args[0]
 		if len(args) < 2 {
 			return ipc.CommandResult{
 				Command: "break",
 				Success: false,
-				Error:   "Usage: break call <function>",
+				Error:   fmt.Sprintf("Usage: break %s <function> | break %s <Class>::<method>", bpType, bpType),
 			}
 		}
 		funcName := args[1]
-		response, err := e.client.SetBreakpointToCall(funcName)
+		setBreakpoint := e.client.SetBreakpointToCall
+		if bpType == "return" {
+			setBreakpoint = e.client.SetBreakpointToReturn
+		}
+		response, err := setBreakpoint(funcName)
+		if err != nil {
+			return ipc.CommandResult{
+				Command: "break",
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+		if response.HasError() {
+			return ipc.CommandResult{
+				Command: "break",
+				Success: false,
+				Error:   response.GetErrorMessage(),
+			}
+		}
+		spec := BreakpointSpec{Type: bpType, Function: funcName}
+		e.rememberBreakpoint(response.ID, spec)
+		return ipc.CommandResult{
+			Command: "break",
+			Success: true,
+			Result: map[string]interface{}{
+				"id":       response.ID,
+				"location": spec.Location(),
+			},
+		}
+	}
+
+	// Handle "break when <expression>": break on any line where it is true
+	if args[0] == "when" {
+		expression := strings.Join(args[1:], " ")
+		if expression == "" {
+			return ipc.CommandResult{
+				Command: "break",
+				Success: false,
+				Error:   "Usage: break when <expression>",
+			}
+		}
+		response, err := e.client.SetConditionalBreakpoint(expression)
 		if err != nil {
 			return ipc.CommandResult{
 				Command: "break",
@@ -346,12 +771,14 @@ func (e *CommandExecutor) handleBreak(args []string) ipc.CommandResult {
 				Error:   response.GetErrorMessage(),
 			}
 		}
+		spec := BreakpointSpec{Type: "conditional", Condition: expression}
+		e.rememberBreakpoint(response.ID, spec)
 		return ipc.CommandResult{
 			Command: "break",
 			Success: true,
 			Result: map[string]interface{}{
 				"id":       response.ID,
-				"location": fmt.Sprintf("call %s", funcName),
+				"location": spec.Location(),
 			},
 		}
 	}
@@ -377,6 +804,7 @@ func (e *CommandExecutor) handleBreak(args []string) ipc.CommandResult {
 				Error:   response.GetErrorMessage(),
 			}
 		}
+		e.rememberBreakpoint(response.ID, BreakpointSpec{Type: "exception", Exception: exceptionName})
 		location := "exception"
 		if exceptionName != "" {
 			location = fmt.Sprintf("exception %s", exceptionName)
@@ -391,125 +819,330 @@ func (e *CommandExecutor) handleBreak(args []string) ipc.CommandResult {
 		}
 	}
 
-	// Parse locations and condition
-	locations, condition, err := parseBreakpointArgs(args)
+	return e.breakAtLine("break", args, false)
+}
+
+// handleTBreak sets a temporary line breakpoint that is deleted on its first hit
+func (e *CommandExecutor) handleTBreak(args []string) ipc.CommandResult {
+	if len(args) == 0 {
+		return ipc.CommandResult{
+			Command: "tbreak",
+			Success: false,
+			Error:   "Usage: tbreak <line> | tbreak :<line> | tbreak <file>:<line> [hits [>=|==|%] N] [if <cond>]",
+		}
+	}
+	return e.breakAtLine("tbreak", args, true)
+}
+
+// handleUntil runs to a line using a temporary breakpoint and reports whether
+// execution stopped there or at an earlier breakpoint
+func (e *CommandExecutor) handleUntil(args []string) ipc.CommandResult {
+	if len(args) != 1 {
+		return ipc.CommandResult{
+			Command: "until",
+			Success: false,
+			Error:   "Usage: until <line> | until :<line> | until <file>:<line>",
+		}
+	}
+
+	file, line, err := e.parseLineLocation(args[0])
 	if err != nil {
 		return ipc.CommandResult{
-			Command: "break",
+			Command: "until",
 			Success: false,
 			Error:   err.Error(),
 		}
 	}
-
-	// For now, handle only single location (multiple in next task)
-	if len(locations) != 1 {
+	bpResponse, line, warning, err := e.setCheckedLineBreakpoint(file, line, dbgp.BreakpointOptions{Temporary: true})
+	if err != nil {
 		return ipc.CommandResult{
-			Command: "break",
+			Command: "until",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	if bpResponse.HasError() {
+		return ipc.CommandResult{
+			Command: "until",
 			Success: false,
-			Error:   "multiple breakpoints not yet supported",
+			Error:   bpResponse.GetErrorMessage(),
 		}
 	}
 
-	location := locations[0]
+	response, err := e.client.Run()
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "until",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	if response.HasError() {
+		return ipc.CommandResult{
+			Command: "until",
+			Success: false,
+			Error:   response.GetErrorMessage(),
+		}
+	}
 
-	// Parse line or file:line format
-	var file string
-	var line int
+	target := fmt.Sprintf("%s:%d", file, line)
+	stopFile, stopLine := e.currentLocation()
+	reached := response.Status == "break" && stopLine == line && sameFile(stopFile, file)
 
-	// Check for :line format (current file)
-	if strings.HasPrefix(location, ":") {
-		lineStr := strings.TrimPrefix(location, ":")
-		parsedLine, err := strconv.Atoi(lineStr)
-		if err != nil {
-			return ipc.CommandResult{
-				Command: "break",
-				Success: false,
-				Error:   fmt.Sprintf("Invalid line number: %s", lineStr),
-			}
-		}
-		line = parsedLine
-		file, _ = e.client.GetSession().GetCurrentLocation()
-		if file == "" {
-			return ipc.CommandResult{
-				Command: "break",
-				Success: false,
-				Error:   "No current file. Use format: break <file>:<line>",
-			}
+	// Stopped elsewhere: the temporary breakpoint was not consumed, so remove
+	// it before it fires on a later run
+	if !reached && response.Status == "break" {
+		removeResponse, err := e.client.RemoveBreakpoint(bpResponse.ID)
+		if err == nil && removeResponse.HasError() {
+			err = fmt.Errorf("%s", removeResponse.GetErrorMessage())
 		}
-	} else if strings.Contains(location, ":") {
-		// file:line format
-		parts := strings.SplitN(location, ":", 2)
-		file = parts[0]
-		parsedLine, err := strconv.Atoi(parts[1])
 		if err != nil {
-			return ipc.CommandResult{
-				Command: "break",
-				Success: false,
-				Error:   fmt.Sprintf("Invalid line number: %s", parts[1]),
+			cleanupWarning := fmt.Sprintf("failed to remove temporary breakpoint %s at %s: %v", bpResponse.ID, target, err)
+			if warning != "" {
+				warning += "; " + cleanupWarning
+			} else {
+				warning = cleanupWarning
 			}
 		}
-		line = parsedLine
-	} else {
-		// Just a line number
-		parsedLine, err := strconv.Atoi(location)
-		if err != nil {
-			return ipc.CommandResult{
-				Command: "break",
-				Success: false,
-				Error:   fmt.Sprintf("Invalid breakpoint format: %s", location),
-			}
+	}
+
+	result := map[string]interface{}{
+		"status":   response.Status,
+		"filename": stopFile,
+		"line":     stopLine,
+		"target":   target,
+		"reached":  reached,
+	}
+	if warning != "" {
+		result["warning"] = warning
+	}
+
+	return ipc.CommandResult{
+		Command: "until",
+		Success: true,
+		Result:  e.addWatchResults(result, response.Status),
+	}
+}
+
+// sameFile reports whether two breakpoint paths name the same file, allowing
+// file:// URIs and a relative path matching the end of an absolute one
+func sameFile(a, b string) bool {
+	a = strings.TrimPrefix(a, "file://")
+	b = strings.TrimPrefix(b, "file://")
+	return a == b || strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
+
+// parseLineLocation parses a breakpoint location: <line> or :<line> in the
+// current file, or <file>:<line>
+func (e *CommandExecutor) parseLineLocation(location string) (string, int, error) {
+	var file, lineStr string
+	switch {
+	case strings.HasPrefix(location, ":"):
+		lineStr = strings.TrimPrefix(location, ":")
+	case strings.Contains(location, ":"):
+		parts := strings.SplitN(location, ":", 2)
+		file, lineStr = parts[0], parts[1]
+	default:
+		lineStr = location
+	}
+
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		if !strings.Contains(location, ":") {
+			return "", 0, fmt.Errorf("Invalid breakpoint format: %s", location)
 		}
-		line = parsedLine
-		file, _ = e.client.GetSession().GetCurrentLocation()
+		return "", 0, fmt.Errorf("Invalid line number: %s", lineStr)
+	}
+
+	if file == "" {
+		file, _ = e.currentLocation()
 		if file == "" {
-			return ipc.CommandResult{
-				Command: "break",
-				Success: false,
-				Error:   "No current file. Use format: break <file>:<line>",
-			}
+			return "", 0, fmt.Errorf("No current file. Use format: break <file>:<line>")
 		}
 	}
 
-	// Set the breakpoint with condition
-	response, err := e.client.SetBreakpoint(file, line, condition)
+	return file, line, nil
+}
+
+// breakAtLine sets line breakpoints from 'break'/'tbreak' arguments:
+// <location>... [hits [op] N] [if <condition>]. Temporary breakpoints are
+// removed by Xdebug after their first hit.
+func (e *CommandExecutor) breakAtLine(command string, args []string, temporary bool) ipc.CommandResult {
+	// Parse locations and condition
+	locations, condition, err := parseBreakpointArgs(args)
 	if err != nil {
 		return ipc.CommandResult{
-			Command: "break",
+			Command: command,
 			Success: false,
 			Error:   err.Error(),
 		}
 	}
 
-	if response.HasError() {
+	locations, hitValue, hitCondition, err := parseHitArgs(locations)
+	if err != nil {
 		return ipc.CommandResult{
-			Command: "break",
+			Command: command,
 			Success: false,
-			Error:   response.GetErrorMessage(),
+			Error:   err.Error(),
 		}
 	}
 
-	result := map[string]interface{}{
-		"id":       response.ID,
-		"location": fmt.Sprintf("%s:%d", file, line),
+	if len(locations) == 0 {
+		return ipc.CommandResult{
+			Command: command,
+			Success: false,
+			Error:   "no breakpoint location given",
+		}
+	}
+
+	opts := dbgp.BreakpointOptions{
+		Condition:    condition,
+		HitValue:     hitValue,
+		HitCondition: hitCondition,
+		Temporary:    temporary,
+	}
+
+	// Each location gets its own breakpoint; a failure does not stop the rest
+	results := make([]view.JSONBreakpointResult, 0, len(locations))
+	failed := 0
+	for _, location := range locations {
+		result := e.setLineBreakpoint(location, opts)
+		if result.Error != "" {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if len(results) == 1 {
+		result := results[0]
+		if result.Error != "" {
+			return ipc.CommandResult{
+				Command: command,
+				Success: false,
+				Error:   result.Error,
+			}
+		}
+
+		resultMap := map[string]interface{}{
+			"id":       result.ID,
+			"location": result.Location,
+			"line":     result.Line,
+		}
+		if result.RequestedLine != 0 {
+			resultMap["requested_line"] = result.RequestedLine
+		}
+		if result.Warning != "" {
+			resultMap["warning"] = result.Warning
+		}
+		if condition != "" {
+			resultMap["condition"] = condition
+		}
+		if temporary {
+			resultMap["temporary"] = true
+		}
+		if result.Hits != "" {
+			resultMap["hits"] = result.Hits
+		}
+
+		return ipc.CommandResult{
+			Command: command,
+			Success: true,
+			Result:  resultMap,
+		}
 	}
-	if condition != "" {
-		result["condition"] = condition
+
+	if failed == len(results) {
+		errs := make([]string, 0, len(results))
+		for _, result := range results {
+			errs = append(errs, fmt.Sprintf("%s: %s", result.Location, result.Error))
+		}
+		return ipc.CommandResult{
+			Command: command,
+			Success: false,
+			Error:   "no breakpoints set: " + strings.Join(errs, "; "),
+		}
 	}
 
 	return ipc.CommandResult{
-		Command: "break",
+		Command: command,
 		Success: true,
-		Result:  result,
+		Result: map[string]interface{}{
+			"breakpoints": results,
+			"failed":      failed,
+		},
+	}
+}
+
+// setLineBreakpoint sets one line breakpoint and reports its ID or the error
+func (e *CommandExecutor) setLineBreakpoint(location string, opts dbgp.BreakpointOptions) view.JSONBreakpointResult {
+	result := view.JSONBreakpointResult{
+		Location:  location,
+		Condition: opts.Condition,
+		Temporary: opts.Temporary,
+	}
+	if opts.HitValue > 0 {
+		result.Hits = fmt.Sprintf("%s %d", opts.HitCondition, opts.HitValue)
+	}
+
+	file, line, err := e.parseLineLocation(location)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Location = fmt.Sprintf("%s:%d", file, line)
+
+	response, target, warning, err := e.setCheckedLineBreakpoint(file, line, opts)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if response.HasError() {
+		result.Error = response.GetErrorMessage()
+		return result
 	}
+	result.ID = response.ID
+	result.Line = target
+	result.Warning = warning
+	if target != line {
+		result.Location = fmt.Sprintf("%s:%d", file, target)
+		result.RequestedLine = line
+	}
+
+	// Temporary breakpoints are deleted by Xdebug on their first hit, so
+	// they are not re-applied to later connections
+	if !opts.Temporary {
+		spec := BreakpointSpec{
+			Type:         "line",
+			File:         file,
+			Line:         target,
+			Condition:    opts.Condition,
+			HitValue:     opts.HitValue,
+			HitCondition: opts.HitCondition,
+		}
+		if target != line {
+			spec.RequestedLine = line
+		}
+		e.rememberBreakpoint(response.ID, spec)
+	}
+
+	return result
 }
 
 // handlePrint prints variable value
 func (e *CommandExecutor) handlePrint(args []string) ipc.CommandResult {
+	args, opts, full, err := parsePrintArgs(args)
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "print",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
 	if len(args) == 0 {
 		return ipc.CommandResult{
 			Command: "print",
 			Success: false,
-			Error:   "Usage: print <variable>",
+			Error:   "Usage: print <variable> [--page N] [--depth N] [--full]",
 		}
 	}
 
@@ -537,7 +1170,7 @@ func (e *CommandExecutor) handlePrint(args []string) ipc.CommandResult {
 	varName := strings.Join(args, " ")
 	varName = strings.TrimPrefix(varName, "$")
 
-	response, err := e.client.GetProperty(varName)
+	response, err := e.client.GetPropertyWithOptions(varName, opts)
 	if err != nil {
 		return ipc.CommandResult{
 			Command: "print",
@@ -554,21 +1187,155 @@ func (e *CommandExecutor) handlePrint(args []string) ipc.CommandResult {
 		}
 	}
 
-	if len(response.Properties) == 0 {
-		return ipc.CommandResult{
-			Command: "print",
-			Success: false,
-			Error:   "Variable not found or has no value",
+	if len(response.Properties) == 0 {
+		return ipc.CommandResult{
+			Command: "print",
+			Success: false,
+			Error:   "Variable not found or has no value",
+		}
+	}
+
+	prop := &response.Properties[0]
+	jsonProp := view.ConvertPropertyToJSON(prop)
+
+	// property_value returns the whole string that property_get cut at max_data
+	if full && jsonProp.NumChildren == 0 {
+		value, err := e.client.GetPropertyValue(varName)
+		if err != nil {
+			return ipc.CommandResult{
+				Command: "print",
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+		jsonProp.Value = value
+		jsonProp.Truncated = false
+	}
+
+	return ipc.CommandResult{
+		Command: "print",
+		Success: true,
+		Result:  jsonProp,
+	}
+}
+
+// parsePrintArgs separates the --page N, --depth N and --full options of
+// print from the variable name
+func parsePrintArgs(args []string) ([]string, dbgp.PropertyOptions, bool, error) {
+	var opts dbgp.PropertyOptions
+	var full bool
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--full":
+			if hasValue {
+				return nil, opts, false, fmt.Errorf("--full takes no value")
+			}
+			full = true
+			continue
+		case "--page", "--depth":
+		default:
+			rest = append(rest, args[i])
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, opts, false, fmt.Errorf("%s requires a number", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		n, err := strconv.Atoi(value)
+		if name == "--page" {
+			if err != nil || n < 0 {
+				return nil, opts, false, fmt.Errorf("invalid page %q: must be 0 or greater", value)
+			}
+			opts.Page = n
+		} else {
+			if err != nil || n < 1 {
+				return nil, opts, false, fmt.Errorf("invalid depth %q: must be a positive number", value)
+			}
+			opts.MaxDepth = n
+		}
+	}
+	return rest, opts, full, nil
+}
+
+// featureLimits are the features that limit how much of a variable Xdebug
+// returns, shown by a bare 'feature' command
+var featureLimits = []string{"max_children", "max_data", "max_depth"}
+
+// handleFeature shows or changes Xdebug features for the rest of the session
+// Syntax: feature [name [value]]
+func (e *CommandExecutor) handleFeature(args []string) ipc.CommandResult {
+	if len(args) > 2 {
+		return ipc.CommandResult{
+			Command: "feature",
+			Success: false,
+			Error:   "Usage: feature [name [value]]",
+		}
+	}
+
+	names := featureLimits
+	if len(args) > 0 {
+		names = []string{args[0]}
+	}
+
+	if len(args) == 2 {
+		name, value := args[0], args[1]
+		for _, limit := range featureLimits {
+			if name != limit {
+				continue
+			}
+			if n, err := strconv.Atoi(value); err != nil || n < 0 {
+				return ipc.CommandResult{
+					Command: "feature",
+					Success: false,
+					Error:   fmt.Sprintf("invalid value %q for %s: must be 0 or greater", value, name),
+				}
+			}
+		}
+		if err := e.client.FeatureSet(name, value); err != nil {
+			return ipc.CommandResult{
+				Command: "feature",
+				Success: false,
+				Error:   err.Error(),
+			}
 		}
 	}
 
-	prop := &response.Properties[0]
-	jsonProp := view.ConvertPropertyToJSON(prop)
+	features := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		response, err := e.client.FeatureGet(name)
+		if err != nil {
+			return ipc.CommandResult{
+				Command: "feature",
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+		if response.HasError() {
+			return ipc.CommandResult{
+				Command: "feature",
+				Success: false,
+				Error:   fmt.Sprintf("feature_get %s failed: %s", name, response.GetErrorMessage()),
+			}
+		}
+		features = append(features, map[string]interface{}{
+			"name":      name,
+			"value":     strings.TrimSpace(response.Source),
+			"supported": response.Supported == "1",
+		})
+	}
 
 	return ipc.CommandResult{
-		Command: "print",
+		Command: "feature",
 		Success: true,
-		Result:  jsonProp,
+		Result: map[string]interface{}{
+			"features": features,
+		},
 	}
 }
 
@@ -664,27 +1431,6 @@ func (e *CommandExecutor) handleContext(args []string) ipc.CommandResult {
 	}
 }
 
-// handleList shows source code around current line
-func (e *CommandExecutor) handleList() ipc.CommandResult {
-	file, line := e.client.GetSession().GetCurrentLocation()
-	if file == "" {
-		return ipc.CommandResult{
-			Command: "list",
-			Success: false,
-			Error:   "No current location available",
-		}
-	}
-
-	return ipc.CommandResult{
-		Command: "list",
-		Success: true,
-		Result: map[string]interface{}{
-			"file": file,
-			"line": line,
-		},
-	}
-}
-
 // handleInfo shows debugging information
 func (e *CommandExecutor) handleInfo(args []string) ipc.CommandResult {
 	if len(args) == 0 {
@@ -723,7 +1469,19 @@ func (e *CommandExecutor) handleInfo(args []string) ipc.CommandResult {
 
 		jsonBps := make([]view.JSONBreakpoint, 0, len(viewBps))
 		for _, bp := range viewBps {
-			jsonBps = append(jsonBps, view.ConvertBreakpointToJSON(bp))
+			jsonBp := view.ConvertBreakpointToJSON(bp)
+			if key, ok := e.breakpointKeys[jsonBp.ID]; ok {
+				if spec, ok := e.breakpoints.Get(key); ok {
+					jsonBp.Log = spec.Message
+					switch {
+					case spec.RequestedLine != 0:
+						jsonBp.RequestedLine = spec.RequestedLine
+					case spec.Type == "line" && jsonBp.Line != 0 && spec.Line != jsonBp.Line:
+						jsonBp.RequestedLine = spec.Line
+					}
+				}
+			}
+			jsonBps = append(jsonBps, jsonBp)
 		}
 
 		return ipc.CommandResult{
@@ -759,9 +1517,12 @@ func (e *CommandExecutor) handleInfo(args []string) ipc.CommandResult {
 			viewStack = append(viewStack, &response.Stack[i])
 		}
 
+		selected := e.client.GetSession().GetFrameDepth()
 		jsonStack := make([]view.JSONStack, 0, len(viewStack))
 		for _, frame := range viewStack {
-			jsonStack = append(jsonStack, view.ConvertStackToJSON(frame))
+			jsonFrame := view.ConvertStackToJSON(frame)
+			jsonFrame.Selected = jsonFrame.Level == selected
+			jsonStack = append(jsonStack, jsonFrame)
 		}
 
 		return ipc.CommandResult{
@@ -816,24 +1577,48 @@ func (e *CommandExecutor) handleHelp(args []string) ipc.CommandResult {
 
 Available commands:
   run, r              Continue execution (aliases: continue, cont)
+  run --async         Continue without waiting for the script to stop
+  wait [--timeout N]  Wait until the script breaks or ends (default 30s)
   step, s             Step into (aliases: into, step_into)
   next, n             Step over (alias: over)
   out, o              Step out (alias: step_out)
   break, b <target>   Set breakpoint
+  tbreak <target>     Set a temporary breakpoint (deleted on first hit)
+  until, u <line>     Run to a line in the current file (or <file>:<line>)
+  logpoint <loc> <m>  Log a message with {$expressions} at a line and continue
+  logs [clear]        Show messages logged by logpoints
   delete, del <id>    Delete breakpoint by ID (alias: breakpoint_remove)
   clear <location>    Delete breakpoint by location (GDB-style)
   print, p <var>      Print variable value
+                      (--page N, --depth N, --full for large values)
   property_get -n $v  Print variable (DBGp-style)
   context, c [type]   Show variables (local/global/constant)
-  list, l             Show source code
+  list, l [where]     Show source with the current line and breakpoints marked
+                      (+, -, <line>, <first>,<last>, <function>, Class::method)
   info, i [topic]     Show info (breakpoints)
   breakpoint_list     List breakpoints (DBGp-style)
   status, st          Show current execution status
   stack               Show call stack
+  exception [expr]    Show the exception stopped on (class, message, chain, trace)
+  frame [N]           Select stack frame N for print/context/set (0 = innermost)
+  up, down [N]        Select the caller / callee of the selected frame
   eval, e <expr>      Evaluate PHP expression
   set $var = value    Set variable value
   detach, d           Detach from debug session
+  pause               Interrupt a running script and show where it stopped
   finish, f           Stop debugging
+  sessions            List connected debug sessions
+  session <id>        Switch commands to another session
+  output [clear]      Show captured program output
+  output on|off       Start/stop capturing stdout (on [stderr], redirect)
+  notifications       Show notifications (breakpoint_resolved, xdebug_notify)
+  feature [name [v]]  Show or set max_children, max_data, max_depth
+  record [options]    Step automatically and record each executed line
+                      (--over, --to <line>, --return, --steps N,
+                       --vars $a,$b, --timeout N, --when <expr>)
+  watch <expr>        Show an expression's value at every stop
+  watches             List watch expressions
+  unwatch <id>        Remove a watch expression
   help, h, ?          Show help
 
 For detailed help on a specific command, use: help <command>
@@ -855,6 +1640,20 @@ For detailed help on a specific command, use: help <command>
 
 // handleStatus returns the current execution status
 func (e *CommandExecutor) handleStatus() ipc.CommandResult {
+	// Xdebug does not answer while the script runs, so report the local state
+	if session := e.client.GetSession(); session.GetState() == dbgp.StateRunning {
+		return ipc.CommandResult{
+			Command: "status",
+			Success: true,
+			Result: map[string]interface{}{
+				"status":   dbgp.StateRunning.String(),
+				"reason":   session.GetReason(),
+				"filename": "",
+				"line":     0,
+			},
+		}
+	}
+
 	response, err := e.client.Status()
 	if err != nil {
 		return ipc.CommandResult{
@@ -872,7 +1671,7 @@ func (e *CommandExecutor) handleStatus() ipc.CommandResult {
 		}
 	}
 
-	file, line := e.client.GetSession().GetCurrentLocation()
+	file, line := e.currentLocation()
 	return ipc.CommandResult{
 		Command: "status",
 		Success: true,
@@ -881,6 +1680,7 @@ func (e *CommandExecutor) handleStatus() ipc.CommandResult {
 			"reason":   response.Reason,
 			"filename": file,
 			"line":     line,
+			"frame":    e.client.GetSession().GetFrameDepth(),
 		},
 	}
 }
@@ -1058,7 +1858,7 @@ func (e *CommandExecutor) handleSource(args []string) ipc.CommandResult {
 	}
 
 	if fileURI == "" {
-		file, _ := e.client.GetSession().GetCurrentLocation()
+		file, _ := e.currentLocation()
 		if file == "" {
 			return ipc.CommandResult{
 				Command: "source",
@@ -1086,9 +1886,18 @@ func (e *CommandExecutor) handleSource(args []string) ipc.CommandResult {
 		}
 	}
 
-	sourceData := ""
-	if response.Source != "" {
-		sourceData = view.TryDecodeBase64(response.Source, "string")
+	sourceData, err := response.DecodedValue()
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "source",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	firstLine := beginLine
+	if firstLine < 1 {
+		firstLine = 1
 	}
 
 	return ipc.CommandResult{
@@ -1099,6 +1908,7 @@ func (e *CommandExecutor) handleSource(args []string) ipc.CommandResult {
 			"start_line": beginLine,
 			"end_line":   endLine,
 			"source":     sourceData,
+			"lines":      e.annotateSource(fileURI, firstLine, sourceData),
 		},
 	}
 }
@@ -1139,6 +1949,8 @@ func (e *CommandExecutor) handleDelete(args []string) ipc.CommandResult {
 		}
 	}
 
+	e.forgetBreakpoint(breakpointID)
+
 	return ipc.CommandResult{
 		Command: "delete",
 		Success: true,
@@ -1176,6 +1988,8 @@ func (e *CommandExecutor) handleDisable(args []string) ipc.CommandResult {
 		}
 	}
 
+	e.setBreakpointState(breakpointID, "disabled")
+
 	return ipc.CommandResult{
 		Command: "disable",
 		Success: true,
@@ -1214,6 +2028,8 @@ func (e *CommandExecutor) handleEnable(args []string) ipc.CommandResult {
 		}
 	}
 
+	e.setBreakpointState(breakpointID, "enabled")
+
 	return ipc.CommandResult{
 		Command: "enable",
 		Success: true,
@@ -1243,15 +2059,20 @@ func (e *CommandExecutor) handleStack() ipc.CommandResult {
 		}
 	}
 
+	selected := e.client.GetSession().GetFrameDepth()
 	stackFrames := make([]map[string]interface{}, 0, len(response.Stack))
 	for _, frame := range response.Stack {
 		lineNo, _ := strconv.Atoi(frame.Lineno)
-		stackFrames = append(stackFrames, map[string]interface{}{
+		stackFrame := map[string]interface{}{
 			"depth":    frame.Level,
 			"function": frame.Where,
-			"file":     frame.Filename,
+			"file":     e.localPath(frame.Filename),
 			"line":     lineNo,
-		})
+		}
+		if frame.GetLevel() == selected {
+			stackFrame["selected"] = true
+		}
+		stackFrames = append(stackFrames, stackFrame)
 	}
 
 	return ipc.CommandResult{
@@ -1261,6 +2082,117 @@ func (e *CommandExecutor) handleStack() ipc.CommandResult {
 	}
 }
 
+// handleFrame selects the stack frame that print, context, property_get and
+// set read from, or shows the selected frame without an argument
+func (e *CommandExecutor) handleFrame(args []string) ipc.CommandResult {
+	depth := e.client.GetSession().GetFrameDepth()
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed < 0 || len(args) > 1 {
+			return ipc.CommandResult{
+				Command: "frame",
+				Success: false,
+				Error:   "Usage: frame [N] (0 = innermost frame)",
+			}
+		}
+		depth = parsed
+	}
+	return e.selectFrame("frame", depth)
+}
+
+// handleUp selects the caller of the selected frame, or N frames up
+func (e *CommandExecutor) handleUp(args []string) ipc.CommandResult {
+	count, err := parseFrameCount(args)
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "up",
+			Success: false,
+			Error:   "Usage: up [N]",
+		}
+	}
+	return e.selectFrame("up", e.client.GetSession().GetFrameDepth()+count)
+}
+
+// handleDown selects the frame called by the selected frame, or N frames down
+func (e *CommandExecutor) handleDown(args []string) ipc.CommandResult {
+	count, err := parseFrameCount(args)
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "down",
+			Success: false,
+			Error:   "Usage: down [N]",
+		}
+	}
+	depth := e.client.GetSession().GetFrameDepth() - count
+	if depth < 0 {
+		return ipc.CommandResult{
+			Command: "down",
+			Success: false,
+			Error:   "Bottom (innermost) frame selected; you cannot go down",
+		}
+	}
+	return e.selectFrame("down", depth)
+}
+
+// parseFrameCount parses the optional frame count of up/down (default 1)
+func parseFrameCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count < 1 || len(args) > 1 {
+		return 0, fmt.Errorf("invalid frame count")
+	}
+	return count, nil
+}
+
+// selectFrame checks depth against the current stack, selects it and
+// reports the frame
+func (e *CommandExecutor) selectFrame(command string, depth int) ipc.CommandResult {
+	response, err := e.client.GetStackTrace()
+	if err != nil {
+		return ipc.CommandResult{
+			Command: command,
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	if response.HasError() {
+		return ipc.CommandResult{
+			Command: command,
+			Success: false,
+			Error:   response.GetErrorMessage(),
+		}
+	}
+
+	if depth >= len(response.Stack) {
+		errMsg := fmt.Sprintf("No frame at depth %d (stack has %d frames)", depth, len(response.Stack))
+		if command == "up" {
+			errMsg = "Top (outermost) frame selected; you cannot go up"
+		}
+		return ipc.CommandResult{
+			Command: command,
+			Success: false,
+			Error:   errMsg,
+		}
+	}
+
+	e.client.GetSession().SetFrameDepth(depth)
+
+	frame := &response.Stack[depth]
+	return ipc.CommandResult{
+		Command: command,
+		Success: true,
+		Result: map[string]interface{}{
+			"depth":    depth,
+			"function": frame.Where,
+			"file":     frame.GetFilename(),
+			"line":     frame.GetLineNumber(),
+		},
+	}
+}
+
 // handlePropertyGet handles DBGp-style property_get command
 // Syntax: property_get -n <varname>
 func (e *CommandExecutor) handlePropertyGet(args []string) ipc.CommandResult {
@@ -1319,7 +2251,7 @@ func (e *CommandExecutor) handleClear(args []string) ipc.CommandResult {
 			}
 		}
 		line = parsedLine
-		file, _ = e.client.GetSession().GetCurrentLocation()
+		file, _ = e.currentLocation()
 		if file == "" {
 			return ipc.CommandResult{
 				Command: "clear",
@@ -1371,13 +2303,15 @@ func (e *CommandExecutor) handleClear(args []string) ipc.CommandResult {
 	var removedIDs []string
 	for _, bp := range response.Breakpoints {
 		bpLine, _ := strconv.Atoi(bp.Lineno)
+		bpFile := e.localPath(bp.Filename)
 		// Match by file (check if bp.Filename ends with our file, or matches exactly)
-		fileMatches := bp.Filename == file ||
-			strings.HasSuffix(bp.Filename, "/"+file) ||
-			strings.HasSuffix(bp.Filename, "file://"+file)
+		fileMatches := bpFile == file ||
+			strings.HasSuffix(bpFile, "/"+file) ||
+			strings.HasSuffix(bpFile, "file://"+file)
 		if fileMatches && bpLine == line {
 			removeResp, err := e.client.RemoveBreakpoint(bp.ID)
 			if err == nil && !removeResp.HasError() {
+				e.forgetBreakpoint(bp.ID)
 				removedCount++
 				removedIDs = append(removedIDs, bp.ID)
 			}
@@ -1402,3 +2336,230 @@ func (e *CommandExecutor) handleClear(args []string) ipc.CommandResult {
 		},
 	}
 }
+
+// handleSessions lists the Xdebug connections held by the daemon
+func (e *CommandExecutor) handleSessions() ipc.CommandResult {
+	if e.sessions == nil {
+		return ipc.CommandResult{
+			Command: "sessions",
+			Success: false,
+			Error:   "Session list not available",
+		}
+	}
+
+	current := e.sessions.Current()
+	sessions := make([]map[string]interface{}, 0)
+	for _, session := range e.sessions.List() {
+		sessions = append(sessions, sessionSummary(session, current))
+	}
+
+	return ipc.CommandResult{
+		Command: "sessions",
+		Success: true,
+		Result: map[string]interface{}{
+			"sessions": sessions,
+		},
+	}
+}
+
+// handleSession switches the default session for subsequent commands
+func (e *CommandExecutor) handleSession(args []string) ipc.CommandResult {
+	if e.sessions == nil {
+		return ipc.CommandResult{
+			Command: "session",
+			Success: false,
+			Error:   "Session switching not available",
+		}
+	}
+
+	if len(args) == 0 {
+		current := e.sessions.Current()
+		if current == nil {
+			return ipc.CommandResult{
+				Command: "session",
+				Success: false,
+				Error:   "No active sessions",
+			}
+		}
+		return ipc.CommandResult{
+			Command: "session",
+			Success: true,
+			Result:  sessionSummary(current, current),
+		}
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "session",
+			Success: false,
+			Error:   fmt.Sprintf("Invalid session ID: %s", args[0]),
+		}
+	}
+
+	if err := e.sessions.Select(id); err != nil {
+		return ipc.CommandResult{
+			Command: "session",
+			Success: false,
+			Error:   fmt.Sprintf("Session %d not found. Use 'sessions' to list active sessions", id),
+		}
+	}
+
+	session, _ := e.sessions.Get(id)
+	return ipc.CommandResult{
+		Command: "session",
+		Success: true,
+		Result:  sessionSummary(session, session),
+	}
+}
+
+// sessionSummary describes a session for the 'sessions' and 'session' commands
+func sessionSummary(session, current *DebugSession) map[string]interface{} {
+	file, line := session.Client.GetSession().GetCurrentLocation()
+	file = session.Client.PathMapper().ToLocal(file)
+	return map[string]interface{}{
+		"id":       session.ID,
+		"ide_key":  session.IDEKey,
+		"fileuri":  session.FileURI,
+		"state":    session.State().String(),
+		"filename": file,
+		"line":     line,
+		"current":  session == current,
+	}
+}
+
+// handleOutput shows or clears captured program output, or changes output capture.
+// Syntax: output [clear] | output on|redirect|off [stdout|stderr]
+func (e *CommandExecutor) handleOutput(args []string) ipc.CommandResult {
+	session := e.client.GetSession()
+
+	if len(args) == 0 {
+		chunks := session.GetOutput()
+		var combined strings.Builder
+		entries := make([]map[string]interface{}, 0, len(chunks))
+		for _, chunk := range chunks {
+			combined.WriteString(chunk.Data)
+			entries = append(entries, map[string]interface{}{
+				"stream": chunk.Stream,
+				"data":   chunk.Data,
+			})
+		}
+		return ipc.CommandResult{
+			Command: "output",
+			Success: true,
+			Result: map[string]interface{}{
+				"output": combined.String(),
+				"chunks": entries,
+			},
+		}
+	}
+
+	if args[0] == "clear" {
+		session.ClearOutput()
+		return ipc.CommandResult{
+			Command: "output",
+			Success: true,
+			Result: map[string]interface{}{
+				"message": "Output buffer cleared",
+			},
+		}
+	}
+
+	modes := map[string]int{"off": 0, "on": 1, "redirect": 2}
+	mode, ok := modes[args[0]]
+	if !ok {
+		return ipc.CommandResult{
+			Command: "output",
+			Success: false,
+			Error:   fmt.Sprintf("Unknown output action: %s (expected clear, on, redirect or off)", args[0]),
+		}
+	}
+
+	stream := "stdout"
+	if len(args) > 1 {
+		stream = args[1]
+	}
+
+	var response *dbgp.ProtocolResponse
+	var err error
+	switch stream {
+	case "stdout":
+		response, err = e.client.Stdout(mode)
+	case "stderr":
+		response, err = e.client.Stderr(mode)
+	default:
+		return ipc.CommandResult{
+			Command: "output",
+			Success: false,
+			Error:   fmt.Sprintf("Unknown stream: %s (expected stdout or stderr)", stream),
+		}
+	}
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "output",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	if response.HasError() {
+		return ipc.CommandResult{
+			Command: "output",
+			Success: false,
+			Error:   fmt.Sprintf("Failed to set %s capture: %s", stream, response.GetErrorMessage()),
+		}
+	}
+
+	return ipc.CommandResult{
+		Command: "output",
+		Success: true,
+		Result: map[string]interface{}{
+			"message": fmt.Sprintf("%s capture %s", stream, args[0]),
+			"stream":  stream,
+			"mode":    mode,
+		},
+	}
+}
+
+// handleNotifications shows or clears notifications received from Xdebug
+// Syntax: notifications [clear]
+func (e *CommandExecutor) handleNotifications(args []string) ipc.CommandResult {
+	session := e.client.GetSession()
+
+	if len(args) > 0 {
+		if args[0] != "clear" {
+			return ipc.CommandResult{
+				Command: "notifications",
+				Success: false,
+				Error:   fmt.Sprintf("Unknown notifications action: %s (expected clear)", args[0]),
+			}
+		}
+		session.ClearNotifications()
+		return ipc.CommandResult{
+			Command: "notifications",
+			Success: true,
+			Result: map[string]interface{}{
+				"message": "Notifications cleared",
+			},
+		}
+	}
+
+	notifications := make([]map[string]interface{}, 0)
+	for _, notification := range session.GetNotifications() {
+		notifications = append(notifications, map[string]interface{}{
+			"name":          notification.Name,
+			"filename":      notification.Filename,
+			"line":          notification.Line,
+			"breakpoint_id": notification.BreakpointID,
+			"value":         notification.Value,
+			"time":          notification.Time.Format(time.RFC3339),
+		})
+	}
+
+	return ipc.CommandResult{
+		Command: "notifications",
+		Success: true,
+		Result: map[string]interface{}{
+			"notifications": notifications,
+		},
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/console/xdebug-cli/internal/view"
 )
 
 // TestHandleContext_NoStackFrames tests that context command returns friendly error
@@ -400,3 +403,524 @@ func TestExpandCommands(t *testing.T) {
 		})
 	}
 }
+
+// TestOutputAndNotifications tests that stream and notify packets received
+// while running are available through the output and notifications commands
+func TestOutputAndNotifications(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="stdout" transaction_id="1" success="1"/>`))
+
+	result := executor.executeCommand("output", []string{"on"})
+	if !result.Success {
+		t.Fatalf("output on failed: %s", result.Error)
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "stdout -i 1 -c 1") {
+		t.Errorf("Expected 'stdout -i 1 -c 1' to be sent, got %q", sent)
+	}
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<stream xmlns="urn:debugger_protocol_v1" type="stdout" encoding="base64"><![CDATA[SGVsbG8s]]></stream>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<notify xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" name="user"><xdebug:location filename="file:///app/index.php" lineno="7"/><property type="string" encoding="base64"><![CDATA[Y2hlY2twb2ludA==]]></property></notify>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<stream xmlns="urn:debugger_protocol_v1" type="stdout" encoding="base64"><![CDATA[IHdvcmxk]]></stream>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="run" transaction_id="2" status="break" reason="ok">
+<xdebug:message filename="file:///app/index.php" lineno="9"/>
+</response>`))
+
+	result = executor.executeCommand("run", nil)
+	if !result.Success {
+		t.Fatalf("run failed: %s", result.Error)
+	}
+
+	result = executor.executeCommand("output", nil)
+	if !result.Success {
+		t.Fatalf("output failed: %s", result.Error)
+	}
+	if got := result.Result.(map[string]interface{})["output"]; got != "Hello, world" {
+		t.Errorf("output = %q, want %q", got, "Hello, world")
+	}
+
+	result = executor.executeCommand("notifications", nil)
+	notifications := result.Result.(map[string]interface{})["notifications"].([]map[string]interface{})
+	if len(notifications) != 1 {
+		t.Fatalf("notifications = %v, want 1 entry", notifications)
+	}
+	if notifications[0]["name"] != "user" || notifications[0]["value"] != "checkpoint" || notifications[0]["line"] != 7 {
+		t.Errorf("notification = %v", notifications[0])
+	}
+
+	executor.executeCommand("output", []string{"clear"})
+	executor.executeCommand("notifications", []string{"clear"})
+	if got := executor.executeCommand("output", nil).Result.(map[string]interface{})["output"]; got != "" {
+		t.Errorf("output after clear = %q, want empty", got)
+	}
+	if got := executor.executeCommand("notifications", nil).Result.(map[string]interface{})["notifications"].([]map[string]interface{}); len(got) != 0 {
+		t.Errorf("notifications after clear = %v, want none", got)
+	}
+
+	if result := executor.executeCommand("output", []string{"on", "stdin"}); result.Success {
+		t.Error("output on stdin succeeded, want error")
+	}
+}
+
+// TestPause tests that pause interrupts a running 'run' and reports the
+// location where the script stopped
+func TestPause(t *testing.T) {
+	xdebugSide, ideSide := net.Pipe()
+	defer xdebugSide.Close()
+
+	client := dbgp.NewClient(dbgp.NewConnection(ideSide))
+	defer client.Close()
+	executor := NewCommandExecutor(client)
+
+	if result := executor.ExecuteCommands([]string{"pause"}, false); result[0].Success {
+		t.Fatal("pause succeeded while the script was not running")
+	}
+
+	// Fake Xdebug: answer break, then report the interrupted run
+	go func() {
+		buf := make([]byte, 256)
+		var received string
+		for !strings.Contains(received, "break -i 2") {
+			n, err := xdebugSide.Read(buf)
+			if err != nil {
+				return
+			}
+			received += string(buf[:n])
+		}
+		xdebugSide.Write([]byte(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="break" transaction_id="2" success="1"/>`)))
+		xdebugSide.Write([]byte(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="1" status="break" reason="ok"><xdebug:message filename="file:///app/loop.php" lineno="17"/></response>`)))
+	}()
+
+	runDone := make(chan []ipcResultSummary, 1)
+	go func() {
+		runDone <- summarize(executor.ExecuteCommands([]string{"run"}, false))
+	}()
+
+	// Wait until run is in flight
+	deadline := time.Now().Add(time.Second)
+	for client.GetSession().GetState() != dbgp.StateRunning && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	results := executor.ExecuteCommands([]string{"pause"}, false)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("pause = %+v", results)
+	}
+	pauseResult := results[0].Result.(map[string]interface{})
+	if pauseResult["status"] != "break" || pauseResult["filename"] != "file:///app/loop.php" || pauseResult["line"] != 17 {
+		t.Errorf("pause result = %v, want break at file:///app/loop.php:17", pauseResult)
+	}
+
+	if run := <-runDone; len(run) != 1 || !run[0].success || run[0].status != "break" {
+		t.Errorf("run result = %+v, want break", run)
+	}
+}
+
+// TestPause_AfterOtherCommands tests that pause later in a batch, which runs
+// with the executor lock held, does not deadlock
+func TestPause_AfterOtherCommands(t *testing.T) {
+	xdebugSide, ideSide := net.Pipe()
+	defer xdebugSide.Close()
+
+	client := dbgp.NewClient(dbgp.NewConnection(ideSide))
+	defer client.Close()
+	executor := NewCommandExecutor(client)
+
+	// Fake Xdebug: answer break, then report the interrupted run
+	go func() {
+		buf := make([]byte, 256)
+		var received string
+		for !strings.Contains(received, "break -i 2") {
+			n, err := xdebugSide.Read(buf)
+			if err != nil {
+				return
+			}
+			received += string(buf[:n])
+		}
+		xdebugSide.Write([]byte(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="break" transaction_id="2" success="1"/>`)))
+		xdebugSide.Write([]byte(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="1" status="break" reason="ok"><xdebug:message filename="file:///app/loop.php" lineno="23"/></response>`)))
+	}()
+
+	done := make(chan []ipc.CommandResult, 1)
+	go func() {
+		done <- executor.ExecuteCommands([]string{"run --async; status; pause"}, false)
+	}()
+
+	var results []ipc.CommandResult
+	select {
+	case results = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("'run --async; status; pause' did not return")
+	}
+
+	if len(results) != 3 || !results[2].Success {
+		t.Fatalf("results = %+v", results)
+	}
+	pauseResult := results[2].Result.(map[string]interface{})
+	if pauseResult["status"] != "break" || pauseResult["filename"] != "file:///app/loop.php" || pauseResult["line"] != 23 {
+		t.Errorf("pause result = %v, want break at file:///app/loop.php:23", pauseResult)
+	}
+}
+
+// ipcResultSummary is the part of a run result TestPause checks
+type ipcResultSummary struct {
+	success bool
+	status  string
+}
+
+// summarize extracts success and status from command results
+func summarize(results []ipc.CommandResult) []ipcResultSummary {
+	summaries := make([]ipcResultSummary, 0, len(results))
+	for _, result := range results {
+		summary := ipcResultSummary{success: result.Success}
+		if m, ok := result.Result.(map[string]interface{}); ok {
+			summary.status, _ = m["status"].(string)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// TestRunAsyncAndWait tests that 'run --async' returns while the script runs
+// and 'wait' reports where it stops
+func TestRunAsyncAndWait(t *testing.T) {
+	xdebugSide, ideSide := net.Pipe()
+	defer xdebugSide.Close()
+
+	client := dbgp.NewClient(dbgp.NewConnection(ideSide))
+	defer client.Close()
+	executor := NewCommandExecutor(client)
+
+	// Fake Xdebug: answer run only once the test allows it
+	release := make(chan struct{})
+	go func() {
+		buf := make([]byte, 256)
+		var received string
+		for !strings.Contains(received, "run -i 1") {
+			n, err := xdebugSide.Read(buf)
+			if err != nil {
+				return
+			}
+			received += string(buf[:n])
+		}
+		<-release
+		xdebugSide.Write([]byte(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="1" status="break" reason="ok"><xdebug:message filename="file:///app/slow.php" lineno="8"/></response>`)))
+	}()
+
+	results := executor.ExecuteCommands([]string{"run --async"}, false)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("run --async = %+v", results)
+	}
+	if status := results[0].Result.(map[string]interface{})["status"]; status != "running" {
+		t.Errorf("run --async status = %v, want running", status)
+	}
+
+	results = executor.ExecuteCommands([]string{"print $x"}, false)
+	if results[0].Success || !strings.Contains(results[0].Error, "Script is running") {
+		t.Errorf("print while running = %+v, want 'Script is running' error", results[0])
+	}
+
+	results = executor.ExecuteCommands([]string{"status"}, false)
+	if !results[0].Success || results[0].Result.(map[string]interface{})["status"] != "running" {
+		t.Errorf("status while running = %+v, want running", results[0])
+	}
+
+	results = executor.ExecuteCommands([]string{"wait --timeout 1"}, false)
+	if results[0].Success || !strings.Contains(results[0].Error, "Timed out") {
+		t.Errorf("wait before break = %+v, want timeout", results[0])
+	}
+
+	close(release)
+	results = executor.ExecuteCommands([]string{"wait"}, false)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("wait = %+v", results)
+	}
+	waitResult := results[0].Result.(map[string]interface{})
+	if waitResult["status"] != "break" || waitResult["reason"] != "ok" ||
+		waitResult["filename"] != "file:///app/slow.php" || waitResult["line"] != 8 {
+		t.Errorf("wait result = %v, want break (ok) at file:///app/slow.php:8", waitResult)
+	}
+}
+
+// TestWait_ConcurrentStatus tests that a blocked 'wait' does not hold up
+// commands of other clients that are accepted while the script runs
+func TestWait_ConcurrentStatus(t *testing.T) {
+	xdebugSide, ideSide := net.Pipe()
+	defer xdebugSide.Close()
+
+	client := dbgp.NewClient(dbgp.NewConnection(ideSide))
+	defer client.Close()
+	executor := NewCommandExecutor(client)
+
+	// Fake Xdebug: read run, answer it only once the test allows it
+	release := make(chan struct{})
+	go func() {
+		buf := make([]byte, 256)
+		var received string
+		for !strings.Contains(received, "run -i 1") {
+			n, err := xdebugSide.Read(buf)
+			if err != nil {
+				return
+			}
+			received += string(buf[:n])
+		}
+		<-release
+		xdebugSide.Write([]byte(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="1" status="break" reason="ok"><xdebug:message filename="file:///app/slow.php" lineno="8"/></response>`)))
+	}()
+
+	if results := executor.ExecuteCommands([]string{"run --async"}, false); !results[0].Success {
+		t.Fatalf("run --async = %+v", results)
+	}
+
+	waitDone := make(chan []ipc.CommandResult, 1)
+	go func() {
+		waitDone <- executor.ExecuteCommands([]string{"wait --timeout 5"}, false)
+	}()
+
+	statusDone := make(chan []ipc.CommandResult, 1)
+	go func() {
+		// Give wait time to block first
+		time.Sleep(50 * time.Millisecond)
+		statusDone <- executor.ExecuteCommands([]string{"status"}, false)
+	}()
+
+	select {
+	case results := <-statusDone:
+		if !results[0].Success || results[0].Result.(map[string]interface{})["status"] != "running" {
+			t.Errorf("status while waiting = %+v, want running", results[0])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("status blocked while another client waits")
+	}
+
+	close(release)
+	select {
+	case results := <-waitDone:
+		if !results[0].Success || results[0].Result.(map[string]interface{})["status"] != "break" {
+			t.Errorf("wait = %+v, want break", results[0])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("wait did not return after the script broke")
+	}
+}
+
+// TestRequestTimeout tests the IPC timeout derived from 'wait' commands
+func TestRequestTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		want     time.Duration
+	}{
+		{"no wait", []string{"run", "print $x"}, 0},
+		{"default timeout", []string{"run --async; wait"}, DefaultWaitTimeout},
+		{"explicit timeout", []string{"wait --timeout 90"}, 90 * time.Second},
+		{"equals form", []string{"wait --timeout=5"}, 5 * time.Second},
+		{"longest wins", []string{"wait --timeout 5", "wait --timeout 60"}, 60 * time.Second},
+		{"invalid timeout ignored", []string{"wait --timeout soon"}, 0},
+		{"record default timeout", []string{"record --return"}, DefaultRecordTimeout},
+		{"record timeout", []string{"record --timeout 120 --when $x > 1"}, 120 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestTimeout(tt.commands); got != tt.want {
+				t.Errorf("RequestTimeout(%v) = %s, want %s", tt.commands, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUntil tests that 'until' reports whether the target line was reached
+// and removes the temporary breakpoint when an earlier one stopped execution
+func TestUntil(t *testing.T) {
+	tests := []struct {
+		name        string
+		stopLine    string
+		removeFails bool
+		wantReached bool
+		wantRemove  bool
+	}{
+		{"target reached", "30", false, true, false},
+		{"earlier breakpoint", "12", false, false, true},
+		{"removal fails", "12", true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn := newMockConn()
+			client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+			executor := NewCommandExecutor(client)
+
+			mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="9"/>`))
+			mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="2" status="break" reason="ok"><xdebug:message filename="file:///app/a.php" lineno="` + tt.stopLine + `"/></response>`))
+			if tt.removeFails {
+				mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_remove" transaction_id="3"><error code="205"><message>no such breakpoint</message></error></response>`))
+			} else {
+				mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_remove" transaction_id="3"/>`))
+			}
+
+			result := executor.executeCommand("until", []string{"/app/a.php:30"})
+			if !result.Success {
+				t.Fatalf("until failed: %s", result.Error)
+			}
+
+			untilResult := result.Result.(map[string]interface{})
+			if untilResult["reached"] != tt.wantReached {
+				t.Errorf("reached = %v, want %v", untilResult["reached"], tt.wantReached)
+			}
+			if untilResult["target"] != "/app/a.php:30" {
+				t.Errorf("target = %v, want /app/a.php:30", untilResult["target"])
+			}
+			warning, _ := untilResult["warning"].(string)
+			if hasWarning := strings.Contains(warning, "failed to remove temporary breakpoint 9"); hasWarning != tt.removeFails {
+				t.Errorf("warning = %q, want cleanup warning %v", warning, tt.removeFails)
+			}
+
+			sent := mockConn.writeBuf.String()
+			if !strings.Contains(sent, "-n 30 -r 1") {
+				t.Errorf("until sent %q, want a temporary breakpoint", sent)
+			}
+			if removed := strings.Contains(sent, "breakpoint_remove -i 3 -d 9"); removed != tt.wantRemove {
+				t.Errorf("breakpoint removed = %v, want %v (sent %q)", removed, tt.wantRemove, sent)
+			}
+		})
+	}
+}
+
+// TestFrameSelection tests that up/down/frame select the depth used by print
+// and stop at the ends of the stack
+func TestFrameSelection(t *testing.T) {
+	stackMessage := func(txID int) string {
+		return dbgpMessage(fmt.Sprintf(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="stack_get" transaction_id="%d">
+<stack where="inner" level="0" type="file" filename="file:///app/a.php" lineno="3"/>
+<stack where="caller" level="1" type="file" filename="file:///app/a.php" lineno="10"/>
+<stack where="{main}" level="2" type="file" filename="file:///app/index.php" lineno="5"/>
+</response>`, txID))
+	}
+
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	client.GetSession().SetState(dbgp.StateBreak)
+	executor := NewCommandExecutor(client)
+
+	// up: stack_get (1)
+	mockConn.readBuf.WriteString(stackMessage(1))
+	result := executor.executeCommand("up", nil)
+	if !result.Success {
+		t.Fatalf("up failed: %s", result.Error)
+	}
+	if frame := result.Result.(map[string]interface{}); frame["depth"] != 1 || frame["function"] != "caller" || frame["line"] != 10 {
+		t.Errorf("up selected %v, want #1 caller at line 10", frame)
+	}
+
+	// print: stack_get (2) and property_get (3) in the selected frame
+	mockConn.readBuf.WriteString(stackMessage(2))
+	mockConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="property_get" transaction_id="3">
+<property name="$x" fullname="$x" type="int">7</property>
+</response>`))
+	if result := executor.executeCommand("print", []string{"$x"}); !result.Success {
+		t.Fatalf("print failed: %s", result.Error)
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "property_get -i 3 -d 1 -n") {
+		t.Errorf("print sent %q, want property_get at depth 1", sent)
+	}
+
+	// up 5: stack_get (4), beyond the outermost frame
+	mockConn.readBuf.WriteString(stackMessage(4))
+	if result := executor.executeCommand("up", []string{"5"}); result.Success || !strings.Contains(result.Error, "outermost") {
+		t.Errorf("up 5 = %+v, want outermost frame error", result)
+	}
+
+	// down: stack_get (5) back to the innermost frame
+	mockConn.readBuf.WriteString(stackMessage(5))
+	if result := executor.executeCommand("down", nil); !result.Success {
+		t.Fatalf("down failed: %s", result.Error)
+	}
+	if result := executor.executeCommand("down", nil); result.Success || !strings.Contains(result.Error, "innermost") {
+		t.Errorf("down at frame 0 = %+v, want innermost frame error", result)
+	}
+
+	if result := executor.executeCommand("frame", []string{"-1"}); result.Success {
+		t.Error("frame -1 succeeded, want usage error")
+	}
+}
+
+func TestParsePrintArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantArgs string
+		wantOpts dbgp.PropertyOptions
+		wantFull bool
+		wantErr  bool
+	}{
+		{"plain", []string{"$rows"}, "$rows", dbgp.PropertyOptions{}, false, false},
+		{"page", []string{"$rows", "--page", "3"}, "$rows", dbgp.PropertyOptions{Page: 3}, false, false},
+		{"depth with equals", []string{"--depth=4", "$obj"}, "$obj", dbgp.PropertyOptions{MaxDepth: 4}, false, false},
+		{"full", []string{"$html", "--full"}, "$html", dbgp.PropertyOptions{}, true, false},
+		{"expression with spaces", []string{"$a['x", "y']", "--page", "0"}, "$a['x y']", dbgp.PropertyOptions{}, false, false},
+		{"missing page", []string{"$rows", "--page"}, "", dbgp.PropertyOptions{}, false, true},
+		{"negative page", []string{"$rows", "--page", "-1"}, "", dbgp.PropertyOptions{}, false, true},
+		{"zero depth", []string{"$obj", "--depth", "0"}, "", dbgp.PropertyOptions{}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, opts, full, err := parsePrintArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePrintArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strings.Join(args, " ") != tt.wantArgs || opts != tt.wantOpts || full != tt.wantFull {
+				t.Errorf("parsePrintArgs(%v) = %v, %+v, %v; want %q, %+v, %v",
+					tt.args, args, opts, full, tt.wantArgs, tt.wantOpts, tt.wantFull)
+			}
+		})
+	}
+}
+
+func TestPrintFull(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="stack_get" transaction_id="1"><stack where="{main}" level="0" type="file" filename="file:///app/a.php" lineno="3"/></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="property_get" transaction_id="2"><property name="$html" fullname="$html" type="string" size="11" encoding="base64"><![CDATA[aGVsbG8=]]></property></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="property_value" transaction_id="3" size="11" encoding="base64"><![CDATA[aGVsbG8gd29ybGQ=]]></response>`))
+
+	result := executor.executeCommand("print", []string{"$html", "--full"})
+	if !result.Success {
+		t.Fatalf("print --full failed: %s", result.Error)
+	}
+	prop, ok := result.Result.(view.JSONProperty)
+	if !ok {
+		t.Fatalf("expected view.JSONProperty, got %T", result.Result)
+	}
+	if prop.Value != "hello world" || prop.Truncated || prop.Size != 11 {
+		t.Errorf("expected the complete value, got %+v", prop)
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "property_value -i 3 -d 0 -m 0 -n html") {
+		t.Errorf("expected property_value to be sent, got %q", sent)
+	}
+}
@@ -0,0 +1,131 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+)
+
+// setCheckedLineBreakpoint sets a line breakpoint on a line Xdebug can break
+// on. It returns the line the breakpoint ended up on and a warning when that
+// differs from the requested line or the line looks like it has no code.
+//
+// While the script is stopped, a line inside a function on the stack is
+// checked against xcmd_get_executable_lines and moved to the nearest
+// executable line before the breakpoint is set. Engines with
+// resolved_breakpoints enabled report the line they resolved the breakpoint
+// to, which is used when it differs.
+func (e *CommandExecutor) setCheckedLineBreakpoint(file string, line int, opts dbgp.BreakpointOptions) (*dbgp.ProtocolResponse, int, string, error) {
+	target, warning := e.checkBreakpointLine(file, line)
+
+	response, err := e.client.SetLineBreakpoint(file, target, opts)
+	if err != nil || response.HasError() {
+		return response, target, warning, err
+	}
+
+	if resolved := e.resolvedBreakpointLine(response); resolved > 0 && resolved != target {
+		warning = fmt.Sprintf("line %d has no executable code, Xdebug moved the breakpoint to line %d", line, resolved)
+		target = resolved
+	}
+
+	return response, target, warning, nil
+}
+
+// checkBreakpointLine looks up the executable lines of the innermost function
+// on the stack that is in the file and covers the line. A line without code
+// is moved to the nearest executable line. In a file's top-level code the
+// gaps may hold function and class bodies, which have their own lines, so
+// the nearest line is only suggested there.
+func (e *CommandExecutor) checkBreakpointLine(file string, line int) (int, string) {
+	if e.client.GetSession().GetState() != dbgp.StateBreak {
+		return line, ""
+	}
+
+	response, err := e.client.GetStackTrace()
+	if err != nil || response.HasError() {
+		return line, ""
+	}
+
+	for _, frame := range response.Stack {
+		if !sameFile(e.localPath(frame.Filename), file) {
+			continue
+		}
+		depth, err := strconv.Atoi(frame.Level)
+		if err != nil {
+			continue
+		}
+		lines, err := e.client.GetExecutableLines(depth)
+		if err != nil || !coversLine(lines, line) {
+			continue
+		}
+
+		nearest := nearestLine(lines, line)
+		switch {
+		case nearest == line:
+			return line, ""
+		case frame.Where == "{main}":
+			return line, fmt.Sprintf("line %d may have no executable code, the nearest executable line is %d", line, nearest)
+		default:
+			return nearest, fmt.Sprintf("line %d has no executable code in %s, breakpoint moved to line %d", line, frame.Where, nearest)
+		}
+	}
+
+	return line, ""
+}
+
+// resolvedBreakpointLine returns the line Xdebug resolved a new breakpoint
+// to, or 0 if it has not been resolved yet
+func (e *CommandExecutor) resolvedBreakpointLine(response *dbgp.ProtocolResponse) int {
+	if response.Resolved != "resolved" || response.ID == "" {
+		return 0
+	}
+
+	bpResponse, err := e.client.GetBreakpoint(response.ID)
+	if err != nil || bpResponse.HasError() || len(bpResponse.Breakpoints) == 0 {
+		return 0
+	}
+	return bpResponse.Breakpoints[0].GetLineNumber()
+}
+
+// coversLine reports whether a line lies between the first and last of the
+// executable lines of a function
+func coversLine(lines []int, line int) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	first, last := lines[0], lines[0]
+	for _, l := range lines {
+		if l < first {
+			first = l
+		}
+		if l > last {
+			last = l
+		}
+	}
+	return line >= first && line <= last
+}
+
+// nearestLine returns the executable line closest to line, preferring the
+// later one on a tie as Xdebug does when it resolves breakpoints
+func nearestLine(lines []int, line int) int {
+	nearest := 0
+	for _, l := range lines {
+		if l == line {
+			return line
+		}
+		if nearest == 0 || distance(l, line) < distance(nearest, line) ||
+			(distance(l, line) == distance(nearest, line) && l > nearest) {
+			nearest = l
+		}
+	}
+	return nearest
+}
+
+// distance returns the number of lines between a and b
+func distance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
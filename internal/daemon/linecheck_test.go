@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+)
+
+func TestNearestLine(t *testing.T) {
+	lines := []int{10, 12, 16, 17}
+	tests := []struct {
+		name string
+		line int
+		want int
+	}{
+		{"executable line", 12, 12},
+		{"tie goes to the later line", 11, 12},
+		{"closer to the earlier line", 13, 12},
+		{"closer to the later line", 15, 16},
+		{"past the last line", 20, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nearestLine(lines, tt.line); got != tt.want {
+				t.Errorf("nearestLine(%d) = %d, want %d", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoversLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []int
+		line  int
+		want  bool
+	}{
+		{"before the first line", []int{10, 12, 16, 17}, 9, false},
+		{"after the last line", []int{10, 12, 16, 17}, 18, false},
+		{"between executable lines", []int{10, 12, 16, 17}, 14, true},
+		{"no executable lines", nil, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coversLine(tt.lines, tt.line); got != tt.want {
+				t.Errorf("coversLine(%v, %d) = %v, want %v", tt.lines, tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreak_MovesToExecutableLine(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+	client.GetSession().SetState(dbgp.StateBreak)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="stack_get" transaction_id="1"><stack where="Order->save" level="0" type="file" filename="file:///app/Order.php" lineno="10"/><stack where="{main}" level="1" type="file" filename="file:///app/index.php" lineno="3"/></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="xcmd_get_executable_lines" transaction_id="2"><xdebug:lines><xdebug:line lineno="10"/><xdebug:line lineno="14"/><xdebug:line lineno="15"/></xdebug:lines></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_set" transaction_id="3" id="7"/>`))
+
+	result := executor.executeCommand("break", []string{"/app/Order.php:12"})
+	if !result.Success {
+		t.Fatalf("break failed: %s", result.Error)
+	}
+
+	resultMap := result.Result.(map[string]interface{})
+	if resultMap["line"] != 14 || resultMap["requested_line"] != 12 || resultMap["location"] != "/app/Order.php:14" {
+		t.Errorf("expected the breakpoint to move to line 14, got %v", resultMap)
+	}
+	if warning, _ := resultMap["warning"].(string); !strings.Contains(warning, "line 12 has no executable code") {
+		t.Errorf("unexpected warning %q", warning)
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "xcmd_get_executable_lines -i 2 -d 0") || !strings.Contains(sent, "-n 14") {
+		t.Errorf("unexpected commands %q", sent)
+	}
+
+	spec := executor.breakpoints.List()[0]
+	if spec.Line != 14 || spec.RequestedLine != 12 {
+		t.Errorf("expected the store to keep the moved line, got %+v", spec)
+	}
+}
+
+func TestBreak_UsesResolvedLine(t *testing.T) {
+	mockConn := newMockConn()
+	executor := NewCommandExecutor(dbgp.NewClient(dbgp.NewConnection(mockConn)))
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_set" transaction_id="1" id="4" resolved="resolved"/>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_get" transaction_id="2"><breakpoint id="4" type="line" state="enabled" filename="file:///app/a.php" lineno="21" resolved="resolved"/></response>`))
+
+	result := executor.executeCommand("break", []string{"/app/a.php:19"})
+	if !result.Success {
+		t.Fatalf("break failed: %s", result.Error)
+	}
+
+	resultMap := result.Result.(map[string]interface{})
+	if resultMap["line"] != 21 || resultMap["requested_line"] != 19 {
+		t.Errorf("expected the resolved line 21, got %v", resultMap)
+	}
+	if warning, _ := resultMap["warning"].(string); !strings.Contains(warning, "Xdebug moved the breakpoint to line 21") {
+		t.Errorf("unexpected warning %q", warning)
+	}
+}
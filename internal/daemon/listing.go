@@ -0,0 +1,265 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+// listContext is how many lines list shows before and after a line
+const listContext = 5
+
+// listing is a window of source lines shown by list
+type listing struct {
+	file        string
+	first, last int
+}
+
+// functionLocationPHP evaluates to the JSON location of a function, or of a
+// method when the class (first %s) is not empty
+const functionLocationPHP = `(function ($class, $name) {
+	try {
+		$r = $class === '' ? new ReflectionFunction($name) : new ReflectionMethod($class, $name);
+	} catch (ReflectionException $e) {
+		return json_encode(array('error' => $e->getMessage()));
+	}
+	return json_encode(array('filename' => $r->getFileName(), 'start' => $r->getStartLine(), 'end' => $r->getEndLine()));
+})('%s', '%s')`
+
+// functionNamePattern matches the function and Class::method names list accepts
+var functionNamePattern = regexp.MustCompile(`^\\?[A-Za-z_][A-Za-z0-9_\\]*(::[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// functionLocation is what functionLocationPHP evaluates to
+type functionLocation struct {
+	Filename string `json:"filename"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Error    string `json:"error"`
+}
+
+// handleList shows a window of source lines, annotated with the current
+// line and breakpoints
+// Syntax: list [+ | - | [file:]<line> | [file:]<first>,<last> | <function> | <Class::method>]
+func (e *CommandExecutor) handleList(args []string) ipc.CommandResult {
+	window, err := e.listWindow(strings.Join(args, ""))
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "list",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	response, err := e.client.GetSource(window.file, window.first, window.last)
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "list",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	if response.HasError() {
+		return ipc.CommandResult{
+			Command: "list",
+			Success: false,
+			Error:   response.GetErrorMessage(),
+		}
+	}
+
+	source, err := response.DecodedValue()
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "list",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	lines := e.annotateSource(window.file, window.first, source)
+	if len(lines) == 0 {
+		return ipc.CommandResult{
+			Command: "list",
+			Success: false,
+			Error:   fmt.Sprintf("Line %d is past the end of %s", window.first, window.file),
+		}
+	}
+	window.last = lines[len(lines)-1].Line
+	e.lastListing = window
+
+	result := map[string]interface{}{
+		"file":       window.file,
+		"start_line": window.first,
+		"end_line":   window.last,
+		"lines":      lines,
+	}
+	if file, line := e.currentLocation(); file != "" && sameFile(file, window.file) {
+		result["line"] = line
+	}
+
+	return ipc.CommandResult{
+		Command: "list",
+		Success: true,
+		Result:  result,
+	}
+}
+
+// listWindow works out the file and lines a list argument asks for
+func (e *CommandExecutor) listWindow(arg string) (listing, error) {
+	currentFile, currentLine := e.currentLocation()
+	last := e.lastListing
+
+	switch {
+	case arg == "" || ((arg == "+" || arg == "-") && last.file == ""):
+		if currentFile == "" {
+			return listing{}, fmt.Errorf("No current location available")
+		}
+		return around(currentFile, currentLine), nil
+
+	case arg == "+":
+		return listing{file: last.file, first: last.last + 1, last: last.last + 2*listContext + 1}, nil
+
+	case arg == "-":
+		if last.first <= 1 {
+			return listing{}, fmt.Errorf("Already at the start of %s", last.file)
+		}
+		first := last.first - 2*listContext - 1
+		if first < 1 {
+			first = 1
+		}
+		return listing{file: last.file, first: first, last: last.first - 1}, nil
+
+	case functionNamePattern.MatchString(arg):
+		return e.functionWindow(arg)
+	}
+
+	// [file:]<line> or [file:]<first>,<last>, in the current file by default
+	file, lines := currentFile, arg
+	if i := strings.LastIndex(arg, ":"); i >= 0 {
+		file, lines = arg[:i], arg[i+1:]
+	}
+	if file == "" {
+		file = last.file
+	}
+	if file == "" {
+		return listing{}, fmt.Errorf("No current file. Use format: list <file>:<line>")
+	}
+
+	firstStr, lastStr, isRange := strings.Cut(lines, ",")
+	first, err := strconv.Atoi(strings.TrimSpace(firstStr))
+	if err != nil || first < 1 {
+		return listing{}, fmt.Errorf("Usage: list [+|-|<line>|<first>,<last>|<function>|<Class::method>]")
+	}
+	if !isRange {
+		return around(file, first), nil
+	}
+	lastLine, err := strconv.Atoi(strings.TrimSpace(lastStr))
+	if err != nil || lastLine < first {
+		return listing{}, fmt.Errorf("Invalid line range: %s", lines)
+	}
+	return listing{file: file, first: first, last: lastLine}, nil
+}
+
+// around returns the window centered on a line
+func around(file string, line int) listing {
+	first := line - listContext
+	if first < 1 {
+		first = 1
+	}
+	return listing{file: file, first: first, last: line + listContext}
+}
+
+// functionWindow looks up where a function or method is defined by
+// reflection in the script
+func (e *CommandExecutor) functionWindow(name string) (listing, error) {
+	if e.client.GetSession().GetState() != dbgp.StateBreak {
+		return listing{}, fmt.Errorf("Cannot look up %s: the script is not stopped", name)
+	}
+
+	class, function := "", name
+	if i := strings.Index(name, "::"); i >= 0 {
+		class, function = name[:i], name[i+2:]
+	}
+
+	// Backslashes of namespaces are doubled for the single-quoted PHP strings
+	escape := func(s string) string { return strings.ReplaceAll(s, `\`, `\\`) }
+	response, err := e.client.Eval(fmt.Sprintf(functionLocationPHP, escape(class), escape(function)))
+	if err != nil {
+		return listing{}, err
+	}
+	if response.HasError() {
+		return listing{}, fmt.Errorf("%s", response.GetErrorMessage())
+	}
+	if len(response.Properties) == 0 || response.Properties[0].Type != "string" {
+		return listing{}, fmt.Errorf("Could not look up %s", name)
+	}
+
+	value, err := dbgp.DecodePropertyValue(&response.Properties[0])
+	if err != nil {
+		return listing{}, err
+	}
+	var location functionLocation
+	if err := json.Unmarshal([]byte(value), &location); err != nil {
+		return listing{}, fmt.Errorf("failed to parse the location of %s: %w", name, err)
+	}
+	switch {
+	case location.Error != "":
+		return listing{}, fmt.Errorf("%s", location.Error)
+	case location.Filename == "":
+		return listing{}, fmt.Errorf("%s is not defined in a PHP file", name)
+	}
+
+	return listing{file: e.localPath(location.Filename), first: location.Start, last: location.End}, nil
+}
+
+// annotateSource splits source starting at line first into lines, marking
+// the current line and the lines with line breakpoints
+func (e *CommandExecutor) annotateSource(file string, first int, source string) []view.JSONSourceLine {
+	texts := strings.Split(source, "\n")
+	if len(texts) > 0 && texts[len(texts)-1] == "" {
+		texts = texts[:len(texts)-1]
+	}
+
+	currentLine := 0
+	if currentFile, line := e.currentLocation(); currentFile != "" && sameFile(currentFile, file) {
+		currentLine = line
+	}
+	breakpoints := e.lineBreakpointStates(file)
+
+	lines := make([]view.JSONSourceLine, 0, len(texts))
+	for i, text := range texts {
+		number := first + i
+		lines = append(lines, view.JSONSourceLine{
+			Line:       number,
+			Text:       strings.TrimSuffix(text, "\r"),
+			Current:    number == currentLine,
+			Breakpoint: breakpoints[number],
+		})
+	}
+	return lines
+}
+
+// lineBreakpointStates maps the lines of a file that have line breakpoints
+// to "enabled" or "disabled"; an enabled breakpoint wins over a disabled one
+func (e *CommandExecutor) lineBreakpointStates(file string) map[int]string {
+	states := make(map[int]string)
+	response, err := e.client.GetBreakpointList()
+	if err != nil || response.HasError() {
+		return states
+	}
+	for _, bp := range response.Breakpoints {
+		if bp.Type != "line" || !sameFile(e.localPath(bp.Filename), file) {
+			continue
+		}
+		line := bp.GetLineNumber()
+		if states[line] != "enabled" {
+			states[line] = bp.State
+		}
+	}
+	return states
+}
@@ -0,0 +1,194 @@
+package daemon
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+// sourceMessage returns a source response with lines first..last of a
+// made-up file
+func sourceMessage(txID, first, last int) string {
+	var b strings.Builder
+	for line := first; line <= last; line++ {
+		fmt.Fprintf(&b, "// line %d\n", line)
+	}
+	return dbgpMessage(fmt.Sprintf(`<response command="source" transaction_id="%d" encoding="base64"><![CDATA[%s]]></response>`,
+		txID, base64.StdEncoding.EncodeToString([]byte(b.String()))))
+}
+
+func TestList_AnnotatesWindow(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+	client.GetSession().SetCurrentLocation("file:///app/Order.php", 10)
+
+	mockConn.readBuf.WriteString(sourceMessage(1, 5, 15))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_list" transaction_id="2">` +
+		`<breakpoint id="1" type="line" state="enabled" filename="file:///app/Order.php" lineno="7"/>` +
+		`<breakpoint id="2" type="line" state="disabled" filename="file:///app/Order.php" lineno="12"/>` +
+		`<breakpoint id="3" type="line" state="enabled" filename="file:///app/Cart.php" lineno="8"/>` +
+		`</response>`))
+
+	result := executor.executeCommand("list", nil)
+	if !result.Success {
+		t.Fatalf("list failed: %s", result.Error)
+	}
+
+	resultMap := result.Result.(map[string]interface{})
+	if resultMap["start_line"] != 5 || resultMap["end_line"] != 15 || resultMap["line"] != 10 {
+		t.Errorf("unexpected window %v", resultMap)
+	}
+	lines := resultMap["lines"].([]view.JSONSourceLine)
+	if len(lines) != 11 {
+		t.Fatalf("expected 11 lines, got %d", len(lines))
+	}
+	want := map[int]view.JSONSourceLine{
+		7:  {Line: 7, Text: "// line 7", Breakpoint: "enabled"},
+		8:  {Line: 8, Text: "// line 8"},
+		10: {Line: 10, Text: "// line 10", Current: true},
+		12: {Line: 12, Text: "// line 12", Breakpoint: "disabled"},
+	}
+	for _, line := range lines {
+		if w, ok := want[line.Line]; ok && line != w {
+			t.Errorf("line %d = %+v, want %+v", line.Line, line, w)
+		}
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "source -i 1 -f file:///app/Order.php -b 5 -e 15") {
+		t.Errorf("unexpected commands %q", sent)
+	}
+
+	// list + continues after the previous window, up to the end of the file
+	mockConn.readBuf.WriteString(sourceMessage(3, 16, 18))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_list" transaction_id="4"></response>`))
+
+	result = executor.executeCommand("list", []string{"+"})
+	if !result.Success {
+		t.Fatalf("list + failed: %s", result.Error)
+	}
+	resultMap = result.Result.(map[string]interface{})
+	if resultMap["start_line"] != 16 || resultMap["end_line"] != 18 {
+		t.Errorf("unexpected window %v", resultMap)
+	}
+	if !strings.Contains(mockConn.writeBuf.String(), "source -i 3 -f file:///app/Order.php -b 16 -e 26") {
+		t.Errorf("unexpected commands %q", mockConn.writeBuf.String())
+	}
+}
+
+func TestListWindow(t *testing.T) {
+	executor := NewCommandExecutor(dbgp.NewClient(dbgp.NewConnection(newMockConn())))
+	executor.client.GetSession().SetCurrentLocation("/app/a.php", 3)
+
+	tests := []struct {
+		name    string
+		arg     string
+		last    listing
+		want    listing
+		wantErr bool
+	}{
+		{name: "around current line", arg: "", want: listing{file: "/app/a.php", first: 1, last: 8}},
+		{name: "forward without previous listing", arg: "+", want: listing{file: "/app/a.php", first: 1, last: 8}},
+		{name: "backward", arg: "-", last: listing{file: "/app/b.php", first: 30, last: 40}, want: listing{file: "/app/b.php", first: 19, last: 29}},
+		{name: "backward to start of file", arg: "-", last: listing{file: "/app/b.php", first: 4, last: 14}, want: listing{file: "/app/b.php", first: 1, last: 3}},
+		{name: "line range", arg: "120,160", want: listing{file: "/app/a.php", first: 120, last: 160}},
+		{name: "around line", arg: "50", want: listing{file: "/app/a.php", first: 45, last: 55}},
+		{name: "file and range", arg: "/app/c.php:20,25", want: listing{file: "/app/c.php", first: 20, last: 25}},
+		{name: "reversed range", arg: "160,120", wantErr: true},
+		{name: "line zero", arg: "0", wantErr: true},
+		{name: "invalid line", arg: "a.php:x", wantErr: true},
+		{name: "backward at start of file", arg: "-", last: listing{file: "/app/a.php", first: 1, last: 11}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor.lastListing = tt.last
+			got, err := executor.listWindow(tt.arg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("listWindow(%q) = %+v, want an error", tt.arg, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("listWindow(%q) = %+v, %v, want %+v", tt.arg, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestList_Method(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+	client.GetSession().SetState(dbgp.StateBreak)
+	client.GetSession().SetCurrentLocation("file:///app/index.php", 3)
+
+	location := `{"filename":"/app/Cart.php","start":20,"end":23}`
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="eval" transaction_id="1"><property type="string" encoding="base64"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(location)) + `]]></property></response>`))
+	mockConn.readBuf.WriteString(sourceMessage(2, 20, 23))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_list" transaction_id="3"></response>`))
+
+	result := executor.executeCommand("list", []string{`App\Cart::total`})
+	if !result.Success {
+		t.Fatalf("list failed: %s", result.Error)
+	}
+
+	resultMap := result.Result.(map[string]interface{})
+	if resultMap["file"] != "/app/Cart.php" || resultMap["start_line"] != 20 || resultMap["end_line"] != 23 {
+		t.Errorf("unexpected window %v", resultMap)
+	}
+	if _, ok := resultMap["line"]; ok {
+		t.Error("the current line is not in the listed file")
+	}
+
+	// The class reaches PHP with its namespace separator intact
+	encoded := strings.Fields(strings.Split(mockConn.writeBuf.String(), "-- ")[1])[0]
+	code, _ := base64.StdEncoding.DecodeString(encoded)
+	if !strings.Contains(string(code), `('App\\Cart', 'total')`) {
+		t.Errorf("unexpected eval code %s", code)
+	}
+}
+
+// TestSource_Encoding tests that source text is only base64 decoded when the
+// response says it is encoded
+func TestSource_Encoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{
+			name:     "base64",
+			response: `<response command="source" transaction_id="1" encoding="base64"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte("abcd\n")) + `]]></response>`,
+			want:     "abcd\n",
+		},
+		{
+			// "abcd" is valid base64 too, but the response is not encoded
+			name:     "plain text",
+			response: `<response command="source" transaction_id="1"><![CDATA[abcd]]></response>`,
+			want:     "abcd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn := newMockConn()
+			client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+			executor := NewCommandExecutor(client)
+
+			mockConn.readBuf.WriteString(dbgpMessage(tt.response))
+			mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_list" transaction_id="2"></response>`))
+
+			result := executor.executeCommand("source", []string{"/app/a.php"})
+			if !result.Success {
+				t.Fatalf("source failed: %s", result.Error)
+			}
+			if source := result.Result.(map[string]interface{})["source"]; source != tt.want {
+				t.Errorf("source = %q, want %q", source, tt.want)
+			}
+		})
+	}
+}
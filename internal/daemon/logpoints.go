@@ -0,0 +1,269 @@
+package daemon
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+)
+
+// MaxLogEntries caps the logpoint messages kept per daemon
+const MaxLogEntries = 1000
+
+// LogEntry is a message logged when execution passed a logpoint
+type LogEntry struct {
+	Time     time.Time
+	Filename string
+	Line     int
+	Message  string
+}
+
+// LogBuffer collects logpoint messages. It is shared by all executors of a
+// daemon, so messages from every connection end up in one log.
+type LogBuffer struct {
+	mu      sync.RWMutex
+	entries []LogEntry
+}
+
+// NewLogBuffer creates an empty log buffer
+func NewLogBuffer() *LogBuffer {
+	return &LogBuffer{}
+}
+
+// Add appends a message, dropping the oldest once MaxLogEntries is exceeded
+func (b *LogBuffer) Add(entry LogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	b.entries = append(b.entries, entry)
+	if len(b.entries) > MaxLogEntries {
+		b.entries = b.entries[len(b.entries)-MaxLogEntries:]
+	}
+}
+
+// List returns the logged messages in the order they were logged
+func (b *LogBuffer) List() []LogEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	entries := make([]LogEntry, len(b.entries))
+	copy(entries, b.entries)
+	return entries
+}
+
+// Clear empties the log
+func (b *LogBuffer) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = nil
+}
+
+// logpointPlaceholder matches the {$expression} placeholders of a logpoint message
+var logpointPlaceholder = regexp.MustCompile(`\{(\$[^{}]+)\}`)
+
+// handleLogpoint sets a breakpoint that logs a message and continues
+// Syntax: logpoint <location> <message>
+func (e *CommandExecutor) handleLogpoint(args []string) ipc.CommandResult {
+	if len(args) < 2 {
+		return ipc.CommandResult{
+			Command: "logpoint",
+			Success: false,
+			Error:   `Usage: logpoint <file>:<line> "<message with {$expressions}>"`,
+		}
+	}
+
+	message := strings.Join(args[1:], " ")
+	if len(message) >= 2 && (message[0] == '"' || message[0] == '\'') && message[len(message)-1] == message[0] {
+		message = message[1 : len(message)-1]
+	}
+
+	file, line, err := e.parseLineLocation(args[0])
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "logpoint",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	response, target, warning, err := e.setCheckedLineBreakpoint(file, line, dbgp.BreakpointOptions{})
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "logpoint",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	if response.HasError() {
+		return ipc.CommandResult{
+			Command: "logpoint",
+			Success: false,
+			Error:   response.GetErrorMessage(),
+		}
+	}
+
+	spec := BreakpointSpec{
+		Type:    "line",
+		File:    file,
+		Line:    target,
+		Message: message,
+	}
+	result := map[string]interface{}{
+		"id":       response.ID,
+		"location": fmt.Sprintf("%s:%d", file, target),
+		"line":     target,
+		"message":  message,
+	}
+	if target != line {
+		spec.RequestedLine = line
+		result["requested_line"] = line
+	}
+	if warning != "" {
+		result["warning"] = warning
+	}
+	e.rememberBreakpoint(response.ID, spec)
+
+	return ipc.CommandResult{
+		Command: "logpoint",
+		Success: true,
+		Result:  result,
+	}
+}
+
+// handleLogs shows or clears the messages logged by logpoints
+// Syntax: logs [clear]
+func (e *CommandExecutor) handleLogs(args []string) ipc.CommandResult {
+	if len(args) > 0 {
+		if args[0] != "clear" {
+			return ipc.CommandResult{
+				Command: "logs",
+				Success: false,
+				Error:   fmt.Sprintf("Unknown logs action: %s (expected clear)", args[0]),
+			}
+		}
+		e.logs.Clear()
+		return ipc.CommandResult{
+			Command: "logs",
+			Success: true,
+			Result: map[string]interface{}{
+				"message": "Logs cleared",
+			},
+		}
+	}
+
+	logs := make([]map[string]interface{}, 0)
+	for _, entry := range e.logs.List() {
+		logs = append(logs, map[string]interface{}{
+			"time":     entry.Time.Format(time.RFC3339),
+			"filename": entry.Filename,
+			"line":     entry.Line,
+			"message":  entry.Message,
+		})
+	}
+
+	return ipc.CommandResult{
+		Command: "logs",
+		Success: true,
+		Result: map[string]interface{}{
+			"logs": logs,
+		},
+	}
+}
+
+// logpointFilter is the client's break filter for an executor's logpoints
+type logpointFilter struct {
+	e *CommandExecutor
+}
+
+// Filtering reports whether any logpoint is enabled
+func (f logpointFilter) Filtering() bool {
+	for _, spec := range f.e.breakpoints.List() {
+		if spec.Message != "" && spec.State != "disabled" {
+			return true
+		}
+	}
+	return false
+}
+
+// PassBreak logs the message of a logpoint hit, see passLogpoint
+func (f logpointFilter) PassBreak(filename string, line int) bool {
+	return f.e.passLogpoint(filename, line)
+}
+
+// passLogpoint is called when the script stops: at a logpoint it logs the
+// message and returns true so execution continues. A regular breakpoint on
+// the same line still stops.
+func (e *CommandExecutor) passLogpoint(filename string, line int) bool {
+	file := e.localPath(filename)
+
+	var logpoint *BreakpointSpec
+	for _, spec := range e.breakpoints.List() {
+		if spec.Type != "line" || spec.State == "disabled" || spec.Line != line || !sameFile(spec.File, file) {
+			continue
+		}
+		if spec.Message == "" {
+			return false
+		}
+		spec := spec
+		logpoint = &spec
+	}
+	if logpoint == nil {
+		return false
+	}
+
+	e.logs.Add(LogEntry{
+		Filename: file,
+		Line:     line,
+		Message:  e.interpolate(logpoint.Message),
+	})
+	return true
+}
+
+// interpolate replaces each {$expression} in a logpoint message with the
+// value of the expression in the current frame
+func (e *CommandExecutor) interpolate(message string) string {
+	return logpointPlaceholder.ReplaceAllStringFunc(message, func(placeholder string) string {
+		return e.evalToString(placeholder[1 : len(placeholder)-1])
+	})
+}
+
+// evalToString evaluates an expression and formats the result for a log message
+func (e *CommandExecutor) evalToString(expression string) string {
+	response, err := e.client.Eval(expression)
+	if err != nil {
+		return fmt.Sprintf("<error: %v>", err)
+	}
+	if response.HasError() {
+		return fmt.Sprintf("<error: %s>", response.GetErrorMessage())
+	}
+	if len(response.Properties) == 0 {
+		return "<no value>"
+	}
+
+	prop := &response.Properties[0]
+	switch prop.Type {
+	case "null", "uninitialized":
+		return "null"
+	case "array":
+		return fmt.Sprintf("array(%d)", prop.GetNumChildren())
+	case "object":
+		return fmt.Sprintf("object(%s)", prop.ClassType)
+	}
+
+	value, err := dbgp.DecodePropertyValue(prop)
+	if err != nil {
+		return prop.Value
+	}
+	if prop.Type == "bool" {
+		if value == "1" {
+			return "true"
+		}
+		return "false"
+	}
+	return value
+}
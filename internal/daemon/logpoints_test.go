@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+)
+
+func TestLogpoint(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_set" transaction_id="1" id="5"/>`))
+	result := executor.executeCommand("logpoint", []string{"/app/Service.php:88", `"user={$user->id}`, `total={$total}"`})
+	if !result.Success {
+		t.Fatalf("logpoint failed: %s", result.Error)
+	}
+	if message := result.Result.(map[string]interface{})["message"]; message != "user={$user->id} total={$total}" {
+		t.Errorf("expected the unquoted message, got %q", message)
+	}
+
+	// The logpoint is hit, its expressions are evaluated and run is sent again
+	mockConn.readBuf.WriteString(dbgpMessage(`<response xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="2" status="break" reason="ok"><xdebug:message filename="file:///app/Service.php" lineno="88"/></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="eval" transaction_id="3"><property type="int"><![CDATA[7]]></property></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="eval" transaction_id="4"><property type="array" numchildren="2"/></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="run" transaction_id="5" status="stopping" reason="ok"/>`))
+
+	result = executor.executeCommand("run", nil)
+	if !result.Success {
+		t.Fatalf("run failed: %s", result.Error)
+	}
+	if status := result.Result.(map[string]interface{})["status"]; status != "stopping" {
+		t.Errorf("expected run to continue past the logpoint, got status %v", status)
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "run -i 5") {
+		t.Errorf("expected run to be resent, got %q", sent)
+	}
+
+	result = executor.executeCommand("logs", nil)
+	logs := result.Result.(map[string]interface{})["logs"].([]map[string]interface{})
+	if len(logs) != 1 {
+		t.Fatalf("expected one log message, got %d", len(logs))
+	}
+	if logs[0]["message"] != "user=7 total=array(2)" || logs[0]["line"] != 88 {
+		t.Errorf("unexpected log entry %v", logs[0])
+	}
+
+	executor.executeCommand("logs", []string{"clear"})
+	if len(executor.logs.List()) != 0 {
+		t.Error("expected logs to be cleared")
+	}
+}
+
+// TestLogpoint_NextOverCall tests that next over a call that hits a logpoint
+// logs the message and finishes the step instead of stopping in the call
+func TestLogpoint_NextOverCall(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="breakpoint_set" transaction_id="1" id="5"/>`))
+	if result := executor.executeCommand("logpoint", []string{"/app/Service.php:88", "called"}); !result.Success {
+		t.Fatalf("logpoint failed: %s", result.Error)
+	}
+
+	// The step starts at depth 1, stops at the logpoint at depth 2 and is
+	// finished with step_out
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="stack_depth" transaction_id="2" depth="1"/>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="step_over" transaction_id="3" status="break" reason="ok"><xdebug:message filename="file:///app/Service.php" lineno="88"/></response>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="stack_depth" transaction_id="4" depth="2"/>`))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="step_out" transaction_id="5" status="break" reason="ok"><xdebug:message filename="file:///app/index.php" lineno="6"/></response>`))
+
+	result := executor.executeCommand("next", nil)
+	if !result.Success {
+		t.Fatalf("next failed: %s", result.Error)
+	}
+	if res := result.Result.(map[string]interface{}); res["filename"] != "file:///app/index.php" || res["line"] != 6 {
+		t.Errorf("next stopped at %v:%v, want file:///app/index.php:6", res["filename"], res["line"])
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "step_out -i 5") {
+		t.Errorf("expected the step to be finished with step_out, got %q", sent)
+	}
+
+	logs := executor.logs.List()
+	if len(logs) != 1 || logs[0].Message != "called" || logs[0].Line != 88 {
+		t.Errorf("logs = %+v, want the logpoint message", logs)
+	}
+}
+
+func TestPassLogpoint_BreakpointOnSameLineStops(t *testing.T) {
+	executor := NewCommandExecutor(dbgp.NewClient(dbgp.NewConnection(newMockConn())))
+	executor.breakpoints.Add(BreakpointSpec{Type: "line", File: "/app/a.php", Line: 3, Message: "x"})
+	executor.breakpoints.Add(BreakpointSpec{Type: "line", File: "/app/a.php", Line: 3})
+
+	if executor.passLogpoint("file:///app/a.php", 3) {
+		t.Error("expected the regular breakpoint to stop execution")
+	}
+	if executor.passLogpoint("file:///app/a.php", 4) {
+		t.Error("expected no logpoint on another line")
+	}
+	if len(executor.logs.List()) != 0 {
+		t.Error("expected nothing to be logged")
+	}
+}
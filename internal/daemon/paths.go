@@ -123,40 +123,60 @@ func extractFilename(path string) string {
 	return filepath.Base(path)
 }
 
+// BreakpointLocations returns the locations a break or tbreak command sets
+// breakpoints at, e.g. ["a.php:10", ":30"] for "break a.php:10 :30 if $x".
+// Function, conditional and exception breakpoints are returned as
+// "call <function>", "return <function>", "when <expr>" and "exception [name]".
+// Other commands return nil.
+func BreakpointLocations(command string) []string {
+	parts := strings.Fields(command)
+	if len(parts) < 2 || (parts[0] != "break" && parts[0] != "b" && parts[0] != "tbreak") {
+		return nil
+	}
+
+	switch parts[1] {
+	case "call", "return", "when", "exception":
+		return []string{strings.Join(parts[1:], " ")}
+	}
+
+	locations, _, err := parseBreakpointArgs(parts[1:])
+	if err != nil {
+		return nil
+	}
+	locations, _, _, err = parseHitArgs(locations)
+	if err != nil {
+		return nil
+	}
+	return locations
+}
+
 // HasNonAbsoluteBreakpoint scans commands for break commands with non-absolute paths.
 // Returns (hasNonAbsolute, breakpointPath) where breakpointPath is the first
 // non-absolute path found, or empty if all are absolute.
 func HasNonAbsoluteBreakpoint(commands []string) (bool, string) {
 	for _, cmd := range commands {
-		// Check if this is a break command
-		if !strings.HasPrefix(cmd, "break ") && !strings.HasPrefix(cmd, "b ") {
-			continue
-		}
-
-		// Parse the command to extract the breakpoint target
+		// Skip call, return, when and exception breakpoints which don't have file paths
 		parts := strings.Fields(cmd)
-		if len(parts) < 2 {
-			continue
-		}
-
-		// Skip "break call" and "break exception" which don't have file paths
-		if parts[1] == "call" || parts[1] == "exception" {
-			continue
+		if len(parts) >= 2 {
+			switch parts[1] {
+			case "call", "return", "when", "exception":
+				continue
+			}
 		}
 
-		// Get the location (could be :line, file:line, or just line)
-		location := parts[1]
-
-		// Skip :line format (uses current file) - this is OK
-		if strings.HasPrefix(location, ":") {
-			continue
-		}
+		// Each location could be :line, file:line, or just line
+		for _, location := range BreakpointLocations(cmd) {
+			// Skip :line format (uses current file) - this is OK
+			if strings.HasPrefix(location, ":") {
+				continue
+			}
 
-		// Check for file:line format
-		if strings.Contains(location, ":") {
-			filePart := strings.Split(location, ":")[0]
-			if !IsAbsolutePath(filePart) {
-				return true, location
+			// Check for file:line format
+			if strings.Contains(location, ":") {
+				filePart := strings.Split(location, ":")[0]
+				if !IsAbsolutePath(filePart) {
+					return true, location
+				}
 			}
 		}
 	}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -115,6 +116,12 @@ func TestHasNonAbsoluteBreakpoint(t *testing.T) {
 			expectNonAbs: false,
 			expectPath:   "",
 		},
+		{
+			name:         "multiple locations with non-absolute",
+			commands:     []string{"break /var/www/a.php:10 b.php:20 :30 if $x > 1"},
+			expectNonAbs: true,
+			expectPath:   "b.php:20",
+		},
 		{
 			name:         "no break commands",
 			commands:     []string{"run", "context local"},
@@ -142,6 +149,29 @@ func TestHasNonAbsoluteBreakpoint(t *testing.T) {
 	}
 }
 
+func TestBreakpointLocations(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"break /app/a.php:10", []string{"/app/a.php:10"}},
+		{"break a.php:10 b.php:20 :30 if $x > 1", []string{"a.php:10", "b.php:20", ":30"}},
+		{"b :42 hits >= 100", []string{":42"}},
+		{"tbreak :7", []string{":7"}},
+		{"break call myFunction", []string{"call myFunction"}},
+		{"break exception", []string{"exception"}},
+		{"break", nil},
+		{"run", nil},
+	}
+
+	for _, tt := range tests {
+		got := BreakpointLocations(tt.command)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("BreakpointLocations(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
 func TestBreakpointPathStore(t *testing.T) {
 	// Create temp directory for test
 	tempDir := t.TempDir()
@@ -0,0 +1,272 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+const (
+	// DefaultRecordSteps caps how many statements 'record' executes when no
+	// --steps limit is given
+	DefaultRecordSteps = 1000
+
+	// DefaultRecordTimeout caps how long 'record' runs when no --timeout is given
+	DefaultRecordTimeout = 60 * time.Second
+)
+
+// recordOptions holds the stop conditions and settings of a 'record' command
+type recordOptions struct {
+	over     bool          // step over calls instead of into them
+	to       string        // stop at this line location (":N" or "file:N")
+	toReturn bool          // stop when the starting function returns
+	when     string        // stop once this expression is true
+	steps    int           // stop after this many statements
+	vars     []string      // variables recorded with every statement
+	timeout  time.Duration // stop after this long
+}
+
+// parseRecordArgs parses 'record' arguments:
+// [--over] [--to <line>] [--return] [--steps N] [--vars $a,$b] [--timeout N] [--when <expr>]
+// --when takes the rest of the arguments as the expression.
+func parseRecordArgs(args []string) (recordOptions, error) {
+	opts := recordOptions{steps: DefaultRecordSteps, timeout: DefaultRecordTimeout}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--over", "--return":
+			if hasValue {
+				return opts, fmt.Errorf("%s takes no value", name)
+			}
+			if name == "--over" {
+				opts.over = true
+			} else {
+				opts.toReturn = true
+			}
+			continue
+		case "--when":
+			expression := strings.Join(args[i+1:], " ")
+			if hasValue {
+				expression = strings.TrimSpace(value + " " + expression)
+			}
+			if expression == "" {
+				return opts, fmt.Errorf("--when requires an expression")
+			}
+			opts.when = expression
+			return opts, nil
+		case "--to", "--steps", "--vars", "--timeout":
+		default:
+			return opts, fmt.Errorf("Usage: record [--over] [--to <line>] [--return] [--steps N] [--vars $a,$b] [--timeout N] [--when <expr>]")
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s requires a value", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		switch name {
+		case "--to":
+			opts.to = value
+		case "--vars":
+			for _, variable := range strings.Split(value, ",") {
+				if variable = strings.TrimSpace(variable); variable != "" {
+					opts.vars = append(opts.vars, variable)
+				}
+			}
+		default:
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("invalid %s %q: must be a positive number", strings.TrimPrefix(name, "--"), value)
+			}
+			if name == "--steps" {
+				opts.steps = n
+			} else {
+				opts.timeout = time.Duration(n) * time.Second
+			}
+		}
+	}
+	return opts, nil
+}
+
+// handleRecord steps through the script from the current location and
+// records every executed statement until a stop condition is met
+func (e *CommandExecutor) handleRecord(args []string) ipc.CommandResult {
+	opts, err := parseRecordArgs(args)
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "record",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	if e.client.GetSession().GetState() != dbgp.StateBreak {
+		return ipc.CommandResult{
+			Command: "record",
+			Success: false,
+			Error:   "Cannot record: the script is not stopped. Hit a breakpoint first.",
+		}
+	}
+
+	var toFile string
+	var toLine int
+	if opts.to != "" {
+		toFile, toLine, err = e.parseLineLocation(opts.to)
+		if err != nil {
+			return ipc.CommandResult{
+				Command: "record",
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+	}
+
+	first, err := e.recordStatement(0, opts.vars)
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "record",
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	startDepth := first["depth"].(int)
+	trace := []map[string]interface{}{first}
+
+	stopped := ""
+	status := dbgp.StateBreak.String()
+	var stepErr error
+	deadline := time.Now().Add(opts.timeout)
+	for step := 1; stopped == ""; step++ {
+		if step > opts.steps {
+			stopped = "steps"
+			break
+		}
+		if time.Now().After(deadline) {
+			stopped = "timeout"
+			break
+		}
+
+		var response *dbgp.ProtocolResponse
+		if opts.over {
+			response, stepErr = e.client.Next()
+		} else {
+			response, stepErr = e.client.Step()
+		}
+		if stepErr == nil && response.HasError() {
+			stepErr = fmt.Errorf("%s", response.GetErrorMessage())
+		}
+		if stepErr != nil {
+			stopped = "error"
+			break
+		}
+		status = response.Status
+		if status != dbgp.StateBreak.String() {
+			stopped = "finished"
+			break
+		}
+
+		statement, err := e.recordStatement(step, opts.vars)
+		if err != nil {
+			stepErr = err
+			stopped = "error"
+			break
+		}
+		trace = append(trace, statement)
+
+		switch {
+		case toLine > 0 && statement["line"] == toLine && sameFile(statement["filename"].(string), toFile):
+			stopped = "line"
+		case opts.toReturn && statement["depth"].(int) < startDepth:
+			stopped = "return"
+		case opts.when != "" && e.evaluatesTrue(opts.when):
+			stopped = "condition"
+		}
+	}
+
+	file, line := e.currentLocation()
+	result := map[string]interface{}{
+		"stopped":  stopped,
+		"steps":    len(trace) - 1,
+		"status":   status,
+		"filename": file,
+		"line":     line,
+		"trace":    trace,
+	}
+	if stepErr != nil {
+		result["error"] = stepErr.Error()
+	}
+
+	return ipc.CommandResult{
+		Command: "record",
+		Success: true,
+		Result:  result,
+	}
+}
+
+// recordStatement describes the statement the script is stopped at: its
+// location, the stack depth and the values of the requested variables
+func (e *CommandExecutor) recordStatement(step int, variables []string) (map[string]interface{}, error) {
+	response, err := e.client.GetStackDepth()
+	if err != nil {
+		return nil, err
+	}
+	depth, _ := strconv.Atoi(response.Depth)
+
+	file, line := e.currentLocation()
+	statement := map[string]interface{}{
+		"step":     step,
+		"filename": file,
+		"line":     line,
+		"depth":    depth,
+	}
+
+	if len(variables) > 0 {
+		values := make(map[string]view.JSONProperty)
+		for _, variable := range variables {
+			// Variables that are not in scope are left out
+			response, err := e.client.GetProperty(strings.TrimPrefix(variable, "$"))
+			if err != nil || response.HasError() || len(response.Properties) == 0 {
+				continue
+			}
+			values[variable] = view.ConvertPropertyToJSON(&response.Properties[0])
+		}
+		statement["vars"] = values
+	}
+
+	return statement, nil
+}
+
+// evaluatesTrue evaluates a PHP expression and converts the result to a
+// boolean the way PHP does. Evaluation errors count as false.
+func (e *CommandExecutor) evaluatesTrue(expression string) bool {
+	response, err := e.client.Eval(expression)
+	if err != nil || response.HasError() || len(response.Properties) == 0 {
+		return false
+	}
+
+	prop := &response.Properties[0]
+	value, err := dbgp.DecodePropertyValue(prop)
+	if err != nil {
+		value = prop.Value
+	}
+	switch prop.Type {
+	case "null", "uninitialized":
+		return false
+	case "bool", "int", "string":
+		return value != "" && value != "0"
+	case "float":
+		f, err := strconv.ParseFloat(value, 64)
+		return err == nil && f != 0
+	case "array":
+		return prop.GetNumChildren() > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+func TestParseRecordArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    recordOptions
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: recordOptions{steps: DefaultRecordSteps, timeout: DefaultRecordTimeout},
+		},
+		{
+			name: "all options",
+			args: []string{"--over", "--to", ":42", "--return", "--steps=5", "--vars", "$a,$b", "--timeout", "10", "--when", "$a", ">", "3"},
+			want: recordOptions{over: true, to: ":42", toReturn: true, steps: 5, vars: []string{"$a", "$b"}, timeout: 10 * time.Second, when: "$a > 3"},
+		},
+		{name: "unknown option", args: []string{"--fast"}, wantErr: true},
+		{name: "missing value", args: []string{"--to"}, wantErr: true},
+		{name: "zero steps", args: []string{"--steps", "0"}, wantErr: true},
+		{name: "empty expression", args: []string{"--when"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseRecordArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRecordArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fmt.Sprintf("%+v", opts) != fmt.Sprintf("%+v", tt.want) {
+				t.Errorf("parseRecordArgs(%v) = %+v, want %+v", tt.args, opts, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+
+	stop := func(txID, line int) string {
+		return dbgpMessage(fmt.Sprintf(`<response xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="step_into" transaction_id="%d" status="break" reason="ok"><xdebug:message filename="file:///app/cart.php" lineno="%d"/></response>`, txID, line))
+	}
+	depth := func(txID, depth int) string {
+		return dbgpMessage(fmt.Sprintf(`<response command="stack_depth" transaction_id="%d" depth="%d"/>`, txID, depth))
+	}
+	value := func(txID int, value string) string {
+		return dbgpMessage(fmt.Sprintf(`<response command="property_get" transaction_id="%d"><property name="$i" fullname="$i" type="int"><![CDATA[%s]]></property></response>`, txID, value))
+	}
+
+	mockConn.readBuf.WriteString(stop(1, 10))
+	if result := executor.executeCommand("step", nil); !result.Success {
+		t.Fatalf("step failed: %s", result.Error)
+	}
+
+	// Start inside the function, step once within it, then return to the caller
+	mockConn.readBuf.WriteString(depth(2, 2) + value(3, "0"))
+	mockConn.readBuf.WriteString(stop(4, 11) + depth(5, 2) + value(6, "1"))
+	mockConn.readBuf.WriteString(stop(7, 30) + depth(8, 1))
+	mockConn.readBuf.WriteString(dbgpMessage(`<response command="property_get" transaction_id="9"><error code="300"><message>can not get property</message></error></response>`))
+
+	result := executor.executeCommand("record", []string{"--return", "--vars", "$i"})
+	if !result.Success {
+		t.Fatalf("record failed: %s", result.Error)
+	}
+	recordMap := result.Result.(map[string]interface{})
+	if recordMap["stopped"] != "return" || recordMap["steps"] != 2 {
+		t.Errorf("expected to stop on return after 2 steps, got %v after %v", recordMap["stopped"], recordMap["steps"])
+	}
+
+	trace := recordMap["trace"].([]map[string]interface{})
+	var lines []string
+	for _, statement := range trace {
+		lines = append(lines, fmt.Sprintf("%v:%v@%v", statement["step"], statement["line"], statement["depth"]))
+	}
+	if got := strings.Join(lines, " "); got != "0:10@2 1:11@2 2:30@1" {
+		t.Errorf("unexpected trace %s", got)
+	}
+	if vars := trace[1]["vars"].(map[string]view.JSONProperty); vars["$i"].Value != "1" {
+		t.Errorf("expected $i = 1 to be recorded, got %v", vars)
+	}
+	if vars := trace[2]["vars"].(map[string]view.JSONProperty); len(vars) != 0 {
+		t.Errorf("expected the out-of-scope $i to be left out, got %v", vars)
+	}
+	if sent := mockConn.writeBuf.String(); !strings.Contains(sent, "property_get -i 3 -d 0 -n i") {
+		t.Errorf("expected variables to be read, got %q", sent)
+	}
+}
+
+func TestRecord_RequiresBreak(t *testing.T) {
+	executor := NewCommandExecutor(dbgp.NewClient(dbgp.NewConnection(newMockConn())))
+	if result := executor.executeCommand("record", nil); result.Success {
+		t.Error("expected record to fail before the script stops")
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/console/xdebug-cli/internal/ipc"
 )
 
 // SessionInfo represents information about an active daemon session
@@ -16,9 +18,19 @@ type SessionInfo struct {
 	PID        int       `json:"pid"`
 	Port       int       `json:"port"`
 	SocketPath string    `json:"socket_path"`
+	Token      string    `json:"token,omitempty"`
+	IPCAddr    string    `json:"ipc_addr,omitempty"`
 	StartedAt  time.Time `json:"started_at"`
 }
 
+// IPCClient returns a client for the daemon's IPC socket that authenticates
+// with the daemon's token
+func (s SessionInfo) IPCClient() *ipc.Client {
+	client := ipc.NewClient(s.SocketPath)
+	client.SetToken(s.Token)
+	return client
+}
+
 // SessionRegistry manages the registry of active daemon sessions
 type SessionRegistry struct {
 	path     string
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// RuntimeDir returns the directory for the daemon's socket, PID, status and
+// log files: $XDG_RUNTIME_DIR/xdebug-cli, or a per-user directory in the
+// system temp directory. It is created with owner-only permissions; a
+// directory that belongs to another user is refused.
+func RuntimeDir() (string, error) {
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("xdebug-cli-%d", os.Getuid()))
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		dir = filepath.Join(runtimeDir, "xdebug-cli")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create runtime directory: %w", err)
+	}
+
+	// Another user could have created the directory first in a shared temp directory
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to check runtime directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("runtime directory %s is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return "", fmt.Errorf("runtime directory %s belongs to another user", dir)
+	}
+	if info.Mode().Perm() != 0700 {
+		if err := os.Chmod(dir, 0700); err != nil {
+			return "", fmt.Errorf("failed to restrict runtime directory: %w", err)
+		}
+	}
+
+	return dir, nil
+}
+
+// runtimeFile returns the path of a daemon file in the runtime directory,
+// e.g. runtimeFile("daemon-%d.pid", 9003)
+func runtimeFile(format string, port int) (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf(format, port)), nil
+}
+
+// LogFilePath returns the path of the log file of the daemon on a port
+func LogFilePath(port int) (string, error) {
+	return runtimeFile("daemon-%d.log", port)
+}
+
+// newToken returns a random token that IPC clients must present to the daemon
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate IPC token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRuntimeDir(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	dir, err := RuntimeDir()
+	if err != nil {
+		t.Fatalf("RuntimeDir() error = %v", err)
+	}
+	if want := filepath.Join(runtimeDir, "xdebug-cli"); dir != want {
+		t.Errorf("RuntimeDir() = %s, want %s", dir, want)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("permissions = %o, want 700", perm)
+	}
+
+	// A directory left with loose permissions is restricted again
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RuntimeDir(); err != nil {
+		t.Fatalf("RuntimeDir() error = %v", err)
+	}
+	if info, _ := os.Stat(dir); info.Mode().Perm() != 0700 {
+		t.Errorf("permissions = %o, want 700", info.Mode().Perm())
+	}
+}
+
+func TestRuntimeDir_Fallback(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", tempDir)
+
+	dir, err := RuntimeDir()
+	if err != nil {
+		t.Fatalf("RuntimeDir() error = %v", err)
+	}
+	if want := filepath.Join(tempDir, fmt.Sprintf("xdebug-cli-%d", os.Getuid())); dir != want {
+		t.Errorf("RuntimeDir() = %s, want %s", dir, want)
+	}
+
+	// A file in place of the directory is refused
+	t.Setenv("TMPDIR", t.TempDir())
+	if err := os.WriteFile(filepath.Join(os.TempDir(), fmt.Sprintf("xdebug-cli-%d", os.Getuid())), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RuntimeDir(); err == nil {
+		t.Error("RuntimeDir() should refuse a file in place of the directory")
+	}
+}
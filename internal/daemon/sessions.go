@@ -0,0 +1,167 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+)
+
+// DebugSession is one Xdebug connection held by the daemon
+type DebugSession struct {
+	ID          int
+	Client      *dbgp.Client
+	Executor    *CommandExecutor
+	IDEKey      string
+	FileURI     string
+	ConnectedAt time.Time
+	done        chan struct{}
+}
+
+// State returns the DBGp execution state of the session
+func (s *DebugSession) State() dbgp.SessionStateType {
+	return s.Client.GetSession().GetState()
+}
+
+// Done returns a channel that is closed when the session ends
+func (s *DebugSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// SessionPool holds the concurrent Xdebug connections of a daemon.
+// Commands without an explicit session go to the selected session, or to the
+// session that most recently stopped at a breakpoint if none is selected.
+type SessionPool struct {
+	mu       sync.RWMutex
+	sessions map[int]*DebugSession
+	nextID   int
+	selected int
+}
+
+// NewSessionPool creates an empty session pool
+func NewSessionPool() *SessionPool {
+	return &SessionPool{
+		sessions: make(map[int]*DebugSession),
+	}
+}
+
+// Add registers a new connection and assigns it the next session ID
+func (p *SessionPool) Add(client *dbgp.Client, executor *CommandExecutor) *DebugSession {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nextID++
+	session := &DebugSession{
+		ID:          p.nextID,
+		Client:      client,
+		Executor:    executor,
+		IDEKey:      client.GetSession().GetIDEKey(),
+		ConnectedAt: time.Now(),
+		done:        make(chan struct{}),
+	}
+	if files := client.GetSession().GetTargetFiles(); len(files) > 0 {
+		session.FileURI = files[0]
+	}
+
+	p.sessions[session.ID] = session
+	return session
+}
+
+// Remove drops a session from the pool.
+// Returns false if the session was already removed.
+func (p *SessionPool) Remove(id int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.sessions[id]; !ok {
+		return false
+	}
+	delete(p.sessions, id)
+	if p.selected == id {
+		p.selected = 0
+	}
+	return true
+}
+
+// Get returns the session with the given ID
+func (p *SessionPool) Get(id int) (*DebugSession, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	session, ok := p.sessions[id]
+	return session, ok
+}
+
+// Lookup resolves a session ID as sent over IPC
+func (p *SessionPool) Lookup(id string) (*DebugSession, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID: %s", id)
+	}
+	session, ok := p.Get(n)
+	if !ok {
+		return nil, fmt.Errorf("session %d not found", n)
+	}
+	return session, nil
+}
+
+// List returns all sessions ordered by ID
+func (p *SessionPool) List() []*DebugSession {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	sessions := make([]*DebugSession, 0, len(p.sessions))
+	for _, session := range p.sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// Len returns the number of active sessions
+func (p *SessionPool) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.sessions)
+}
+
+// Select makes the session the default target for commands without a session
+func (p *SessionPool) Select(id int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.sessions[id]; !ok {
+		return fmt.Errorf("session %d not found", id)
+	}
+	p.selected = id
+	return nil
+}
+
+// Current returns the default target session: the selected one if set,
+// otherwise the session that most recently stopped at a breakpoint, otherwise
+// the newest session. Returns nil if the pool is empty.
+func (p *SessionPool) Current() *DebugSession {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if session, ok := p.sessions[p.selected]; ok {
+		return session
+	}
+
+	var current *DebugSession
+	var currentBreak time.Time
+	for _, session := range p.sessions {
+		lastBreak := session.Client.GetSession().LastBreakAt()
+		switch {
+		case current == nil,
+			lastBreak.After(currentBreak),
+			lastBreak.Equal(currentBreak) && session.ID > current.ID:
+			current = session
+			currentBreak = lastBreak
+		}
+	}
+	return current
+}
@@ -0,0 +1,163 @@
+package daemon
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+)
+
+func newTestSession(pool *SessionPool) (*DebugSession, *mockConn) {
+	conn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(conn))
+	return pool.Add(client, NewCommandExecutor(client)), conn
+}
+
+func TestSessionPool_AddRemove(t *testing.T) {
+	pool := NewSessionPool()
+
+	first, _ := newTestSession(pool)
+	second, _ := newTestSession(pool)
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("session IDs = %d, %d; want 1, 2", first.ID, second.ID)
+	}
+	if pool.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", pool.Len())
+	}
+
+	if !pool.Remove(first.ID) {
+		t.Error("Remove() = false, want true")
+	}
+	if pool.Remove(first.ID) {
+		t.Error("Remove() second call = true, want false")
+	}
+
+	if _, err := pool.Lookup("1"); err == nil {
+		t.Error("Lookup(1) after Remove succeeded, want error")
+	}
+	if _, err := pool.Lookup("abc"); err == nil {
+		t.Error("Lookup(abc) succeeded, want error")
+	}
+	if session, err := pool.Lookup("2"); err != nil || session != second {
+		t.Errorf("Lookup(2) = %v, %v", session, err)
+	}
+}
+
+func TestSessionPool_Current(t *testing.T) {
+	pool := NewSessionPool()
+
+	if pool.Current() != nil {
+		t.Fatal("Current() on empty pool should be nil")
+	}
+
+	first, _ := newTestSession(pool)
+	second, _ := newTestSession(pool)
+
+	// Without breaks the newest session is the default
+	if pool.Current() != second {
+		t.Errorf("Current() = %d, want newest session %d", pool.Current().ID, second.ID)
+	}
+
+	// The most recently broken session wins
+	first.Client.GetSession().SetState(dbgp.StateBreak)
+	if pool.Current() != first {
+		t.Errorf("Current() = %d, want broken session %d", pool.Current().ID, first.ID)
+	}
+
+	time.Sleep(time.Millisecond)
+	second.Client.GetSession().SetState(dbgp.StateBreak)
+	if pool.Current() != second {
+		t.Errorf("Current() = %d, want most recently broken session %d", pool.Current().ID, second.ID)
+	}
+
+	// An explicit selection overrides the default until the session goes away
+	if err := pool.Select(first.ID); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if pool.Current() != first {
+		t.Errorf("Current() = %d, want selected session %d", pool.Current().ID, first.ID)
+	}
+	if err := pool.Select(42); err == nil {
+		t.Error("Select(42) succeeded, want error")
+	}
+
+	pool.Remove(first.ID)
+	if pool.Current() != second {
+		t.Errorf("Current() after removing selection = %d, want %d", pool.Current().ID, second.ID)
+	}
+}
+
+func TestSplitAtSessionSwitch(t *testing.T) {
+	tests := []struct {
+		commands []string
+		want     [][]string
+	}{
+		{[]string{"run"}, [][]string{{"run"}}},
+		{[]string{"sessions", "run"}, [][]string{{"sessions", "run"}}},
+		{[]string{"session 2", "run"}, [][]string{{"session 2"}, {"run"}}},
+		{[]string{"step", "session 2", "run", "session 1"}, [][]string{{"step", "session 2"}, {"run", "session 1"}}},
+	}
+
+	for _, tt := range tests {
+		got := splitAtSessionSwitch(tt.commands)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitAtSessionSwitch(%v) = %v, want %v", tt.commands, got, tt.want)
+		}
+	}
+}
+
+func TestDaemon_ExecuteCommands_SessionSwitch(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Setenv("HOME", tempDir)
+	defer os.Unsetenv("HOME")
+
+	daemon, err := NewDaemon(dbgp.NewServer("127.0.0.1", 9003), 9003)
+	if err != nil {
+		t.Fatalf("NewDaemon() error = %v", err)
+	}
+
+	firstConn := newMockConn()
+	first := daemon.AddSession(dbgp.NewClient(dbgp.NewConnection(firstConn)))
+	secondConn := newMockConn()
+	second := daemon.AddSession(dbgp.NewClient(dbgp.NewConnection(secondConn)))
+
+	firstConn.readBuf.WriteString(dbgpMessage(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="step_into" transaction_id="1" status="break" reason="ok">
+<xdebug:message filename="file:///first.php" lineno="3"/>
+</response>`))
+
+	// "session 1" moves the rest of the batch to the first session
+	results := daemon.ExecuteCommands([]string{"session 1; step"}, false)
+	if len(results) != 2 || !results[1].Success {
+		t.Fatalf("ExecuteCommands() = %+v", results)
+	}
+	if !strings.Contains(firstConn.writeBuf.String(), "step_into") {
+		t.Errorf("step was not sent to session %d", first.ID)
+	}
+	if secondConn.writeBuf.Len() != 0 {
+		t.Errorf("session %d received commands: %q", second.ID, secondConn.writeBuf.String())
+	}
+
+	// A request can target a session explicitly
+	req := ipc.NewExecuteCommandsRequest([]string{"sessions"}, false)
+	req.Session = "2"
+	resp := daemon.handleIPCRequest(req)
+	if !resp.Success {
+		t.Fatalf("handleIPCRequest() error = %s", resp.Error)
+	}
+	sessions := resp.Results[0].Result.(map[string]interface{})["sessions"].([]map[string]interface{})
+	if len(sessions) != 2 || sessions[0]["current"] != true {
+		t.Errorf("sessions = %v, want 2 sessions with session 1 current", sessions)
+	}
+
+	req.Session = "9"
+	resp = daemon.handleIPCRequest(req)
+	if resp.Success || resp.Error != "session 9 not found" {
+		t.Errorf("handleIPCRequest() for unknown session = %+v", resp)
+	}
+}
@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/ipc"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+// watch is an expression that is re-evaluated every time the script stops
+type watch struct {
+	id         int
+	expression string
+	value      *view.JSONProperty // last value, nil if not evaluated or failed
+	err        string             // last evaluation error
+	evaluated  bool
+}
+
+// fingerprint identifies the last result so that changes can be detected
+func (w *watch) fingerprint() string {
+	if w.err != "" {
+		return "error: " + w.err
+	}
+	data, _ := json.Marshal(w.value)
+	return string(data)
+}
+
+// result returns the JSON representation of the watch
+func (w *watch) result() map[string]interface{} {
+	result := map[string]interface{}{
+		"id":         w.id,
+		"expression": w.expression,
+	}
+	if w.value != nil {
+		result["value"] = *w.value
+	}
+	if w.err != "" {
+		result["error"] = w.err
+	}
+	return result
+}
+
+// evaluate evaluates the watch expression in the innermost frame and reports
+// whether the result differs from the previous evaluation
+func (w *watch) evaluate(client *dbgp.Client) bool {
+	previous, evaluated := w.fingerprint(), w.evaluated
+	w.value, w.err = nil, ""
+	w.evaluated = true
+
+	response, err := client.Eval(w.expression)
+	switch {
+	case err != nil:
+		w.err = err.Error()
+	case response.HasError():
+		w.err = response.GetErrorMessage()
+	case len(response.Properties) == 0:
+		w.err = "No result returned"
+	default:
+		value := view.ConvertPropertyToJSON(&response.Properties[0])
+		w.value = &value
+	}
+
+	return evaluated && w.fingerprint() != previous
+}
+
+// watchResults re-evaluates every watch for a command that stopped the script
+// at a new location, returning nil if there are no watches or the script did
+// not stop at a break
+func (e *CommandExecutor) watchResults(status string) []map[string]interface{} {
+	if len(e.watches) == 0 || status != dbgp.StateBreak.String() {
+		return nil
+	}
+	results := make([]map[string]interface{}, 0, len(e.watches))
+	for _, w := range e.watches {
+		changed := w.evaluate(e.client)
+		result := w.result()
+		result["changed"] = changed
+		results = append(results, result)
+	}
+	return results
+}
+
+// addWatchResults adds the watch values to the result of a command that
+// stopped the script
+func (e *CommandExecutor) addWatchResults(result map[string]interface{}, status string) map[string]interface{} {
+	if watches := e.watchResults(status); watches != nil {
+		result["watches"] = watches
+	}
+	return result
+}
+
+// handleWatch registers an expression to evaluate at every stop
+// Syntax: watch <expression>
+func (e *CommandExecutor) handleWatch(args []string) ipc.CommandResult {
+	if len(args) == 0 {
+		return ipc.CommandResult{
+			Command: "watch",
+			Success: false,
+			Error:   "Usage: watch <expression>",
+		}
+	}
+
+	e.nextWatchID++
+	w := &watch{id: e.nextWatchID, expression: strings.Join(args, " ")}
+	e.watches = append(e.watches, w)
+
+	// Show the current value right away when the script is stopped
+	if e.client.GetSession().GetState() == dbgp.StateBreak {
+		w.evaluate(e.client)
+	}
+
+	return ipc.CommandResult{
+		Command: "watch",
+		Success: true,
+		Result:  w.result(),
+	}
+}
+
+// handleWatches lists the watch expressions with their last values
+func (e *CommandExecutor) handleWatches() ipc.CommandResult {
+	watches := make([]map[string]interface{}, 0, len(e.watches))
+	for _, w := range e.watches {
+		watches = append(watches, w.result())
+	}
+
+	return ipc.CommandResult{
+		Command: "watches",
+		Success: true,
+		Result: map[string]interface{}{
+			"watches": watches,
+		},
+	}
+}
+
+// handleUnwatch removes a watch expression by ID
+// Syntax: unwatch <id>
+func (e *CommandExecutor) handleUnwatch(args []string) ipc.CommandResult {
+	if len(args) != 1 {
+		return ipc.CommandResult{
+			Command: "unwatch",
+			Success: false,
+			Error:   "Usage: unwatch <id>",
+		}
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return ipc.CommandResult{
+			Command: "unwatch",
+			Success: false,
+			Error:   fmt.Sprintf("Invalid watch ID: %s", args[0]),
+		}
+	}
+
+	for i, w := range e.watches {
+		if w.id != id {
+			continue
+		}
+		e.watches = append(e.watches[:i], e.watches[i+1:]...)
+		return ipc.CommandResult{
+			Command: "unwatch",
+			Success: true,
+			Result: map[string]interface{}{
+				"id":         id,
+				"expression": w.expression,
+			},
+		}
+	}
+
+	return ipc.CommandResult{
+		Command: "unwatch",
+		Success: false,
+		Error:   fmt.Sprintf("No watch with ID %d", id),
+	}
+}
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/console/xdebug-cli/internal/dbgp"
+	"github.com/console/xdebug-cli/internal/view"
+)
+
+func TestWatches(t *testing.T) {
+	mockConn := newMockConn()
+	client := dbgp.NewClient(dbgp.NewConnection(mockConn))
+	executor := NewCommandExecutor(client)
+
+	// Not stopped yet: the watch is registered without evaluating it
+	result := executor.executeCommand("watch", []string{"$order->total"})
+	if !result.Success {
+		t.Fatalf("watch failed: %s", result.Error)
+	}
+	if _, ok := result.Result.(map[string]interface{})["value"]; ok {
+		t.Error("expected no value before the script stops")
+	}
+
+	stops := []struct {
+		command     string
+		value       string
+		wantChanged bool
+	}{
+		{"step", "10", false},
+		{"next", "12", true},
+		{"next", "12", false},
+	}
+	txID := 0
+	for _, stop := range stops {
+		txID += 2
+		mockConn.readBuf.WriteString(dbgpMessage(fmt.Sprintf(`<response command="%s" transaction_id="%d" status="break" reason="ok"/>`, stop.command, txID-1)))
+		mockConn.readBuf.WriteString(dbgpMessage(fmt.Sprintf(`<response command="eval" transaction_id="%d"><property type="int"><![CDATA[%s]]></property></response>`, txID, stop.value)))
+
+		result := executor.executeCommand(stop.command, nil)
+		if !result.Success {
+			t.Fatalf("%s failed: %s", stop.command, result.Error)
+		}
+		watches, ok := result.Result.(map[string]interface{})["watches"].([]map[string]interface{})
+		if !ok || len(watches) != 1 {
+			t.Fatalf("%s: expected one watch in the result, got %v", stop.command, result.Result)
+		}
+		if value := watches[0]["value"].(view.JSONProperty); value.Value != stop.value {
+			t.Errorf("%s: expected value %s, got %q", stop.command, stop.value, value.Value)
+		}
+		if watches[0]["changed"] != stop.wantChanged {
+			t.Errorf("%s: expected changed %v, got %v", stop.command, stop.wantChanged, watches[0]["changed"])
+		}
+	}
+
+	result = executor.executeCommand("watches", nil)
+	listed := result.Result.(map[string]interface{})["watches"].([]map[string]interface{})
+	if len(listed) != 1 || listed[0]["expression"] != "$order->total" {
+		t.Errorf("expected the registered watch, got %v", listed)
+	}
+
+	if result := executor.executeCommand("unwatch", []string{"1"}); !result.Success {
+		t.Errorf("unwatch failed: %s", result.Error)
+	}
+	if result := executor.executeCommand("unwatch", []string{"1"}); result.Success {
+		t.Error("expected unwatch of a removed watch to fail")
+	}
+	if len(executor.watches) != 0 {
+		t.Errorf("expected no watches left, got %d", len(executor.watches))
+	}
+}
@@ -9,16 +9,45 @@ import (
 
 // Client represents a DBGp client for debugging operations
 type Client struct {
-	conn    *Connection
-	session *Session
+	conn        *Connection
+	session     *Session
+	pathMapper  *PathMapper
+	breakFilter BreakFilter
+}
+
+// BreakFilter lets the client pass over some breaks, e.g. logpoints that
+// only log a message
+type BreakFilter interface {
+	// Filtering reports whether any break could be passed over. Steps only
+	// track the stack depth while it is true.
+	Filtering() bool
+
+	// PassBreak is called at each break with the location reported by Xdebug.
+	// Returning true resumes execution instead of reporting the stop, e.g.
+	// after a logpoint has logged its message.
+	PassBreak(filename string, line int) bool
 }
 
 // NewClient creates a new DBGp client
 func NewClient(conn *Connection) *Client {
-	return &Client{
+	c := &Client{
 		conn:    conn,
 		session: NewSession(),
 	}
+	conn.Subscribe(c.handleAsyncPacket)
+	return c
+}
+
+// SetPathMapper configures remote<->local path translation for this client.
+// Local paths given to breakpoint and source commands are sent to Xdebug as
+// remote paths, and stack frames report local paths.
+func (c *Client) SetPathMapper(mapper *PathMapper) {
+	c.pathMapper = mapper
+}
+
+// PathMapper returns the client's path mapper (nil if no mappings are configured)
+func (c *Client) PathMapper() *PathMapper {
+	return c.pathMapper
 }
 
 // Init reads the initial protocol message and sets up the session
@@ -50,26 +79,89 @@ func (c *Client) Init() (*ProtocolInit, error) {
 	return init, nil
 }
 
+// SetBreakFilter installs a filter that lets Run and RunAsync pass over some
+// breaks without reporting them. Next and StepOut pass over such breaks in
+// the calls they step over.
+func (c *Client) SetBreakFilter(filter BreakFilter) {
+	c.breakFilter = filter
+}
+
+// passBreak reports whether the break filter wants execution to resume
+// after a run or step response
+func (c *Client) passBreak(response *ProtocolResponse) bool {
+	if c.breakFilter == nil || response.Status != "break" || len(response.Message) == 0 {
+		return false
+	}
+	line, err := strconv.Atoi(response.Message[0].Lineno)
+	if err != nil {
+		return false
+	}
+	return c.breakFilter.PassBreak(response.Message[0].Filename, line)
+}
+
 // Run sends the run command to continue execution
 func (c *Client) Run() (*ProtocolResponse, error) {
-	txID := c.session.NextTransactionIDInt()
-	command := fmt.Sprintf("run -i %d", txID)
-	c.session.AddCommand(strconv.Itoa(txID), "run")
+	for {
+		txID := c.session.NextTransactionIDInt()
+		command := fmt.Sprintf("run -i %d", txID)
+		c.session.AddCommand(strconv.Itoa(txID), "run")
+		c.session.SetState(StateRunning)
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
+		response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
+		if err != nil {
+			return nil, err
+		}
+		if c.passBreak(response) {
+			continue
+		}
+
+		// Update session state based on response
+		c.updateSessionFromResponse(response)
+
+		return response, nil
 	}
+}
 
-	response, err := c.conn.GetResponse()
+// RunAsync sends the run command without waiting for the script to stop.
+// The session state and location are updated when Xdebug responds.
+func (c *Client) RunAsync() error {
+	replies, err := c.sendRun()
 	if err != nil {
-		return nil, err
-	}
+		return err
+	}
+
+	go func() {
+		for {
+			reply := <-replies
+			if reply.Err != nil {
+				// The connection is gone, so the script will not stop again
+				c.session.SetState(StateStopped)
+				return
+			}
+			if !c.passBreak(reply.Response) {
+				c.updateSessionFromResponse(reply.Response)
+				return
+			}
+			next, err := c.sendRun()
+			if err != nil {
+				c.session.SetState(StateStopped)
+				return
+			}
+			replies = next
+		}
+	}()
 
-	// Update session state based on response
-	c.updateSessionFromResponse(response)
+	return nil
+}
 
-	return response, nil
+// sendRun sends the run command and returns the channel its reply arrives on
+func (c *Client) sendRun() (<-chan Reply, error) {
+	txID := c.session.NextTransactionIDInt()
+	command := fmt.Sprintf("run -i %d", txID)
+	c.session.AddCommand(strconv.Itoa(txID), "run")
+	c.session.SetState(StateRunning)
+
+	return c.conn.Send(strconv.Itoa(txID), command)
 }
 
 // Step sends the step_into command
@@ -77,17 +169,15 @@ func (c *Client) Step() (*ProtocolResponse, error) {
 	txID := c.session.NextTransactionIDInt()
 	command := fmt.Sprintf("step_into -i %d", txID)
 	c.session.AddCommand(strconv.Itoa(txID), "step_into")
+	c.session.SetState(StateRunning)
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := c.conn.GetResponse()
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
 	if err != nil {
 		return nil, err
 	}
 
+	// Stepping onto a logpoint still stops, but its message is logged
+	c.passBreak(response)
 	c.updateSessionFromResponse(response)
 
 	return response, nil
@@ -95,16 +185,33 @@ func (c *Client) Step() (*ProtocolResponse, error) {
 
 // Next sends the step_over command
 func (c *Client) Next() (*ProtocolResponse, error) {
-	txID := c.session.NextTransactionIDInt()
-	command := fmt.Sprintf("step_over -i %d", txID)
-	c.session.AddCommand(strconv.Itoa(txID), "step_over")
+	return c.stepOver("step_over", 0)
+}
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
+// StepOut sends the step_out command
+// Steps out of current scope and breaks after returning from current function
+// Also known as "finish" in GDB
+func (c *Client) StepOut() (*ProtocolResponse, error) {
+	return c.stepOver("step_out", -1)
+}
+
+// stepOver sends step_over or step_out. Xdebug stops at breakpoints inside
+// the calls these step over; when such a stop is at a logpoint, its message
+// is logged and the step is finished with step_out until execution is back
+// at most maxDepth frames deeper than where the step started.
+func (c *Client) stepOver(command string, maxDepth int) (*ProtocolResponse, error) {
+	startDepth := -1
+	if c.breakFilter != nil && c.breakFilter.Filtering() {
+		startDepth = c.stackDepth()
 	}
 
-	response, err := c.conn.GetResponse()
+	response, err := c.sendStep(command)
+	for err == nil && c.passBreak(response) && startDepth >= 0 {
+		if depth := c.stackDepth(); depth < 0 || depth <= startDepth+maxDepth {
+			break
+		}
+		response, err = c.sendStep("step_out")
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -114,27 +221,37 @@ func (c *Client) Next() (*ProtocolResponse, error) {
 	return response, nil
 }
 
-// StepOut sends the step_out command
-// Steps out of current scope and breaks after returning from current function
-// Also known as "finish" in GDB
-func (c *Client) StepOut() (*ProtocolResponse, error) {
+// sendStep sends a step command and returns Xdebug's response
+func (c *Client) sendStep(command string) (*ProtocolResponse, error) {
 	txID := c.session.NextTransactionIDInt()
-	command := fmt.Sprintf("step_out -i %d", txID)
-	c.session.AddCommand(strconv.Itoa(txID), "step_out")
+	c.session.AddCommand(strconv.Itoa(txID), command)
+	c.session.SetState(StateRunning)
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
+	return c.conn.SendCommand(strconv.Itoa(txID), fmt.Sprintf("%s -i %d", command, txID))
+}
 
-	response, err := c.conn.GetResponse()
+// stackDepth returns the number of stack frames, or -1 if Xdebug does not say
+func (c *Client) stackDepth() int {
+	response, err := c.GetStackDepth()
+	if err != nil || response.HasError() {
+		return -1
+	}
+	depth, err := strconv.Atoi(response.Depth)
 	if err != nil {
-		return nil, err
+		return -1
 	}
+	return depth
+}
 
-	c.updateSessionFromResponse(response)
+// Break interrupts the running script. It is sent while a continuation
+// command (run, step) is still waiting; that command then returns with
+// status "break" at the location where the script was interrupted.
+func (c *Client) Break() (*ProtocolResponse, error) {
+	txID := c.session.NextTransactionIDInt()
+	command := fmt.Sprintf("break -i %d", txID)
+	c.session.AddCommand(strconv.Itoa(txID), "break")
 
-	return response, nil
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // Finish sends the stop command to end the debugging session
@@ -144,12 +261,7 @@ func (c *Client) Finish() (*ProtocolResponse, error) {
 	c.session.AddCommand(strconv.Itoa(txID), "stop")
 	c.session.SetState(StateStopping)
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := c.conn.GetResponse()
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
 	if err != nil {
 		return nil, err
 	}
@@ -165,12 +277,7 @@ func (c *Client) Status() (*ProtocolResponse, error) {
 	command := fmt.Sprintf("status -i %d", txID)
 	c.session.AddCommand(strconv.Itoa(txID), "status")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := c.conn.GetResponse()
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
 	if err != nil {
 		return nil, err
 	}
@@ -187,12 +294,7 @@ func (c *Client) Detach() (*ProtocolResponse, error) {
 	c.session.AddCommand(strconv.Itoa(txID), "detach")
 	c.session.SetState(StateStopping)
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := c.conn.GetResponse()
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
 	if err != nil {
 		return nil, err
 	}
@@ -204,6 +306,26 @@ func (c *Client) Detach() (*ProtocolResponse, error) {
 
 // SetBreakpoint sets a line breakpoint
 func (c *Client) SetBreakpoint(file string, line int, condition string) (*ProtocolResponse, error) {
+	return c.SetLineBreakpoint(file, line, BreakpointOptions{Condition: condition})
+}
+
+// BreakpointOptions holds the optional arguments of a line breakpoint
+type BreakpointOptions struct {
+	// Condition is a PHP expression; the breakpoint only breaks when it is true
+	Condition string
+
+	// HitValue and HitCondition make the breakpoint break only on some hits:
+	// ">=" breaks once the hit count reaches HitValue, "==" breaks only on
+	// that hit and "%" breaks on every multiple of it (0 = every hit)
+	HitValue     int
+	HitCondition string
+
+	// Temporary breakpoints are removed by Xdebug after their first hit
+	Temporary bool
+}
+
+// SetLineBreakpoint sets a line breakpoint with optional condition and hit count
+func (c *Client) SetLineBreakpoint(file string, line int, opts BreakpointOptions) (*ProtocolResponse, error) {
 	txID := c.session.NextTransactionIDInt()
 
 	// Convert file path to file:// URI if needed
@@ -211,35 +333,42 @@ func (c *Client) SetBreakpoint(file string, line int, condition string) (*Protoc
 
 	command := fmt.Sprintf("breakpoint_set -i %d -t line -f %s -n %d", txID, fileURI, line)
 
-	if condition != "" {
+	if opts.HitValue > 0 {
+		command += fmt.Sprintf(" -h %d", opts.HitValue)
+		if opts.HitCondition != "" {
+			command += fmt.Sprintf(" -o %s", opts.HitCondition)
+		}
+	}
+
+	if opts.Temporary {
+		command += " -r 1"
+	}
+
+	if opts.Condition != "" {
 		// Encode condition in base64
-		encoded := base64.StdEncoding.EncodeToString([]byte(condition))
+		encoded := base64.StdEncoding.EncodeToString([]byte(opts.Condition))
 		command += fmt.Sprintf(" -- %s", encoded)
 	}
 
 	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_set")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // normalizeFileURI converts a file path to a file:// URI
 // If the path is already a file:// URI, returns it as-is
 // If it's an absolute path, converts to file:// URI
 // If it's a relative path, resolves it against the project root from init FileURI
+// Absolute local paths are translated to remote paths using the path mapper
 func (c *Client) normalizeFileURI(file string) string {
 	// Already a file:// URI
 	if strings.HasPrefix(file, "file://") {
-		return file
+		return c.pathMapper.ToRemote(file)
 	}
 
 	// Absolute path - convert to file:// URI
 	if strings.HasPrefix(file, "/") {
-		return "file://" + file
+		return "file://" + c.pathMapper.ToRemote(file)
 	}
 
 	// Relative path - need to resolve against project root
@@ -321,16 +450,39 @@ func getLastDirName(path string) string {
 
 // SetBreakpointToCall sets a function call breakpoint
 func (c *Client) SetBreakpointToCall(funcName string) (*ProtocolResponse, error) {
+	return c.setFunctionBreakpoint("call", funcName)
+}
+
+// SetBreakpointToReturn sets a breakpoint on returning from a function or
+// Class::method
+func (c *Client) SetBreakpointToReturn(funcName string) (*ProtocolResponse, error) {
+	return c.setFunctionBreakpoint("return", funcName)
+}
+
+// setFunctionBreakpoint sets a call or return breakpoint. A Class::method name
+// is sent as class (-a) and method (-m).
+func (c *Client) setFunctionBreakpoint(bpType, funcName string) (*ProtocolResponse, error) {
 	txID := c.session.NextTransactionIDInt()
-	command := fmt.Sprintf("breakpoint_set -i %d -t call -m %s", txID, funcName)
+	command := fmt.Sprintf("breakpoint_set -i %d -t %s", txID, bpType)
+	if class, method, ok := strings.Cut(funcName, "::"); ok {
+		command += fmt.Sprintf(" -a %s -m %s", class, method)
+	} else {
+		command += fmt.Sprintf(" -m %s", funcName)
+	}
 	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_set")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
+}
 
-	return c.conn.GetResponse()
+// SetConditionalBreakpoint sets a breakpoint that breaks on any line where
+// the PHP expression is true
+func (c *Client) SetConditionalBreakpoint(expression string) (*ProtocolResponse, error) {
+	txID := c.session.NextTransactionIDInt()
+	encoded := base64.StdEncoding.EncodeToString([]byte(expression))
+	command := fmt.Sprintf("breakpoint_set -i %d -t conditional -- %s", txID, encoded)
+	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_set")
+
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // SetExceptionBreakpoint sets an exception breakpoint
@@ -344,12 +496,7 @@ func (c *Client) SetExceptionBreakpoint(exceptionName string) (*ProtocolResponse
 
 	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_set")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // GetBreakpointList retrieves the list of all breakpoints
@@ -358,12 +505,7 @@ func (c *Client) GetBreakpointList() (*ProtocolResponse, error) {
 	command := fmt.Sprintf("breakpoint_list -i %d", txID)
 	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_list")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // RemoveBreakpoint removes a breakpoint by ID
@@ -372,12 +514,17 @@ func (c *Client) RemoveBreakpoint(breakpointID string) (*ProtocolResponse, error
 	command := fmt.Sprintf("breakpoint_remove -i %d -d %s", txID, breakpointID)
 	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_remove")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
+}
+
+// GetBreakpoint retrieves a breakpoint by ID, including the line Xdebug
+// resolved it to
+func (c *Client) GetBreakpoint(breakpointID string) (*ProtocolResponse, error) {
+	txID := c.session.NextTransactionIDInt()
+	command := fmt.Sprintf("breakpoint_get -i %d -d %s", txID, breakpointID)
+	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_get")
 
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // UpdateBreakpoint updates a breakpoint state (enabled/disabled)
@@ -391,29 +538,71 @@ func (c *Client) UpdateBreakpoint(breakpointID, state string) (*ProtocolResponse
 	command := fmt.Sprintf("breakpoint_update -i %d -d %s -s %s", txID, breakpointID, state)
 	c.session.AddCommand(strconv.Itoa(txID), "breakpoint_update")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
+}
 
-	return c.conn.GetResponse()
+// PropertyOptions controls how much of a property is retrieved
+type PropertyOptions struct {
+	Page     int // Page of children to retrieve (0 = first page)
+	MaxDepth int // Nesting levels to expand (0 = session max_depth)
 }
 
-// GetProperty retrieves the value of a property/variable
+// GetProperty retrieves the value of a property/variable in the selected frame
 func (c *Client) GetProperty(name string) (*ProtocolResponse, error) {
+	return c.GetPropertyWithOptions(name, PropertyOptions{})
+}
+
+// GetPropertyWithOptions retrieves a property/variable in the selected frame,
+// selecting a page of its children and optionally a deeper nesting level.
+// property_get has no depth argument, so MaxDepth temporarily changes the
+// max_depth feature and restores it afterwards.
+func (c *Client) GetPropertyWithOptions(name string, opts PropertyOptions) (*ProtocolResponse, error) {
+	if opts.MaxDepth > 0 {
+		current, err := c.FeatureGet("max_depth")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read max_depth: %w", err)
+		}
+		previous := strings.TrimSpace(current.Source)
+		if previous != strconv.Itoa(opts.MaxDepth) {
+			if err := c.FeatureSet("max_depth", strconv.Itoa(opts.MaxDepth)); err != nil {
+				return nil, err
+			}
+			if previous != "" {
+				defer c.FeatureSet("max_depth", previous)
+			}
+		}
+	}
+
 	txID := c.session.NextTransactionIDInt()
-	command := fmt.Sprintf("property_get -i %d -d 0 -n %s", txID, name)
+	command := fmt.Sprintf("property_get -i %d -d %d", txID, c.session.GetFrameDepth())
+	if opts.Page > 0 {
+		command += fmt.Sprintf(" -p %d", opts.Page)
+	}
+	command += " -n " + name
 	c.session.AddCommand(strconv.Itoa(txID), "property_get")
 
-	err := c.conn.SendMessage(command)
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
+}
+
+// GetPropertyValue retrieves the complete value of a property in the selected
+// frame, without the max_data limit property_get applies
+func (c *Client) GetPropertyValue(name string) (string, error) {
+	txID := c.session.NextTransactionIDInt()
+	command := fmt.Sprintf("property_value -i %d -d %d -m 0 -n %s", txID, c.session.GetFrameDepth(), name)
+	c.session.AddCommand(strconv.Itoa(txID), "property_value")
+
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if response.HasError() {
+		return "", fmt.Errorf("property_value failed: %s", response.GetErrorMessage())
 	}
 
-	return c.conn.GetResponse()
+	return response.DecodedValue()
 }
 
-// SetProperty sets a variable value
+// SetProperty sets a variable value in the selected frame
 func (c *Client) SetProperty(name, value, dataType string) (*ProtocolResponse, error) {
 	txID := c.session.NextTransactionIDInt()
 
@@ -421,30 +610,20 @@ func (c *Client) SetProperty(name, value, dataType string) (*ProtocolResponse, e
 	encodedValue := base64.StdEncoding.EncodeToString([]byte(value))
 	dataLength := len(encodedValue)
 
-	command := fmt.Sprintf("property_set -i %d -n %s -t %s -l %d -- %s",
-		txID, name, dataType, dataLength, encodedValue)
+	command := fmt.Sprintf("property_set -i %d -d %d -n %s -t %s -l %d -- %s",
+		txID, c.session.GetFrameDepth(), name, dataType, dataLength, encodedValue)
 	c.session.AddCommand(strconv.Itoa(txID), "property_set")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
-// GetContext retrieves all variables in a specific context
+// GetContext retrieves all variables in a specific context of the selected frame
 func (c *Client) GetContext(contextID int) (*ProtocolResponse, error) {
 	txID := c.session.NextTransactionIDInt()
-	command := fmt.Sprintf("context_get -i %d -d 0 -c %d", txID, contextID)
+	command := fmt.Sprintf("context_get -i %d -d %d -c %d", txID, c.session.GetFrameDepth(), contextID)
 	c.session.AddCommand(strconv.Itoa(txID), "context_get")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // GetContextNames retrieves the list of available contexts
@@ -453,12 +632,7 @@ func (c *Client) GetContextNames() (*ProtocolResponse, error) {
 	command := fmt.Sprintf("context_names -i %d", txID)
 	c.session.AddCommand(strconv.Itoa(txID), "context_names")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // Eval evaluates an expression
@@ -470,12 +644,7 @@ func (c *Client) Eval(expression string) (*ProtocolResponse, error) {
 	command := fmt.Sprintf("eval -i %d -- %s", txID, encoded)
 	c.session.AddCommand(strconv.Itoa(txID), "eval")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // GetStackDepth retrieves the current stack depth
@@ -484,12 +653,7 @@ func (c *Client) GetStackDepth() (*ProtocolResponse, error) {
 	command := fmt.Sprintf("stack_depth -i %d", txID)
 	c.session.AddCommand(strconv.Itoa(txID), "stack_depth")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // GetStackTrace retrieves the call stack
@@ -498,12 +662,41 @@ func (c *Client) GetStackTrace() (*ProtocolResponse, error) {
 	command := fmt.Sprintf("stack_get -i %d", txID)
 	c.session.AddCommand(strconv.Itoa(txID), "stack_get")
 
-	err := c.conn.SendMessage(command)
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.conn.GetResponse()
+	// Stack frames report local paths
+	for i := range response.Stack {
+		response.Stack[i].pathMapper = c.pathMapper
+	}
+
+	return response, nil
+}
+
+// GetExecutableLines returns the lines Xdebug can break on in the function
+// of the given stack frame (0 = innermost), using xcmd_get_executable_lines
+func (c *Client) GetExecutableLines(depth int) ([]int, error) {
+	txID := c.session.NextTransactionIDInt()
+	command := fmt.Sprintf("xcmd_get_executable_lines -i %d -d %d", txID, depth)
+	c.session.AddCommand(strconv.Itoa(txID), "xcmd_get_executable_lines")
+
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
+	if err != nil {
+		return nil, err
+	}
+	if response.HasError() {
+		return nil, fmt.Errorf("xcmd_get_executable_lines failed: %s", response.GetErrorMessage())
+	}
+
+	lines := make([]int, 0, len(response.Lines))
+	for _, l := range response.Lines {
+		if line, err := strconv.Atoi(l.Lineno); err == nil {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
 }
 
 // GetSource retrieves the source code of a file with optional line range
@@ -516,7 +709,7 @@ func (c *Client) GetSource(fileURI string, beginLine, endLine int) (*ProtocolRes
 
 	// Add optional file URI parameter
 	if fileURI != "" {
-		command += fmt.Sprintf(" -f %s", fileURI)
+		command += fmt.Sprintf(" -f %s", c.normalizeFileURI(fileURI))
 	}
 
 	// Add optional begin line parameter
@@ -531,12 +724,7 @@ func (c *Client) GetSource(fileURI string, beginLine, endLine int) (*ProtocolRes
 
 	c.session.AddCommand(strconv.Itoa(txID), "source")
 
-	err := c.conn.SendMessage(command)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.conn.GetResponse()
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
 }
 
 // GetSession returns the session object
@@ -550,12 +738,89 @@ func (c *Client) FeatureGet(featureName string) (*ProtocolResponse, error) {
 	command := fmt.Sprintf("feature_get -i %d -n %s", txID, featureName)
 	c.session.AddCommand(strconv.Itoa(txID), "feature_get")
 
-	err := c.conn.SendMessage(command)
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
+}
+
+// FeatureSet changes an Xdebug feature/setting such as max_children,
+// max_data or max_depth for the rest of the session
+func (c *Client) FeatureSet(featureName, value string) error {
+	txID := c.session.NextTransactionIDInt()
+	command := fmt.Sprintf("feature_set -i %d -n %s -v %s", txID, featureName, value)
+	c.session.AddCommand(strconv.Itoa(txID), "feature_set")
+
+	response, err := c.conn.SendCommand(strconv.Itoa(txID), command)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if response.HasError() {
+		return fmt.Errorf("feature_set %s failed: %s", featureName, response.GetErrorMessage())
 	}
+	if response.Success == "0" {
+		return fmt.Errorf("feature_set %s failed: value %q not accepted", featureName, value)
+	}
+
+	return nil
+}
 
-	return c.conn.GetResponse()
+// Stdout sets how the script's stdout is handled: 0 = disable capture,
+// 1 = copy to the debugger, 2 = redirect to the debugger only
+func (c *Client) Stdout(mode int) (*ProtocolResponse, error) {
+	return c.redirectStream("stdout", mode)
+}
+
+// Stderr sets how the script's stderr is handled (same modes as Stdout).
+// Not every Xdebug version implements stderr capture.
+func (c *Client) Stderr(mode int) (*ProtocolResponse, error) {
+	return c.redirectStream("stderr", mode)
+}
+
+// redirectStream sends a stdout/stderr command
+func (c *Client) redirectStream(stream string, mode int) (*ProtocolResponse, error) {
+	if mode < 0 || mode > 2 {
+		return nil, fmt.Errorf("invalid %s mode %d (expected 0, 1 or 2)", stream, mode)
+	}
+
+	txID := c.session.NextTransactionIDInt()
+	command := fmt.Sprintf("%s -i %d -c %d", stream, txID, mode)
+	c.session.AddCommand(strconv.Itoa(txID), stream)
+
+	return c.conn.SendCommand(strconv.Itoa(txID), command)
+}
+
+// handleAsyncPacket stores stream and notify packets in the session buffers.
+// Late responses to abandoned commands still update the session state.
+func (c *Client) handleAsyncPacket(packet interface{}) {
+	switch p := packet.(type) {
+	case *ProtocolResponse:
+		c.updateSessionFromResponse(p)
+
+	case *ProtocolStream:
+		data, err := p.Decoded()
+		if err != nil {
+			data = p.Content
+		}
+		c.session.AddOutput(p.Type, data)
+
+	case *ProtocolNotify:
+		notification := Notification{Name: p.Name}
+		if p.Breakpoint != nil {
+			notification.BreakpointID = p.Breakpoint.ID
+			notification.Filename = c.pathMapper.ToLocal(p.Breakpoint.Filename)
+			notification.Line, _ = strconv.Atoi(p.Breakpoint.Lineno)
+		}
+		if p.Location != nil {
+			notification.Filename = c.pathMapper.ToLocal(p.Location.Filename)
+			notification.Line, _ = strconv.Atoi(p.Location.Lineno)
+		}
+		if len(p.Properties) > 0 {
+			value, err := DecodePropertyValue(&p.Properties[0])
+			if err != nil {
+				value = p.Properties[0].Value
+			}
+			notification.Value = value
+		}
+		c.session.AddNotification(notification)
+	}
 }
 
 // XdebugConfigWarning represents a potential configuration issue
@@ -593,20 +858,13 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-// updateSessionFromResponse updates session state based on response
+// updateSessionFromResponse updates session state based on response. The
+// location and reason are recorded before the state changes, so anyone
+// seeing the new state also sees where the script stopped.
 func (c *Client) updateSessionFromResponse(response *ProtocolResponse) {
-	// Update state based on status
-	switch response.Status {
-	case "starting":
-		c.session.SetState(StateStarting)
-	case "running":
-		c.session.SetState(StateRunning)
-	case "break":
-		c.session.SetState(StateBreak)
-	case "stopping":
-		c.session.SetState(StateStopping)
-	case "stopped":
-		c.session.SetState(StateStopped)
+	if response.Status != "" {
+		c.session.SetReason(response.Reason)
+		c.session.SetException(exceptionFromResponse(response))
 	}
 
 	// Update current location if provided
@@ -623,6 +881,37 @@ func (c *Client) updateSessionFromResponse(response *ProtocolResponse) {
 			c.session.SetCurrentLocation(msg.Filename, line)
 		}
 	}
+
+	// Update state based on status
+	switch response.Status {
+	case "starting":
+		c.session.SetState(StateStarting)
+	case "running":
+		c.session.SetState(StateRunning)
+	case "break":
+		c.session.SetState(StateBreak)
+	case "stopping":
+		c.session.SetState(StateStopping)
+	case "stopped":
+		c.session.SetState(StateStopped)
+	}
+}
+
+// exceptionFromResponse returns the exception a break response reports, or
+// nil if the script did not stop on an exception
+func exceptionFromResponse(response *ProtocolResponse) *ExceptionInfo {
+	if response.Status != "break" || len(response.Message) == 0 || response.Message[0].Exception == "" {
+		return nil
+	}
+	msg := response.Message[0]
+	line, _ := strconv.Atoi(msg.Lineno)
+	return &ExceptionInfo{
+		Class:    msg.Exception,
+		Message:  strings.TrimSpace(msg.Text),
+		Code:     msg.Code,
+		Filename: msg.Filename,
+		Line:     line,
+	}
 }
 
 // DecodePropertyValue decodes a base64-encoded property value
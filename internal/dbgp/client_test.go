@@ -132,6 +132,35 @@ func TestClient_Run(t *testing.T) {
 	}
 }
 
+func TestClient_RunStopsOnException(t *testing.T) {
+	responseXML := `<response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" command="run" transaction_id="1" status="break" reason="ok"><xdebug:message filename="file:///app/Order.php" lineno="42" exception="RuntimeException" code="7"><![CDATA[Out of stock]]></xdebug:message></response>`
+	stepXML := `<response command="step_into" transaction_id="2" status="break" reason="ok"><xdebug:message filename="file:///app/Order.php" lineno="43"/></response>`
+
+	mockConn := newMockConn()
+	mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(responseXML), responseXML))
+	mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(stepXML), stepXML))
+	client := NewClient(NewConnection(mockConn))
+
+	if _, err := client.Run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exception := client.session.GetException()
+	if exception == nil {
+		t.Fatal("Expected the exception to be recorded")
+	}
+	if exception.Class != "RuntimeException" || exception.Message != "Out of stock" || exception.Code != "7" || exception.Line != 42 {
+		t.Errorf("Unexpected exception %+v", exception)
+	}
+
+	if _, err := client.Step(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.session.GetException() != nil {
+		t.Error("Expected the exception to be cleared after stepping")
+	}
+}
+
 func TestClient_Step(t *testing.T) {
 	responseXML := `<?xml version="1.0" encoding="iso-8859-1"?>
 <response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug"
@@ -349,12 +378,136 @@ func TestClient_SetBreakpointToCall(t *testing.T) {
 	}
 }
 
+func TestClient_FunctionAndConditionalBreakpoints(t *testing.T) {
+	responseXML := `<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="3"/>`
+
+	tests := []struct {
+		name string
+		set  func(client *Client) (*ProtocolResponse, error)
+		want string
+	}{
+		{
+			name: "call class method",
+			set:  func(client *Client) (*ProtocolResponse, error) { return client.SetBreakpointToCall("Order::save") },
+			want: "breakpoint_set -i 1 -t call -a Order -m save",
+		},
+		{
+			name: "return function",
+			set:  func(client *Client) (*ProtocolResponse, error) { return client.SetBreakpointToReturn("calculate") },
+			want: "breakpoint_set -i 1 -t return -m calculate",
+		},
+		{
+			name: "return class method",
+			set:  func(client *Client) (*ProtocolResponse, error) { return client.SetBreakpointToReturn("Cart::total") },
+			want: "breakpoint_set -i 1 -t return -a Cart -m total",
+		},
+		{
+			name: "conditional",
+			set:  func(client *Client) (*ProtocolResponse, error) { return client.SetConditionalBreakpoint("$x > 1") },
+			want: "breakpoint_set -i 1 -t conditional -- JHggPiAx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn := newMockConn()
+			mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(responseXML), responseXML))
+			client := NewClient(NewConnection(mockConn))
+
+			if _, err := tt.set(client); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if sent := mockConn.writeBuf.String(); !strings.Contains(sent, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, sent)
+			}
+		})
+	}
+}
+
+func TestClient_GetPropertyWithOptions(t *testing.T) {
+	responses := []string{
+		`<response command="feature_get" transaction_id="1" feature_name="max_depth" supported="1"><![CDATA[1]]></response>`,
+		`<response command="feature_set" transaction_id="2" feature="max_depth" success="1"/>`,
+		`<response command="property_get" transaction_id="3"><property name="rows" type="array" numchildren="70" page="2" pagesize="32"/></response>`,
+		`<response command="feature_set" transaction_id="4" feature="max_depth" success="1"/>`,
+	}
+	mockConn := newMockConn()
+	for _, xml := range responses {
+		mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(xml), xml))
+	}
+	client := NewClient(NewConnection(mockConn))
+
+	response, err := client.GetPropertyWithOptions("rows", PropertyOptions{Page: 2, MaxDepth: 4})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(response.Properties) != 1 || response.Properties[0].GetPage() != 2 {
+		t.Errorf("Expected page 2 of rows, got %+v", response.Properties)
+	}
+
+	sent := mockConn.writeBuf.String()
+	for _, want := range []string{
+		"feature_get -i 1 -n max_depth\x00",
+		"feature_set -i 2 -n max_depth -v 4\x00",
+		"property_get -i 3 -d 0 -p 2 -n rows\x00",
+		"feature_set -i 4 -n max_depth -v 1\x00",
+	} {
+		if !strings.Contains(sent, want) {
+			t.Errorf("Expected %q to be sent, got %q", want, sent)
+		}
+	}
+}
+
+func TestClient_GetPropertyValue(t *testing.T) {
+	responseXML := `<response command="property_value" transaction_id="1" size="11" encoding="base64"><![CDATA[aGVsbG8gd29ybGQ=]]></response>`
+	mockConn := newMockConn()
+	mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(responseXML), responseXML))
+	client := NewClient(NewConnection(mockConn))
+
+	value, err := client.GetPropertyValue("html")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value != "hello world" {
+		t.Errorf("Expected %q, got %q", "hello world", value)
+	}
+	if want := "property_value -i 1 -d 0 -m 0 -n html"; !strings.Contains(mockConn.writeBuf.String(), want) {
+		t.Errorf("Expected %q, got %q", want, mockConn.writeBuf.String())
+	}
+}
+
+func TestProtocolBreakpoint_GetExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression ProtocolExpression
+		want       string
+	}{
+		{"base64", ProtocolExpression{Encoding: "base64", Value: "JHggPiAx"}, "$x > 1"},
+		{"plain", ProtocolExpression{Value: "$x > 1"}, "$x > 1"},
+		{"plain text that is valid base64", ProtocolExpression{Value: "true"}, "true"},
+		{"invalid base64", ProtocolExpression{Encoding: "base64", Value: "$x > 1"}, "$x > 1"},
+		{"empty", ProtocolExpression{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp := &ProtocolBreakpoint{Expression: tt.expression}
+			if got := bp.GetExpression(); got != tt.want {
+				t.Errorf("GetExpression(%+v) = %q, want %q", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestClient_GetBreakpointList(t *testing.T) {
 	responseXML := `<?xml version="1.0" encoding="iso-8859-1"?>
 <response xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug"
           command="breakpoint_list"
           transaction_id="1">
     <breakpoint id="1" type="line" state="enabled" filename="file:///test.php" lineno="10"></breakpoint>
+    <breakpoint id="2" type="conditional" state="enabled" filename="file:///test.php" lineno="12"><expression encoding="base64"><![CDATA[JHggPiAx]]></expression></breakpoint>
 </response>`
 
 	mockConn := newMockConn()
@@ -373,8 +526,11 @@ func TestClient_GetBreakpointList(t *testing.T) {
 		t.Errorf("Expected command 'breakpoint_list', got '%s'", response.Command)
 	}
 
-	if len(response.Breakpoints) != 1 {
-		t.Errorf("Expected 1 breakpoint, got %d", len(response.Breakpoints))
+	if len(response.Breakpoints) != 2 {
+		t.Fatalf("Expected 2 breakpoints, got %d", len(response.Breakpoints))
+	}
+	if expression := response.Breakpoints[1].GetExpression(); expression != "$x > 1" {
+		t.Errorf("Expected expression '$x > 1', got '%s'", expression)
 	}
 }
 
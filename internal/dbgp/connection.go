@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,23 +24,65 @@ const (
 var (
 	// digitsOnlyRegex validates that the size field contains only digits
 	digitsOnlyRegex = regexp.MustCompile(`^\d+$`)
+
+	// transactionIDRegex extracts the transaction ID from a message that failed to parse
+	transactionIDRegex = regexp.MustCompile(`transaction_id="([^"]*)"`)
 )
 
-// Connection wraps a network connection and handles DBGp message framing
+// Reply is the outcome of a command sent with Send: the response carrying
+// the command's transaction ID, or the error that ended the wait
+type Reply struct {
+	Response *ProtocolResponse
+	Err      error
+}
+
+// Connection wraps a network connection and handles DBGp message framing.
+//
+// Commands sent with Send/SendCommand are answered by a reader goroutine that
+// routes each response to the caller waiting for its transaction ID, so
+// commands may be issued while another one (e.g. run) is still waiting.
+// Stream, notify and unmatched packets go to subscribers.
 type Connection struct {
 	conn   net.Conn
 	reader *bufio.Reader
+
+	// responseTimeout bounds how long SendCommand waits for a response
+	responseTimeout time.Duration
+
+	writeMu sync.Mutex
+
+	mu               sync.Mutex
+	pending          map[string]chan Reply
+	subscribers      map[int]func(packet interface{})
+	nextSubscriberID int
+	readerStarted    bool
+	readErr          error
+	wake             chan struct{}
+	closed           chan struct{}
+	closeOnce        sync.Once
 }
 
 // NewConnection creates a new DBGp connection wrapper
 func NewConnection(conn net.Conn) *Connection {
 	return &Connection{
-		conn:   conn,
-		reader: bufio.NewReader(conn),
+		conn:            conn,
+		reader:          bufio.NewReader(conn),
+		responseTimeout: DefaultMessageTimeout,
+		pending:         make(map[string]chan Reply),
+		subscribers:     make(map[int]func(packet interface{})),
+		wake:            make(chan struct{}, 1),
+		closed:          make(chan struct{}),
 	}
 }
 
-// ReadMessage reads a DBGp message with the format: size\0xml\0
+// SetResponseTimeout sets how long SendCommand waits for a response
+func (c *Connection) SetResponseTimeout(timeout time.Duration) {
+	c.responseTimeout = timeout
+}
+
+// ReadMessage reads a DBGp message with the format: size\0xml\0.
+// It reads directly from the connection and must not be used once commands
+// are sent with Send/SendCommand; it is meant for the init packet.
 func (c *Connection) ReadMessage() (string, error) {
 	return c.ReadMessageWithTimeout(DefaultMessageTimeout)
 }
@@ -58,6 +101,11 @@ func (c *Connection) ReadMessageWithTimeout(timeout time.Duration) (string, erro
 		_ = c.conn.SetReadDeadline(time.Time{})
 	}()
 
+	return c.readFrame()
+}
+
+// readFrame reads one size\0xml\0 frame without touching the read deadline
+func (c *Connection) readFrame() (string, error) {
 	// Read the size part (up to first null byte)
 	sizeBytes, err := c.reader.ReadBytes(0)
 	if err != nil {
@@ -118,6 +166,9 @@ func (c *Connection) ReadMessageWithTimeout(timeout time.Duration) (string, erro
 
 // SendMessage sends a DBGp command message with null terminator
 func (c *Connection) SendMessage(message string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
 	// Disable Nagle's algorithm for immediate delivery (critical for debugging)
 	if tcpConn, ok := c.conn.(*net.TCPConn); ok {
 		_ = tcpConn.SetNoDelay(true)
@@ -132,28 +183,216 @@ func (c *Connection) SendMessage(message string) error {
 	return nil
 }
 
-// GetResponse reads a message and parses it as a protocol response
-func (c *Connection) GetResponse() (*ProtocolResponse, error) {
-	xmlData, err := c.ReadMessage()
-	if err != nil {
+// Subscribe registers a handler for packets no command is waiting for:
+// stream and notify packets, and responses to abandoned commands. Handlers
+// run on the reader goroutine and must not block. The returned function
+// removes the handler.
+func (c *Connection) Subscribe(handler func(packet interface{})) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextSubscriberID
+	c.nextSubscriberID++
+	c.subscribers[id] = handler
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+// Send sends a command and returns a channel that receives the response with
+// the given transaction ID. The reader goroutine is started on first use.
+func (c *Connection) Send(transactionID, command string) (<-chan Reply, error) {
+	ch := make(chan Reply, 1)
+
+	c.mu.Lock()
+	if c.readErr != nil {
+		err := c.readErr
+		c.mu.Unlock()
 		return nil, err
 	}
+	if _, exists := c.pending[transactionID]; exists {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("transaction %s is already waiting for a response", transactionID)
+	}
+	c.pending[transactionID] = ch
+	if !c.readerStarted {
+		c.readerStarted = true
+		go c.readLoop()
+	}
+	c.mu.Unlock()
 
-	result, err := CreateProtocolFromXML(xmlData)
+	if err := c.SendMessage(command); err != nil {
+		c.Cancel(transactionID)
+		return nil, err
+	}
+
+	// Wake the reader if it is idle
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+
+	return ch, nil
+}
+
+// SendCommand sends a command and waits for the response with the given
+// transaction ID, up to the response timeout
+func (c *Connection) SendCommand(transactionID, command string) (*ProtocolResponse, error) {
+	ch, err := c.Send(transactionID, command)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, err
+	}
+
+	timer := time.NewTimer(c.responseTimeout)
+	defer timer.Stop()
+
+	select {
+	case reply := <-ch:
+		return reply.Response, reply.Err
+	case <-timer.C:
+		c.Cancel(transactionID)
+		return nil, fmt.Errorf("timeout after %s waiting for response to transaction %s", c.responseTimeout, transactionID)
+	}
+}
+
+// Cancel stops waiting for a transaction; a late response goes to subscribers
+func (c *Connection) Cancel(transactionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, transactionID)
+}
+
+// readLoop reads packets while commands are waiting for responses and
+// dispatches them. While nothing is pending (the script is paused) it idles
+// instead of blocking on the socket.
+func (c *Connection) readLoop() {
+	for {
+		c.mu.Lock()
+		waiting := len(c.pending) > 0
+		c.mu.Unlock()
+
+		if !waiting {
+			select {
+			case <-c.wake:
+				continue
+			case <-c.closed:
+				c.failPending(fmt.Errorf("use of closed network connection"))
+				return
+			}
+		}
+
+		xmlData, err := c.readFrame()
+		if err != nil {
+			c.failPending(err)
+			return
+		}
+
+		result, err := CreateProtocolFromXML(xmlData)
+		if err != nil {
+			c.deliverParseError(xmlData, fmt.Errorf("failed to parse response: %w", err))
+			continue
+		}
+
+		c.dispatch(result)
+	}
+}
+
+// dispatch routes a response to the command waiting for its transaction ID
+// and passes every other packet to subscribers
+func (c *Connection) dispatch(packet interface{}) {
+	if response, ok := packet.(*ProtocolResponse); ok {
+		c.mu.Lock()
+		ch, found := c.pending[response.TransactionID]
+		delete(c.pending, response.TransactionID)
+		c.mu.Unlock()
+
+		if found {
+			ch <- Reply{Response: response}
+			return
+		}
 	}
 
-	response, ok := result.(*ProtocolResponse)
-	if !ok {
-		return nil, fmt.Errorf("expected response, got %T", result)
+	c.publish(packet)
+}
+
+// publish passes a packet to every subscriber
+func (c *Connection) publish(packet interface{}) {
+	c.mu.Lock()
+	handlers := make([]func(packet interface{}), 0, len(c.subscribers))
+	for _, handler := range c.subscribers {
+		handlers = append(handlers, handler)
 	}
+	c.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(packet)
+	}
+}
+
+// deliverParseError reports an unparseable message to the command it answers.
+// If the transaction ID cannot be recovered and a single command is waiting,
+// that command gets the error.
+func (c *Connection) deliverParseError(xmlData string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return response, nil
+	transactionID := ""
+	if match := transactionIDRegex.FindStringSubmatch(xmlData); match != nil {
+		transactionID = match[1]
+	}
+	if _, found := c.pending[transactionID]; !found && len(c.pending) == 1 {
+		for id := range c.pending {
+			transactionID = id
+		}
+	}
+
+	if ch, found := c.pending[transactionID]; found {
+		delete(c.pending, transactionID)
+		ch <- Reply{Err: err}
+	}
+}
+
+// failPending ends every waiting command with err; later commands fail immediately
+func (c *Connection) failPending(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readErr = err
+	for id, ch := range c.pending {
+		delete(c.pending, id)
+		ch <- Reply{Err: err}
+	}
+}
+
+// GetResponse reads a message directly and parses it as a protocol response.
+// Stream and notify packets arriving before the response are passed to
+// subscribers and skipped. Like ReadMessage, it must not be mixed with Send.
+func (c *Connection) GetResponse() (*ProtocolResponse, error) {
+	for {
+		xmlData, err := c.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := CreateProtocolFromXML(xmlData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		switch packet := result.(type) {
+		case *ProtocolResponse:
+			return packet, nil
+		case *ProtocolStream, *ProtocolNotify:
+			c.publish(packet)
+		default:
+			return nil, fmt.Errorf("expected response, got %T", result)
+		}
+	}
 }
 
 // Close closes the underlying connection
 func (c *Connection) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
 	if c.conn != nil {
 		return c.conn.Close()
 	}
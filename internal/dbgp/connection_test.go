@@ -637,3 +637,176 @@ func TestConnection_ReadMessage_UsesDefaultTimeout(t *testing.T) {
 		t.Fatal("Expected SetReadDeadline to be called (timeout should be set)")
 	}
 }
+
+func TestConnection_GetResponse_SkipsAsyncPackets(t *testing.T) {
+	stream := `<?xml version="1.0" encoding="iso-8859-1"?>
+<stream xmlns="urn:debugger_protocol_v1" type="stdout" encoding="base64"><![CDATA[b3V0]]></stream>`
+	notify := `<?xml version="1.0" encoding="iso-8859-1"?>
+<notify xmlns="urn:debugger_protocol_v1" name="user"></notify>`
+	response := `<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="run" transaction_id="1" status="break" reason="ok"></response>`
+
+	mockConn := newMockConn()
+	for _, xml := range []string{stream, notify, response} {
+		mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(xml), xml))
+	}
+
+	conn := NewConnection(mockConn)
+	var packets []interface{}
+	conn.Subscribe(func(packet interface{}) {
+		packets = append(packets, packet)
+	})
+
+	resp, err := conn.GetResponse()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Command != "run" {
+		t.Errorf("Expected command 'run', got '%s'", resp.Command)
+	}
+
+	if len(packets) != 2 {
+		t.Fatalf("Expected 2 async packets, got %d", len(packets))
+	}
+	if _, ok := packets[0].(*ProtocolStream); !ok {
+		t.Errorf("Expected *ProtocolStream first, got %T", packets[0])
+	}
+	if _, ok := packets[1].(*ProtocolNotify); !ok {
+		t.Errorf("Expected *ProtocolNotify second, got %T", packets[1])
+	}
+}
+
+// frame formats an XML packet as Xdebug sends it
+func frame(xml string) []byte {
+	return []byte(fmt.Sprintf("%d\x00%s\x00", len(xml), xml))
+}
+
+// responseXML builds a minimal response for a command and transaction ID
+func responseXML(command, transactionID string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="%s" transaction_id="%s" status="break" reason="ok"></response>`, command, transactionID)
+}
+
+// readCommands reads null-terminated commands from the Xdebug side of a pipe
+// until it is closed, passing each one to the returned channel
+func readCommands(conn net.Conn) <-chan string {
+	commands := make(chan string, 10)
+	go func() {
+		defer close(commands)
+		var command []byte
+		b := make([]byte, 1)
+		for {
+			if _, err := conn.Read(b); err != nil {
+				return
+			}
+			if b[0] == 0 {
+				commands <- string(command)
+				command = nil
+				continue
+			}
+			command = append(command, b[0])
+		}
+	}()
+	return commands
+}
+
+func TestConnection_Send_RoutesByTransactionID(t *testing.T) {
+	xdebugSide, ideSide := net.Pipe()
+	defer xdebugSide.Close()
+
+	conn := NewConnection(ideSide)
+	defer conn.Close()
+	commands := readCommands(xdebugSide)
+
+	unsolicited := make(chan interface{}, 2)
+	conn.Subscribe(func(packet interface{}) {
+		unsolicited <- packet
+	})
+
+	// run is left waiting while break is sent, as when interrupting a running script
+	runReply, err := conn.Send("1", "run -i 1")
+	if err != nil {
+		t.Fatalf("Send(run) error = %v", err)
+	}
+	if got := <-commands; got != "run -i 1" {
+		t.Fatalf("Xdebug received %q, want 'run -i 1'", got)
+	}
+
+	breakDone := make(chan *ProtocolResponse, 1)
+	go func() {
+		response, err := conn.SendCommand("2", "break -i 2")
+		if err != nil {
+			t.Errorf("SendCommand(break) error = %v", err)
+		}
+		breakDone <- response
+	}()
+	if got := <-commands; got != "break -i 2" {
+		t.Fatalf("Xdebug received %q, want 'break -i 2'", got)
+	}
+
+	// A stray response and a notification arrive before the replies, and the
+	// replies arrive in the opposite order to the commands
+	go func() {
+		xdebugSide.Write(frame(responseXML("status", "99")))
+		xdebugSide.Write(frame(`<notify xmlns="urn:debugger_protocol_v1" name="user"></notify>`))
+		xdebugSide.Write(frame(responseXML("break", "2")))
+		xdebugSide.Write(frame(responseXML("run", "1")))
+	}()
+
+	if response := <-breakDone; response == nil || response.Command != "break" {
+		t.Errorf("break reply = %+v, want command 'break'", response)
+	}
+
+	select {
+	case reply := <-runReply:
+		if reply.Err != nil || reply.Response.Command != "run" {
+			t.Errorf("run reply = %+v, want command 'run'", reply)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for run reply")
+	}
+
+	for _, want := range []string{"*dbgp.ProtocolResponse", "*dbgp.ProtocolNotify"} {
+		select {
+		case packet := <-unsolicited:
+			if got := fmt.Sprintf("%T", packet); got != want {
+				t.Errorf("unsolicited packet = %s, want %s", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for unsolicited %s", want)
+		}
+	}
+}
+
+func TestConnection_SendCommand_Timeout(t *testing.T) {
+	xdebugSide, ideSide := net.Pipe()
+	defer xdebugSide.Close()
+
+	conn := NewConnection(ideSide)
+	defer conn.Close()
+	conn.SetResponseTimeout(50 * time.Millisecond)
+
+	readCommands(xdebugSide)
+
+	_, err := conn.SendCommand("1", "status -i 1")
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("SendCommand() error = %v, want timeout", err)
+	}
+}
+
+func TestConnection_SendCommand_ConnectionClosed(t *testing.T) {
+	mockConn := newMockConn()
+	conn := NewConnection(mockConn)
+
+	// The mock returns EOF as soon as its buffer is empty
+	_, err := conn.SendCommand("1", "status -i 1")
+	if err == nil || !strings.Contains(err.Error(), "EOF") {
+		t.Fatalf("SendCommand() error = %v, want EOF", err)
+	}
+
+	// The connection stays failed for later commands
+	mockConn.readBuf.Write(frame(responseXML("status", "2")))
+	if _, err := conn.SendCommand("2", "status -i 2"); err == nil {
+		t.Error("SendCommand() after EOF succeeded, want error")
+	}
+}
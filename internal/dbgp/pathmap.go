@@ -0,0 +1,153 @@
+package dbgp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathMapping maps a directory as Xdebug sees it to the same directory on the
+// local machine, e.g. /var/www/html inside a container to ~/src/app
+type PathMapping struct {
+	Remote string
+	Local  string
+}
+
+// ParsePathMapping parses a "remote=local" mapping as given on the command line.
+// A leading ~ in the local path is expanded to the user's home directory.
+func ParsePathMapping(spec string) (PathMapping, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return PathMapping{}, fmt.Errorf("invalid path mapping %q (expected remote=local)", spec)
+	}
+
+	remote := cleanMappingPath(strings.TrimSpace(parts[0]))
+	local := strings.TrimSpace(parts[1])
+	if local == "~" || strings.HasPrefix(local, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return PathMapping{}, fmt.Errorf("failed to expand ~ in path mapping: %w", err)
+		}
+		local = home + strings.TrimPrefix(local, "~")
+	}
+	local = cleanMappingPath(local)
+
+	if !strings.HasPrefix(remote, "/") || !strings.HasPrefix(local, "/") {
+		return PathMapping{}, fmt.Errorf("invalid path mapping %q (both paths must be absolute)", spec)
+	}
+
+	return PathMapping{Remote: remote, Local: local}, nil
+}
+
+// cleanMappingPath strips the file:// prefix and trailing slashes from a mapping path
+func cleanMappingPath(path string) string {
+	path = strings.TrimPrefix(path, "file://")
+	return filepath.Clean(path)
+}
+
+// PathMapper translates file paths between Xdebug (remote) and the local
+// checkout. Both plain paths and file:// URIs are accepted; the result keeps
+// the form of the input. A nil PathMapper leaves every path unchanged.
+type PathMapper struct {
+	mappings []PathMapping
+}
+
+// NewPathMapper creates a mapper for the given mappings. The most specific
+// (longest) matching prefix wins when mappings overlap.
+func NewPathMapper(mappings []PathMapping) *PathMapper {
+	return &PathMapper{mappings: mappings}
+}
+
+// ParsePathMappings parses a list of "remote=local" mappings into a mapper
+func ParsePathMappings(specs []string) (*PathMapper, error) {
+	mappings := make([]PathMapping, 0, len(specs))
+	for _, spec := range specs {
+		mapping, err := ParsePathMapping(spec)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	return NewPathMapper(mappings), nil
+}
+
+// Mappings returns the configured mappings
+func (m *PathMapper) Mappings() []PathMapping {
+	if m == nil {
+		return nil
+	}
+	return m.mappings
+}
+
+// ToRemote translates a local path to the path Xdebug uses
+func (m *PathMapper) ToRemote(path string) string {
+	if m == nil {
+		return path
+	}
+	return m.translate(path, func(p PathMapping) (string, string) { return p.Local, p.Remote })
+}
+
+// ToLocal translates a path reported by Xdebug to the local path. Paths that
+// are already local are returned unchanged, so the mapping can safely be
+// applied more than once.
+func (m *PathMapper) ToLocal(path string) string {
+	if m == nil {
+		return path
+	}
+	plain := strings.TrimPrefix(path, "file://")
+	local := m.longestMatch(plain, func(p PathMapping) string { return p.Local })
+	remote := m.longestMatch(plain, func(p PathMapping) string { return p.Remote })
+	if local >= remote {
+		return path
+	}
+	return m.translate(path, func(p PathMapping) (string, string) { return p.Remote, p.Local })
+}
+
+// longestMatch returns the length of the longest mapping directory containing path, or -1
+func (m *PathMapper) longestMatch(path string, dir func(PathMapping) string) int {
+	best := -1
+	for _, mapping := range m.mappings {
+		if d := dir(mapping); hasPathPrefix(path, d) && len(d) > best {
+			best = len(d)
+		}
+	}
+	return best
+}
+
+// translate replaces the longest matching "from" prefix with its "to" counterpart
+func (m *PathMapper) translate(path string, direction func(PathMapping) (string, string)) string {
+	isURI := strings.HasPrefix(path, "file://")
+	plain := strings.TrimPrefix(path, "file://")
+
+	best := -1
+	var bestFrom, bestTo string
+	for _, mapping := range m.mappings {
+		from, to := direction(mapping)
+		if hasPathPrefix(plain, from) && len(from) > best {
+			best = len(from)
+			bestFrom, bestTo = from, to
+		}
+	}
+	if best < 0 {
+		return path
+	}
+
+	rest := strings.TrimPrefix(plain, strings.TrimSuffix(bestFrom, "/"))
+	mapped := strings.TrimSuffix(bestTo, "/") + rest
+	if mapped == "" {
+		mapped = "/"
+	}
+	if isURI {
+		return "file://" + mapped
+	}
+	return mapped
+}
+
+// hasPathPrefix checks whether path is dir or inside dir
+func hasPathPrefix(path, dir string) bool {
+	if dir == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
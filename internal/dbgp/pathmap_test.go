@@ -0,0 +1,135 @@
+package dbgp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParsePathMapping(t *testing.T) {
+	home, _ := os.UserHomeDir()
+
+	tests := []struct {
+		spec    string
+		want    PathMapping
+		wantErr bool
+	}{
+		{spec: "/var/www/html=/home/dev/app", want: PathMapping{Remote: "/var/www/html", Local: "/home/dev/app"}},
+		{spec: "/var/www/html/=/home/dev/app/", want: PathMapping{Remote: "/var/www/html", Local: "/home/dev/app"}},
+		{spec: "file:///var/www=/src", want: PathMapping{Remote: "/var/www", Local: "/src"}},
+		{spec: "/var/www=~/src/app", want: PathMapping{Remote: "/var/www", Local: home + "/src/app"}},
+		{spec: "/var/www", wantErr: true},
+		{spec: "=/src", wantErr: true},
+		{spec: "var/www=/src", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParsePathMapping(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePathMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParsePathMapping() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathMapper_Translate(t *testing.T) {
+	mapper := NewPathMapper([]PathMapping{
+		{Remote: "/var/www/html", Local: "/home/dev/app"},
+		{Remote: "/var/www/html/vendor", Local: "/home/dev/vendor"},
+		{Remote: "/srv", Local: "/srv/checkout"},
+	})
+
+	tests := []struct {
+		name     string
+		toLocal  bool
+		path     string
+		expected string
+	}{
+		{"remote to local", true, "/var/www/html/index.php", "/home/dev/app/index.php"},
+		{"remote URI to local URI", true, "file:///var/www/html/index.php", "file:///home/dev/app/index.php"},
+		{"longest prefix wins", true, "/var/www/html/vendor/lib.php", "/home/dev/vendor/lib.php"},
+		{"partial directory name is not a match", true, "/var/www/html2/index.php", "/var/www/html2/index.php"},
+		{"unmapped path unchanged", true, "/opt/other.php", "/opt/other.php"},
+		{"already local path unchanged", true, "/srv/checkout/a.php", "/srv/checkout/a.php"},
+		{"nested remote mapped", true, "/srv/a.php", "/srv/checkout/a.php"},
+		{"local to remote", false, "/home/dev/app/src/Kernel.php", "/var/www/html/src/Kernel.php"},
+		{"local URI to remote URI", false, "file:///home/dev/vendor/lib.php", "file:///var/www/html/vendor/lib.php"},
+		{"nested local to remote", false, "/srv/checkout/a.php", "/srv/a.php"},
+		{"unmapped local unchanged", false, "/tmp/a.php", "/tmp/a.php"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if tt.toLocal {
+				got = mapper.ToLocal(tt.path)
+			} else {
+				got = mapper.ToRemote(tt.path)
+			}
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPathMapper_Nil(t *testing.T) {
+	var mapper *PathMapper
+	if got := mapper.ToLocal("/var/www/a.php"); got != "/var/www/a.php" {
+		t.Errorf("nil ToLocal() = %q", got)
+	}
+	if got := mapper.ToRemote("/home/a.php"); got != "/home/a.php" {
+		t.Errorf("nil ToRemote() = %q", got)
+	}
+	if mapper.Mappings() != nil {
+		t.Error("nil Mappings() should be nil")
+	}
+}
+
+func TestClient_PathMapping(t *testing.T) {
+	mapper := NewPathMapper([]PathMapping{{Remote: "/var/www/html", Local: "/home/dev/app"}})
+
+	t.Run("breakpoint uses remote path", func(t *testing.T) {
+		responseXML := `<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="breakpoint_set" transaction_id="1" id="1"/>`
+		mockConn := newMockConn()
+		mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(responseXML), responseXML))
+
+		client := NewClient(NewConnection(mockConn))
+		client.SetPathMapper(mapper)
+
+		if _, err := client.SetBreakpoint("/home/dev/app/src/Kernel.php", 12, ""); err != nil {
+			t.Fatalf("SetBreakpoint() error = %v", err)
+		}
+
+		want := "-f file:///var/www/html/src/Kernel.php -n 12"
+		if sent := mockConn.writeBuf.String(); !strings.Contains(sent, want) {
+			t.Errorf("Expected command to contain '%s', got '%s'", want, sent)
+		}
+	})
+
+	t.Run("stack frames report local path", func(t *testing.T) {
+		responseXML := `<?xml version="1.0" encoding="iso-8859-1"?>
+<response xmlns="urn:debugger_protocol_v1" command="stack_get" transaction_id="1">
+<stack where="main" level="0" type="file" filename="file:///var/www/html/index.php" lineno="3"/>
+</response>`
+		mockConn := newMockConn()
+		mockConn.readBuf.WriteString(fmt.Sprintf("%d\x00%s\x00", len(responseXML), responseXML))
+
+		client := NewClient(NewConnection(mockConn))
+		client.SetPathMapper(mapper)
+
+		response, err := client.GetStackTrace()
+		if err != nil {
+			t.Fatalf("GetStackTrace() error = %v", err)
+		}
+		if got := response.Stack[0].GetFilename(); got != "/home/dev/app/index.php" {
+			t.Errorf("GetFilename() = %q, want /home/dev/app/index.php", got)
+		}
+	})
+}
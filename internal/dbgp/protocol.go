@@ -1,7 +1,9 @@
 package dbgp
 
 import (
+	"encoding/base64"
 	"encoding/xml"
+	"fmt"
 	"strings"
 
 	"golang.org/x/net/html/charset"
@@ -42,6 +44,22 @@ type ProtocolResponse struct {
 	// feature_get response fields
 	FeatureName string `xml:"feature_name,attr"`
 	Supported   string `xml:"supported,attr"`
+	// feature_set response field
+	Success string `xml:"success,attr"`
+	// property_value response fields
+	Size     string `xml:"size,attr"`
+	Encoding string `xml:"encoding,attr"`
+	// stack_depth response field
+	Depth string `xml:"depth,attr"`
+	// breakpoint_set response field when resolved_breakpoints is enabled
+	Resolved string `xml:"resolved,attr"`
+	// xcmd_get_executable_lines response field
+	Lines []ProtocolLine `xml:"lines>line"`
+}
+
+// ProtocolLine is a line of the xcmd_get_executable_lines response
+type ProtocolLine struct {
+	Lineno string `xml:"lineno,attr"`
 }
 
 // ProtocolError represents an error in a response
@@ -71,18 +89,26 @@ type ProtocolProperty struct {
 
 // ProtocolBreakpoint represents a breakpoint in a response
 type ProtocolBreakpoint struct {
-	XMLName       xml.Name `xml:"breakpoint"`
-	ID            string   `xml:"id,attr"`
-	Type          string   `xml:"type,attr"`
-	State         string   `xml:"state,attr"`
-	Filename      string   `xml:"filename,attr"`
-	Lineno        string   `xml:"lineno,attr"`
-	Function      string   `xml:"function,attr"`
-	Exception     string   `xml:"exception,attr"`
-	HitValue      string   `xml:"hit_value,attr"`
-	HitCondition  string   `xml:"hit_condition,attr"`
-	HitCount      string   `xml:"hit_count,attr"`
-	Expression    string   `xml:"expression"`
+	XMLName      xml.Name           `xml:"breakpoint"`
+	ID           string             `xml:"id,attr"`
+	Type         string             `xml:"type,attr"`
+	State        string             `xml:"state,attr"`
+	Filename     string             `xml:"filename,attr"`
+	Lineno       string             `xml:"lineno,attr"`
+	Function     string             `xml:"function,attr"`
+	Class        string             `xml:"class,attr"`
+	Exception    string             `xml:"exception,attr"`
+	HitValue     string             `xml:"hit_value,attr"`
+	HitCondition string             `xml:"hit_condition,attr"`
+	HitCount     string             `xml:"hit_count,attr"`
+	Resolved     string             `xml:"resolved,attr"`
+	Expression   ProtocolExpression `xml:"expression"`
+}
+
+// ProtocolExpression is the condition expression of a breakpoint
+type ProtocolExpression struct {
+	Encoding string `xml:"encoding,attr"`
+	Value    string `xml:",chardata"`
 }
 
 // ProtocolContext represents a context (variable scope) in a response
@@ -97,6 +123,10 @@ type ProtocolMessage struct {
 	XMLName  xml.Name `xml:"message"`
 	Filename string   `xml:"filename,attr"`
 	Lineno   string   `xml:"lineno,attr"`
+	// Exception, Code and Text describe the exception of an exception break
+	Exception string `xml:"exception,attr"`
+	Code      string `xml:"code,attr"`
+	Text      string `xml:",chardata"`
 }
 
 // ProtocolStack represents a stack frame in a stack trace response
@@ -107,6 +137,46 @@ type ProtocolStack struct {
 	Type     string   `xml:"type,attr"`
 	Filename string   `xml:"filename,attr"`
 	Lineno   string   `xml:"lineno,attr"`
+
+	// pathMapper translates Filename to a local path in GetFilename
+	pathMapper *PathMapper
+}
+
+// ProtocolStream represents an asynchronous stdout/stderr packet sent while
+// output capture is enabled with the stdout/stderr commands
+type ProtocolStream struct {
+	XMLName  xml.Name `xml:"stream"`
+	Type     string   `xml:"type,attr"`
+	Encoding string   `xml:"encoding,attr"`
+	Content  string   `xml:",chardata"`
+}
+
+// Decoded returns the stream content, decoding base64 if needed
+func (s *ProtocolStream) Decoded() (string, error) {
+	if s.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s.Content))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode stream content: %w", err)
+		}
+		return string(decoded), nil
+	}
+	return s.Content, nil
+}
+
+// ProtocolNotify represents an asynchronous notification, e.g.
+// breakpoint_resolved or a user notification from xdebug_notify()
+type ProtocolNotify struct {
+	XMLName    xml.Name                `xml:"notify"`
+	Name       string                  `xml:"name,attr"`
+	Breakpoint *ProtocolBreakpoint     `xml:"breakpoint"`
+	Location   *ProtocolNotifyLocation `xml:"location"`
+	Properties []ProtocolProperty      `xml:"property"`
+}
+
+// ProtocolNotifyLocation is the xdebug:location element of a user notification
+type ProtocolNotifyLocation struct {
+	Filename string `xml:"filename,attr"`
+	Lineno   string `xml:"lineno,attr"`
 }
 
 // CreateProtocolFromXML parses XML data and returns appropriate protocol structure
@@ -125,6 +195,28 @@ func CreateProtocolFromXML(xmlData string) (interface{}, error) {
 		return &init, nil
 	}
 
+	// Asynchronous packets can arrive between responses
+	root := rootElementName(xmlData)
+	if root == "stream" {
+		var stream ProtocolStream
+		decoder := xml.NewDecoder(strings.NewReader(xmlData))
+		decoder.CharsetReader = charset.NewReaderLabel
+		if err := decoder.Decode(&stream); err != nil {
+			return nil, err
+		}
+		return &stream, nil
+	}
+
+	if root == "notify" {
+		var notify ProtocolNotify
+		decoder := xml.NewDecoder(strings.NewReader(xmlData))
+		decoder.CharsetReader = charset.NewReaderLabel
+		if err := decoder.Decode(&notify); err != nil {
+			return nil, err
+		}
+		return &notify, nil
+	}
+
 	// Try to parse as response message
 	if strings.Contains(xmlData, "<response ") {
 		var response ProtocolResponse
@@ -148,6 +240,39 @@ func CreateProtocolFromXML(xmlData string) (interface{}, error) {
 	return &response, nil
 }
 
+// rootElementName returns the name of the first element after the XML declaration
+func rootElementName(xmlData string) string {
+	rest := xmlData
+	if strings.HasPrefix(rest, "<?xml") {
+		end := strings.Index(rest, "?>")
+		if end == -1 {
+			return ""
+		}
+		rest = strings.TrimSpace(rest[end+2:])
+	}
+	if !strings.HasPrefix(rest, "<") {
+		return ""
+	}
+	end := strings.IndexAny(rest, " \t\r\n/>")
+	if end == -1 {
+		return ""
+	}
+	return rest[1:end]
+}
+
+// DecodedValue returns the character data of a property_value response,
+// decoding base64 if needed
+func (r *ProtocolResponse) DecodedValue() (string, error) {
+	if r.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(r.Source))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode property value: %w", err)
+		}
+		return string(decoded), nil
+	}
+	return r.Source, nil
+}
+
 // HasError checks if the response contains an error
 func (r *ProtocolResponse) HasError() bool {
 	return r.Error != nil && r.Error.Code != ""
@@ -302,3 +302,87 @@ func TestCreateProtocolFromXML_InvalidXML(t *testing.T) {
 		t.Error("Expected error for invalid XML, got nil")
 	}
 }
+
+func TestCreateProtocolFromXML_Stream(t *testing.T) {
+	xmlData := `<?xml version="1.0" encoding="iso-8859-1"?>
+<stream xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" type="stdout" encoding="base64"><![CDATA[SGVsbG8gPHJlc3BvbnNlIC8+]]></stream>`
+
+	result, err := CreateProtocolFromXML(xmlData)
+	if err != nil {
+		t.Fatalf("Failed to parse stream XML: %v", err)
+	}
+
+	stream, ok := result.(*ProtocolStream)
+	if !ok {
+		t.Fatalf("Expected *ProtocolStream, got %T", result)
+	}
+	if stream.Type != "stdout" {
+		t.Errorf("Expected type 'stdout', got '%s'", stream.Type)
+	}
+
+	data, err := stream.Decoded()
+	if err != nil {
+		t.Fatalf("Decoded() error = %v", err)
+	}
+	if data != "Hello <response />" {
+		t.Errorf("Expected decoded content 'Hello <response />', got '%s'", data)
+	}
+}
+
+func TestCreateProtocolFromXML_Notify(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		xml      string
+		check    func(t *testing.T, notify *ProtocolNotify)
+	}{
+		{
+			name:     "breakpoint_resolved",
+			wantName: "breakpoint_resolved",
+			xml: `<?xml version="1.0" encoding="iso-8859-1"?>
+<notify xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" name="breakpoint_resolved"><breakpoint type="line" resolved="resolved" filename="file:///app/index.php" lineno="12" state="enabled" hit_count="0" hit_value="0" id="3"/></notify>`,
+			check: func(t *testing.T, notify *ProtocolNotify) {
+				if notify.Breakpoint == nil {
+					t.Fatal("Expected breakpoint element")
+				}
+				if notify.Breakpoint.ID != "3" || notify.Breakpoint.Lineno != "12" || notify.Breakpoint.Resolved != "resolved" {
+					t.Errorf("Unexpected breakpoint %+v", notify.Breakpoint)
+				}
+			},
+		},
+		{
+			name:     "user notification",
+			wantName: "user",
+			xml: `<?xml version="1.0" encoding="iso-8859-1"?>
+<notify xmlns="urn:debugger_protocol_v1" xmlns:xdebug="https://xdebug.org/dbgp/xdebug" name="user"><xdebug:location filename="file:///app/index.php" lineno="7"/><property type="string" size="5" encoding="base64"><![CDATA[aGVsbG8=]]></property></notify>`,
+			check: func(t *testing.T, notify *ProtocolNotify) {
+				if notify.Location == nil || notify.Location.Lineno != "7" {
+					t.Errorf("Unexpected location %+v", notify.Location)
+				}
+				if len(notify.Properties) != 1 {
+					t.Fatalf("Expected 1 property, got %d", len(notify.Properties))
+				}
+				if value, _ := DecodePropertyValue(&notify.Properties[0]); value != "hello" {
+					t.Errorf("Expected value 'hello', got '%s'", value)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CreateProtocolFromXML(tt.xml)
+			if err != nil {
+				t.Fatalf("Failed to parse notify XML: %v", err)
+			}
+			notify, ok := result.(*ProtocolNotify)
+			if !ok {
+				t.Fatalf("Expected *ProtocolNotify, got %T", result)
+			}
+			if notify.Name != tt.wantName {
+				t.Errorf("Expected name '%s', got '%s'", tt.wantName, notify.Name)
+			}
+			tt.check(t, notify)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package dbgp
 
 import (
 	"sync"
+	"time"
 )
 
 // SessionStateType represents the current state of a debugging session
@@ -42,6 +43,55 @@ func (s SessionStateType) String() string {
 	}
 }
 
+const (
+	// MaxOutputBytes caps the captured program output kept per session
+	MaxOutputBytes = 1024 * 1024
+
+	// MaxNotifications caps the notifications kept per session
+	MaxNotifications = 1000
+)
+
+// OutputChunk is a piece of program output captured from a stream packet
+type OutputChunk struct {
+	Stream string // "stdout" or "stderr"
+	Data   string
+	Time   time.Time
+}
+
+// Notification is an asynchronous notify packet received from Xdebug
+type Notification struct {
+	Name         string // e.g. "breakpoint_resolved" or "user"
+	Filename     string
+	Line         int
+	BreakpointID string
+	Value        string // decoded value of a user notification
+	Time         time.Time
+}
+
+// ExceptionInfo describes the exception the script stopped on, as reported
+// by Xdebug in the break response
+type ExceptionInfo struct {
+	Class    string
+	Message  string
+	Code     string
+	Filename string
+	Line     int
+}
+
+// SessionEvent is a change of the session reported to its observer: a state
+// change with the location and reason at that point, or captured output
+type SessionEvent struct {
+	State    SessionStateType
+	Previous SessionStateType
+	Filename string
+	Line     int
+	Reason   string
+
+	// Stream and Data are set for program output
+	Stream string
+	Data   string
+}
+
 // CommandRecord represents a sent command and its transaction ID
 type CommandRecord struct {
 	TransactionID string
@@ -57,8 +107,17 @@ type Session struct {
 	targetFiles    []string
 	currentFile    string
 	currentLine    int
+	reason         string
+	exception      *ExceptionInfo
+	frameDepth     int
 	ideKey         string
 	appID          string
+	lastBreak      time.Time
+	output         []OutputChunk
+	outputBytes    int
+	notifications  []Notification
+	observer       func(SessionEvent)
+	stateChanged   chan struct{}
 }
 
 // NewSession creates a new debugging session
@@ -68,6 +127,7 @@ func NewSession() *Session {
 		transactionID: 0,
 		commands:      make([]CommandRecord, 0),
 		targetFiles:   make([]string, 0),
+		stateChanged:  make(chan struct{}),
 	}
 }
 
@@ -81,8 +141,89 @@ func (s *Session) GetState() SessionStateType {
 // SetState sets the current session state
 func (s *Session) SetState(state SessionStateType) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	previous := s.state
 	s.state = state
+	if state == StateBreak {
+		s.lastBreak = time.Now()
+	}
+	// Frames are renumbered once execution moves
+	if state == StateRunning {
+		s.frameDepth = 0
+	}
+	event := SessionEvent{State: state, Previous: previous, Filename: s.currentFile, Line: s.currentLine, Reason: s.reason}
+	observer := s.observer
+	s.notifyStateChanged()
+	s.mu.Unlock()
+
+	// Every break is reported, as steps may pass through no other state
+	if observer != nil && (state != previous || state == StateBreak) {
+		observer(event)
+	}
+}
+
+// StateChanged returns a channel that is closed the next time the state is
+// set. Take the channel before reading the state to not miss a change.
+func (s *Session) StateChanged() <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stateChanged
+}
+
+// notifyStateChanged wakes StateChanged waiters; s.mu must be held
+func (s *Session) notifyStateChanged() {
+	close(s.stateChanged)
+	s.stateChanged = make(chan struct{})
+}
+
+// WaitForState blocks until done reports true for the session state or the
+// timeout expires. It returns the last state seen and whether done was met.
+func (s *Session) WaitForState(timeout time.Duration, done func(SessionStateType) bool) (SessionStateType, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		changed := s.StateChanged()
+		state := s.GetState()
+		if done(state) {
+			return state, true
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return s.GetState(), false
+		}
+	}
+}
+
+// SetObserver installs a function that is called after each state change
+// and each chunk of captured output. It runs on the connection's goroutines
+// and must not block.
+func (s *Session) SetObserver(observer func(SessionEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observer = observer
+}
+
+// SetFrameDepth selects the stack frame variables are read from
+// (0 = innermost frame)
+func (s *Session) SetFrameDepth(depth int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frameDepth = depth
+}
+
+// GetFrameDepth returns the selected stack frame depth
+func (s *Session) GetFrameDepth() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.frameDepth
+}
+
+// LastBreakAt returns when the session last entered the break state.
+// The zero time means the session has not stopped yet.
+func (s *Session) LastBreakAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastBreak
 }
 
 // NextTransactionID generates and returns the next transaction ID
@@ -164,6 +305,40 @@ func (s *Session) GetCurrentLocation() (string, int) {
 	return s.currentFile, s.currentLine
 }
 
+// SetReason records the reason Xdebug gave for the current status
+// (ok, error, aborted or exception)
+func (s *Session) SetReason(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reason = reason
+}
+
+// GetReason returns the reason Xdebug gave for the current status
+func (s *Session) GetReason() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reason
+}
+
+// SetException records the exception the script stopped on, or nil when
+// the current stop is not caused by an exception
+func (s *Session) SetException(exception *ExceptionInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exception = exception
+}
+
+// GetException returns the exception the script stopped on, or nil
+func (s *Session) GetException() *ExceptionInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.exception == nil {
+		return nil
+	}
+	exception := *s.exception
+	return &exception
+}
+
 // SetIDEKey sets the IDE key from the init message
 func (s *Session) SetIDEKey(ideKey string) {
 	s.mu.Lock()
@@ -202,6 +377,80 @@ func (s *Session) Reset() {
 	s.targetFiles = make([]string, 0)
 	s.currentFile = ""
 	s.currentLine = 0
+	s.reason = ""
+	s.exception = nil
+	s.frameDepth = 0
 	s.ideKey = ""
 	s.appID = ""
+	s.lastBreak = time.Time{}
+	s.output = nil
+	s.outputBytes = 0
+	s.notifications = nil
+	s.notifyStateChanged()
+}
+
+// AddOutput buffers captured program output, dropping the oldest chunks
+// once MaxOutputBytes is exceeded
+func (s *Session) AddOutput(stream, data string) {
+	s.mu.Lock()
+	s.output = append(s.output, OutputChunk{Stream: stream, Data: data, Time: time.Now()})
+	s.outputBytes += len(data)
+	for s.outputBytes > MaxOutputBytes && len(s.output) > 1 {
+		s.outputBytes -= len(s.output[0].Data)
+		s.output = s.output[1:]
+	}
+	event := SessionEvent{State: s.state, Previous: s.state, Stream: stream, Data: data}
+	observer := s.observer
+	s.mu.Unlock()
+
+	if observer != nil {
+		observer(event)
+	}
+}
+
+// GetOutput returns the buffered program output in arrival order
+func (s *Session) GetOutput() []OutputChunk {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	output := make([]OutputChunk, len(s.output))
+	copy(output, s.output)
+	return output
+}
+
+// ClearOutput empties the output buffer
+func (s *Session) ClearOutput() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.output = nil
+	s.outputBytes = 0
+}
+
+// AddNotification buffers a notification, dropping the oldest once
+// MaxNotifications is exceeded
+func (s *Session) AddNotification(notification Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if notification.Time.IsZero() {
+		notification.Time = time.Now()
+	}
+	s.notifications = append(s.notifications, notification)
+	if len(s.notifications) > MaxNotifications {
+		s.notifications = s.notifications[len(s.notifications)-MaxNotifications:]
+	}
+}
+
+// GetNotifications returns the buffered notifications in arrival order
+func (s *Session) GetNotifications() []Notification {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	notifications := make([]Notification, len(s.notifications))
+	copy(notifications, s.notifications)
+	return notifications
+}
+
+// ClearNotifications empties the notification buffer
+func (s *Session) ClearNotifications() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = nil
 }
@@ -3,6 +3,7 @@ package dbgp
 import (
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewSession(t *testing.T) {
@@ -305,3 +306,71 @@ func TestSession_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Expected 10 commands, got %d", len(session.commands))
 	}
 }
+
+func TestSession_FrameDepthResetsWhenRunning(t *testing.T) {
+	session := NewSession()
+	session.SetState(StateBreak)
+	session.SetFrameDepth(2)
+
+	// Re-reading the break state (e.g. from a status response) keeps the selection
+	session.SetState(StateBreak)
+	if got := session.GetFrameDepth(); got != 2 {
+		t.Errorf("GetFrameDepth() after status = %d, want 2", got)
+	}
+
+	session.SetState(StateRunning)
+	if got := session.GetFrameDepth(); got != 0 {
+		t.Errorf("GetFrameDepth() after running = %d, want 0", got)
+	}
+}
+
+func TestSession_Observer(t *testing.T) {
+	session := NewSession()
+	var events []SessionEvent
+	session.SetObserver(func(event SessionEvent) {
+		events = append(events, event)
+	})
+
+	session.SetState(StateRunning)
+	session.SetState(StateRunning)
+	session.SetCurrentLocation("file:///app/index.php", 4)
+	session.SetReason("ok")
+	session.SetState(StateBreak)
+	session.SetState(StateBreak)
+	session.AddOutput("stdout", "hello")
+
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4: %+v", len(events), events)
+	}
+	if events[0].State != StateRunning || events[0].Previous != StateNone {
+		t.Errorf("first event = %+v, want running after none", events[0])
+	}
+	if want := (SessionEvent{State: StateBreak, Previous: StateRunning, Filename: "file:///app/index.php", Line: 4, Reason: "ok"}); events[1] != want {
+		t.Errorf("break event = %+v, want %+v", events[1], want)
+	}
+	// A step reports a break while already at a break
+	if events[2].State != StateBreak || events[2].Previous != StateBreak {
+		t.Errorf("repeated break event = %+v", events[2])
+	}
+	if events[3].Stream != "stdout" || events[3].Data != "hello" {
+		t.Errorf("output event = %+v", events[3])
+	}
+}
+
+func TestSession_WaitForState(t *testing.T) {
+	session := NewSession()
+	session.SetState(StateRunning)
+	stopped := func(state SessionStateType) bool { return state != StateRunning }
+
+	if state, ok := session.WaitForState(10*time.Millisecond, stopped); ok || state != StateRunning {
+		t.Errorf("WaitForState() while running = %v, %v, want running, false", state, ok)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		session.SetState(StateBreak)
+	}()
+	if state, ok := session.WaitForState(time.Second, stopped); !ok || state != StateBreak {
+		t.Errorf("WaitForState() = %v, %v, want break, true", state, ok)
+	}
+}
@@ -1,7 +1,9 @@
 package dbgp
 
 import (
+	"encoding/base64"
 	"strconv"
+	"strings"
 )
 
 // View adapter methods for ProtocolBreakpoint
@@ -44,6 +46,53 @@ func (b *ProtocolBreakpoint) GetFunction() string {
 	return b.Function
 }
 
+// GetClass returns the class of a Class::method call or return breakpoint
+func (b *ProtocolBreakpoint) GetClass() string {
+	return b.Class
+}
+
+// GetException returns the exception name of an exception breakpoint
+func (b *ProtocolBreakpoint) GetException() string {
+	return b.Exception
+}
+
+// GetExpression returns the breakpoint's condition expression, decoded if
+// Xdebug sent it base64-encoded. Text that fails to decode is returned as is.
+func (b *ProtocolBreakpoint) GetExpression() string {
+	expression := strings.TrimSpace(b.Expression.Value)
+	if b.Expression.Encoding != "base64" {
+		return expression
+	}
+	decoded, err := base64.StdEncoding.DecodeString(expression)
+	if err != nil {
+		return expression
+	}
+	return string(decoded)
+}
+
+// GetHitValue returns the hit count the breakpoint's hit condition compares against
+func (b *ProtocolBreakpoint) GetHitValue() int {
+	value, _ := strconv.Atoi(b.HitValue)
+	return value
+}
+
+// GetHitCondition returns the hit condition operator (>=, == or %)
+func (b *ProtocolBreakpoint) GetHitCondition() string {
+	return b.HitCondition
+}
+
+// GetHitCount returns how many times the breakpoint has been hit
+func (b *ProtocolBreakpoint) GetHitCount() int {
+	count, _ := strconv.Atoi(b.HitCount)
+	return count
+}
+
+// GetResolved returns whether Xdebug resolved the breakpoint to code
+// ("resolved" or "unresolved"), empty if the engine does not report it
+func (b *ProtocolBreakpoint) GetResolved() string {
+	return b.Resolved
+}
+
 // View adapter methods for ProtocolProperty
 // These methods allow ProtocolProperty to be used with the view package
 
@@ -100,6 +149,24 @@ func (p *ProtocolProperty) GetNumChildren() int {
 	return count
 }
 
+// GetSize returns the full length of a string value (0 if not reported)
+func (p *ProtocolProperty) GetSize() int {
+	size, _ := strconv.Atoi(p.Size)
+	return size
+}
+
+// GetPage returns the page of children included in the property
+func (p *ProtocolProperty) GetPage() int {
+	page, _ := strconv.Atoi(p.Page)
+	return page
+}
+
+// GetPageSize returns the number of children per page (0 if not reported)
+func (p *ProtocolProperty) GetPageSize() int {
+	pageSize, _ := strconv.Atoi(p.PageSize)
+	return pageSize
+}
+
 // View adapter methods for ProtocolStack
 // These methods allow ProtocolStack to be used with the view package
 
@@ -125,10 +192,10 @@ func (s *ProtocolStack) GetType() string {
 	return s.Type
 }
 
-// GetFilename returns the filename without file:// prefix
+// GetFilename returns the local filename without file:// prefix
 func (s *ProtocolStack) GetFilename() string {
 	// Strip file:// prefix if present
-	filename := s.Filename
+	filename := s.pathMapper.ToLocal(s.Filename)
 	if len(filename) > 7 && filename[:7] == "file://" {
 		return filename[7:]
 	}
@@ -2,7 +2,9 @@ package ipc
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"time"
 )
@@ -10,29 +12,62 @@ import (
 // DefaultRetryAttempts is the default number of connection retry attempts
 const DefaultRetryAttempts = 3
 
-// Client represents an IPC client that connects to a Unix domain socket
+// Client represents an IPC client that connects to the daemon's Unix domain
+// socket or TCP listener
 type Client struct {
-	socketPath string
+	network    string
+	socketPath string // socket path, or host:port for TCP
 	timeout    time.Duration
+	session    string
+	token      string
 }
 
 // NewClient creates a new IPC client
 func NewClient(socketPath string) *Client {
 	return &Client{
+		network:    "unix",
 		socketPath: socketPath,
 		timeout:    5 * time.Second, // Default 5 second timeout
 	}
 }
 
+// NewTCPClient creates an IPC client for a daemon's TCP listener at host:port.
+// The daemon requires its token, see SetToken.
+func NewTCPClient(addr string) *Client {
+	return &Client{
+		network:    "tcp",
+		socketPath: addr,
+		timeout:    5 * time.Second,
+	}
+}
+
+// Address returns the socket path or TCP address the client connects to
+func (c *Client) Address() string {
+	return c.socketPath
+}
+
 // SetTimeout sets the connection timeout
 func (c *Client) SetTimeout(timeout time.Duration) {
 	c.timeout = timeout
 }
 
+// SetSession targets commands at a specific debug session of the daemon
+func (c *Client) SetSession(session string) {
+	c.session = session
+}
+
+// SetToken sets the token the daemon requires with each request
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
 // Connect establishes a connection to the IPC server
 func (c *Client) Connect() (net.Conn, error) {
-	conn, err := net.DialTimeout("unix", c.socketPath, c.timeout)
+	conn, err := net.DialTimeout(c.network, c.socketPath, c.timeout)
 	if err != nil {
+		if c.network == "tcp" {
+			return nil, fmt.Errorf("failed to connect to %s: %w", c.socketPath, err)
+		}
 		return nil, fmt.Errorf("failed to connect to socket %s: %w", c.socketPath, err)
 	}
 	return conn, nil
@@ -67,124 +102,135 @@ func (c *Client) ConnectWithRetry(maxAttempts int) (net.Conn, error) {
 
 // SendCommands sends a batch of commands to the daemon and returns the response
 func (c *Client) SendCommands(commands []string, jsonOutput bool) (*CommandResponse, error) {
-	// Connect to server
 	conn, err := c.Connect()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	// Set read/write deadlines
-	deadline := time.Now().Add(c.timeout)
-	if err := conn.SetDeadline(deadline); err != nil {
-		return nil, fmt.Errorf("failed to set deadline: %w", err)
-	}
-
-	// Create request
-	req := NewExecuteCommandsRequest(commands, jsonOutput)
+	return c.roundTrip(conn, bufio.NewReader(conn), c.newExecuteCommandsRequest(commands, jsonOutput))
+}
 
-	// Serialize and send request
-	reqData, err := req.ToJSON()
+// SendCommandsWithRetry sends commands with connection retry logic
+func (c *Client) SendCommandsWithRetry(commands []string, jsonOutput bool, maxAttempts int) (*CommandResponse, error) {
+	conn, err := c.ConnectWithRetry(maxAttempts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to serialize request: %w", err)
+		return nil, err
 	}
+	defer conn.Close()
 
-	// Write request with newline delimiter
-	if _, err := conn.Write(append(reqData, '\n')); err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
+	return c.roundTrip(conn, bufio.NewReader(conn), c.newExecuteCommandsRequest(commands, jsonOutput))
+}
 
-	// Read response
-	reader := bufio.NewReader(conn)
-	respData, err := reader.ReadBytes('\n')
+// Kill sends a kill request to the daemon
+func (c *Client) Kill() (*CommandResponse, error) {
+	conn, err := c.Connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse response
-	var resp CommandResponse
-	if err := resp.FromJSON(respData); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, err
 	}
+	defer conn.Close()
 
-	return &resp, nil
+	return c.roundTrip(conn, bufio.NewReader(conn), NewKillRequest())
 }
 
-// SendCommandsWithRetry sends commands with connection retry logic
-func (c *Client) SendCommandsWithRetry(commands []string, jsonOutput bool, maxAttempts int) (*CommandResponse, error) {
-	// Connect to server with retry
-	conn, err := c.ConnectWithRetry(maxAttempts)
+// DaemonStatus asks the daemon for its session lifecycle state
+func (c *Client) DaemonStatus() (*CommandResponse, error) {
+	conn, err := c.Connect()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	// Set read/write deadlines
-	deadline := time.Now().Add(c.timeout)
-	if err := conn.SetDeadline(deadline); err != nil {
-		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	return c.roundTrip(conn, bufio.NewReader(conn), NewDaemonStatusRequest())
+}
+
+// Events subscribes to the daemon's events and calls handle with each event,
+// a line of JSON, until the daemon closes the connection or stop is closed
+// (nil is returned) or handle returns an error. stop may be nil. The client's
+// timeout applies to connecting only.
+func (c *Client) Events(stop <-chan struct{}, handle func(event []byte) error) error {
+	conn, err := c.Connect()
+	if err != nil {
+		return err
 	}
+	defer conn.Close()
 
-	// Create request
-	req := NewExecuteCommandsRequest(commands, jsonOutput)
+	// Closing the connection ends a read waiting for the next event
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-stop:
+			conn.Close()
+		case <-finished:
+		}
+	}()
 
-	// Serialize and send request
+	req := NewEventsRequest()
+	req.Token = c.token
 	reqData, err := req.ToJSON()
 	if err != nil {
-		return nil, fmt.Errorf("failed to serialize request: %w", err)
+		return fmt.Errorf("failed to serialize request: %w", err)
 	}
-
-	// Write request with newline delimiter
 	if _, err := conn.Write(append(reqData, '\n')); err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 
-	// Read response
 	reader := bufio.NewReader(conn)
-	respData, err := reader.ReadBytes('\n')
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+			}
+			return fmt.Errorf("failed to read event: %w", err)
+		}
 
-	// Parse response
-	var resp CommandResponse
-	if err := resp.FromJSON(respData); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
+		// A daemon that cannot stream events answers with an error response
+		var resp CommandResponse
+		if json.Unmarshal(line, &resp) == nil && !resp.Success && resp.Error != "" {
+			return fmt.Errorf("%s", resp.Error)
+		}
 
-	return &resp, nil
+		if err := handle(line); err != nil {
+			return err
+		}
+	}
 }
 
-// Kill sends a kill request to the daemon
-func (c *Client) Kill() (*CommandResponse, error) {
-	// Connect to server
-	conn, err := c.Connect()
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Close()
+// newExecuteCommandsRequest creates an execute request for the client's target session
+func (c *Client) newExecuteCommandsRequest(commands []string, jsonOutput bool) *CommandRequest {
+	req := NewExecuteCommandsRequest(commands, jsonOutput)
+	req.Session = c.session
+	return req
+}
 
-	// Set deadlines
+// roundTrip sends a single request over conn and reads the daemon's response
+func (c *Client) roundTrip(conn net.Conn, reader *bufio.Reader, req *CommandRequest) (*CommandResponse, error) {
+	// Set read/write deadlines
 	deadline := time.Now().Add(c.timeout)
 	if err := conn.SetDeadline(deadline); err != nil {
 		return nil, fmt.Errorf("failed to set deadline: %w", err)
 	}
 
-	// Create kill request
-	req := NewKillRequest()
-
-	// Serialize and send
+	// Serialize and send request
+	req.Token = c.token
 	reqData, err := req.ToJSON()
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize request: %w", err)
 	}
 
+	// Write request with newline delimiter
 	if _, err := conn.Write(append(reqData, '\n')); err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	// Read response
-	reader := bufio.NewReader(conn)
 	respData, err := reader.ReadBytes('\n')
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
@@ -199,6 +245,35 @@ func (c *Client) Kill() (*CommandResponse, error) {
 	return &resp, nil
 }
 
+// Conn is a connection to the daemon that stays open for several requests,
+// saving the connection setup for each command of an interactive session
+type Conn struct {
+	client *Client
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Open connects to the daemon with retry logic and keeps the connection open
+// for SendCommands calls until Close
+func (c *Client) Open(maxAttempts int) (*Conn, error) {
+	conn, err := c.ConnectWithRetry(maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{client: c, conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// SendCommands sends a batch of commands over the open connection. The
+// client's current timeout and target session apply.
+func (c *Conn) SendCommands(commands []string, jsonOutput bool) (*CommandResponse, error) {
+	return c.client.roundTrip(c.conn, c.reader, c.client.newExecuteCommandsRequest(commands, jsonOutput))
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
 // Ping checks if the daemon is responsive
 func (c *Client) Ping() error {
 	conn, err := c.Connect()
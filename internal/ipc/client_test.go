@@ -489,3 +489,48 @@ func containsStringHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestConn_SeveralRequests(t *testing.T) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-client-%d.sock", time.Now().UnixNano()))
+	defer os.Remove(socketPath)
+
+	handler := func(req *CommandRequest) *CommandResponse {
+		return NewSuccessResponse([]CommandResult{{Command: req.Commands[0], Success: true}})
+	}
+
+	server := NewServer(socketPath, handler)
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	go func() {
+		_ = server.Serve()
+	}()
+
+	conn, err := NewClient(socketPath).Open(1)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer conn.Close()
+
+	for _, command := range []string{"status", "step", "context local"} {
+		resp, err := conn.SendCommands([]string{command}, false)
+		if err != nil {
+			t.Fatalf("SendCommands(%q) error = %v", command, err)
+		}
+		if len(resp.Results) != 1 || resp.Results[0].Command != command {
+			t.Errorf("SendCommands(%q) results = %+v", command, resp.Results)
+		}
+	}
+
+	// An idle open connection does not hold up the shutdown
+	done := make(chan error, 1)
+	go func() { done <- server.Shutdown() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown() blocked on the open connection")
+	}
+}
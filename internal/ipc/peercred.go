@@ -0,0 +1,38 @@
+package ipc
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// checkPeer rejects Unix socket connections from processes of other users.
+// Peer credentials are only checked on Linux; elsewhere the socket
+// permissions and the daemon token still apply.
+func checkPeer(conn net.Conn) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil
+	}
+
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("failed to check peer credentials: %w", err)
+	}
+
+	var uid int
+	var known bool
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		uid, known, credErr = peerUID(int(fd))
+	}); err != nil {
+		return fmt.Errorf("failed to check peer credentials: %w", err)
+	}
+	if credErr != nil {
+		return fmt.Errorf("failed to check peer credentials: %w", credErr)
+	}
+	if known && uid != os.Getuid() {
+		return fmt.Errorf("unauthorized: connection from user %d", uid)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package ipc
+
+import "syscall"
+
+// peerUID returns the user ID of the process on the other end of a Unix socket
+func peerUID(fd int) (int, bool, error) {
+	cred, err := syscall.GetsockoptUcred(fd, syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	if err != nil {
+		return 0, false, err
+	}
+	return int(cred.Uid), true, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package ipc
+
+// peerUID reports that peer credentials are not available on this platform
+func peerUID(fd int) (int, bool, error) {
+	return 0, false, nil
+}
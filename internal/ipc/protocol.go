@@ -7,9 +7,11 @@ import (
 
 // CommandRequest represents a request to execute commands in the daemon
 type CommandRequest struct {
-	Type       string   `json:"type"`        // Request type (e.g., "execute_commands", "kill")
-	Commands   []string `json:"commands"`    // Commands to execute
-	JSONOutput bool     `json:"json_output"` // Whether to return JSON output
+	Type       string   `json:"type"`              // Request type (e.g., "execute_commands", "kill", "daemon_status", "events")
+	Commands   []string `json:"commands"`          // Commands to execute
+	JSONOutput bool     `json:"json_output"`       // Whether to return JSON output
+	Session    string   `json:"session,omitempty"` // Target session ID (default: most recently broken session)
+	Token      string   `json:"token,omitempty"`   // Daemon's token from the session registry
 }
 
 // CommandResponse represents the response from the daemon
@@ -82,6 +84,21 @@ func NewKillRequest() *CommandRequest {
 	}
 }
 
+// NewDaemonStatusRequest creates a new CommandRequest for querying the daemon's session state
+func NewDaemonStatusRequest() *CommandRequest {
+	return &CommandRequest{
+		Type: "daemon_status",
+	}
+}
+
+// NewEventsRequest creates a new CommandRequest that subscribes to the
+// daemon's events
+func NewEventsRequest() *CommandRequest {
+	return &CommandRequest{
+		Type: "events",
+	}
+}
+
 // NewSuccessResponse creates a successful CommandResponse
 func NewSuccessResponse(results []CommandResult) *CommandResponse {
 	return &CommandResponse{
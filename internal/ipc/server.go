@@ -3,6 +3,8 @@ package ipc
 import (
 	"bufio"
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -13,15 +15,27 @@ import (
 // RequestHandler is a function that processes IPC requests
 type RequestHandler func(*CommandRequest) *CommandResponse
 
-// Server represents an IPC server using Unix domain sockets
+// StreamHandler serves a request that keeps the connection open. It writes
+// values to the client with send, one JSON line each, until it returns;
+// done is closed when the client goes away or the server shuts down.
+type StreamHandler func(req *CommandRequest, send func(v interface{}) error, done <-chan struct{})
+
+// Server represents an IPC server using Unix domain sockets, and
+// optionally a TCP listener for clients in other containers or hosts
 type Server struct {
-	socketPath string
-	listener   net.Listener
-	handler    RequestHandler
-	mu         sync.Mutex
-	ctx        context.Context
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
+	socketPath  string
+	listener    net.Listener
+	tcpListener net.Listener
+	handler     RequestHandler
+	streams     map[string]StreamHandler
+	token       string
+	tcpToken    string
+	mu          sync.Mutex
+	conns       map[net.Conn]struct{}
+	connsMu     sync.Mutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
 }
 
 // NewServer creates a new IPC server
@@ -30,11 +44,25 @@ func NewServer(socketPath string, handler RequestHandler) *Server {
 	return &Server{
 		socketPath: socketPath,
 		handler:    handler,
+		streams:    make(map[string]StreamHandler),
+		conns:      make(map[net.Conn]struct{}),
 		ctx:        ctx,
 		cancel:     cancel,
 	}
 }
 
+// HandleStream serves requests of the given type with a stream handler
+// instead of the request handler. Register handlers before Serve.
+func (s *Server) HandleStream(requestType string, handler StreamHandler) {
+	s.streams[requestType] = handler
+}
+
+// SetToken makes the server reject requests on the Unix socket that do not
+// carry the token
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
 // Listen starts the IPC server and binds to the Unix socket
 func (s *Server) Listen() error {
 	s.mu.Lock()
@@ -65,12 +93,63 @@ func (s *Server) Listen() error {
 	return nil
 }
 
-// Serve accepts and handles incoming connections
+// ListenTCP additionally listens for requests on a TCP address, which must
+// carry the given token. Requests over TCP are only protected by the token,
+// so it must not be empty.
+func (s *Server) ListenTCP(addr, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token == "" {
+		return fmt.Errorf("a token is required to listen on TCP")
+	}
+	if s.tcpListener != nil {
+		return fmt.Errorf("server already listening on TCP")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.tcpListener = listener
+	s.tcpToken = token
+	return nil
+}
+
+// TCPAddr returns the address of the TCP listener, or "" without one
+func (s *Server) TCPAddr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tcpListener == nil {
+		return ""
+	}
+	return s.tcpListener.Addr().String()
+}
+
+// Serve accepts and handles incoming connections on the Unix socket and,
+// if ListenTCP was called, the TCP listener. It returns when either fails.
 func (s *Server) Serve() error {
 	if s.listener == nil {
 		return fmt.Errorf("server not listening, call Listen() first")
 	}
 
+	s.mu.Lock()
+	tcpListener, tcpToken := s.tcpListener, s.tcpToken
+	s.mu.Unlock()
+	if tcpListener == nil {
+		return s.accept(s.listener, s.token)
+	}
+
+	errs := make(chan error, 2)
+	go func() { errs <- s.accept(tcpListener, tcpToken) }()
+	go func() { errs <- s.accept(s.listener, s.token) }()
+	return <-errs
+}
+
+// accept handles the connections of one listener, whose requests must carry
+// token, until it is closed
+func (s *Server) accept(listener net.Listener, token string) error {
 	for {
 		select {
 		case <-s.ctx.Done():
@@ -78,7 +157,7 @@ func (s *Server) Serve() error {
 		default:
 		}
 
-		conn, err := s.listener.Accept()
+		conn, err := listener.Accept()
 		if err != nil {
 			// Check if server was shut down
 			select {
@@ -90,41 +169,110 @@ func (s *Server) Serve() error {
 		}
 
 		s.wg.Add(1)
-		go s.handleConnection(conn)
+		go s.handleConnection(conn, token)
 	}
 }
 
-// handleConnection processes a single client connection
-func (s *Server) handleConnection(conn net.Conn) {
+// handleConnection processes the requests of a client connection. A client
+// may send several requests over one connection, e.g. an interactive attach;
+// the connection is served until the client closes it. Requests must carry
+// token if it is set.
+func (s *Server) handleConnection(conn net.Conn, token string) {
 	defer s.wg.Done()
 	defer conn.Close()
 
+	if !s.track(conn) {
+		return
+	}
+	defer s.untrack(conn)
+
+	if err := checkPeer(conn); err != nil {
+		s.writeError(conn, err.Error())
+		return
+	}
+
 	reader := bufio.NewReader(conn)
+	for {
+		// Read request (JSON terminated by newline)
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err != io.EOF && s.ctx.Err() == nil {
+				s.writeError(conn, fmt.Sprintf("failed to read request: %v", err))
+			}
+			return
+		}
 
-	// Read request (JSON terminated by newline)
-	line, err := reader.ReadBytes('\n')
-	if err != nil {
-		if err != io.EOF {
-			s.writeError(conn, fmt.Sprintf("failed to read request: %v", err))
+		// Parse request
+		var req CommandRequest
+		if err := req.FromJSON(line); err != nil {
+			s.writeError(conn, fmt.Sprintf("invalid request: %v", err))
+			return
+		}
+
+		if token != "" && subtle.ConstantTimeCompare([]byte(req.Token), []byte(token)) != 1 {
+			s.writeError(conn, "unauthorized: invalid or missing daemon token")
+			return
+		}
+
+		if stream, ok := s.streams[req.Type]; ok {
+			s.serveStream(conn, reader, &req, stream)
+			return
+		}
+
+		// Handle request
+		resp := s.handler(&req)
+
+		// Send response
+		if err := s.writeResponse(conn, resp); err != nil {
+			// Can't send error response if write failed
+			return
 		}
-		return
 	}
+}
 
-	// Parse request
-	var req CommandRequest
-	if err := req.FromJSON(line); err != nil {
-		s.writeError(conn, fmt.Sprintf("invalid request: %v", err))
-		return
+// serveStream runs a stream handler on the connection. The client sends
+// nothing further, so a read returning marks the end of the stream.
+func (s *Server) serveStream(conn net.Conn, reader *bufio.Reader, req *CommandRequest, handler StreamHandler) {
+	done := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, reader)
+		close(done)
+	}()
+
+	var writeMu sync.Mutex
+	send := func(v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to serialize event: %w", err)
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if _, err := conn.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+		return nil
 	}
 
-	// Handle request
-	resp := s.handler(&req)
+	handler(req, send, done)
+}
 
-	// Send response
-	if err := s.writeResponse(conn, resp); err != nil {
-		// Can't send error response if write failed
-		return
+// track registers an open connection so Shutdown can close it. It returns
+// false if the server is already shutting down.
+func (s *Server) track(conn net.Conn) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	if s.ctx.Err() != nil {
+		return false
 	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack forgets a closed connection
+func (s *Server) untrack(conn net.Conn) {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	delete(s.conns, conn)
 }
 
 // writeResponse writes a CommandResponse to the connection
@@ -156,13 +304,29 @@ func (s *Server) Shutdown() error {
 	// Signal shutdown
 	s.cancel()
 
-	// Close listener
+	// Close listeners
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+	}
 	if s.listener != nil {
 		if err := s.listener.Close(); err != nil {
 			return fmt.Errorf("failed to close listener: %w", err)
 		}
 	}
 
+	// Stop reading from open connections so clients that keep them open
+	// between requests do not block the shutdown. A request that is being
+	// handled still gets its response.
+	s.connsMu.Lock()
+	for conn := range s.conns {
+		if reader, ok := conn.(interface{ CloseRead() error }); ok {
+			reader.CloseRead()
+		} else {
+			conn.Close()
+		}
+	}
+	s.connsMu.Unlock()
+
 	// Wait for active connections to finish
 	s.wg.Wait()
 
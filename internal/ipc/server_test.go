@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -254,3 +255,219 @@ func TestServer_SocketPath(t *testing.T) {
 		t.Errorf("SocketPath() = %s, want %s", server.SocketPath(), socketPath)
 	}
 }
+
+func TestServer_Stream(t *testing.T) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-stream-%d.sock", time.Now().UnixNano()))
+	defer os.Remove(socketPath)
+
+	server := NewServer(socketPath, func(req *CommandRequest) *CommandResponse {
+		return NewErrorResponse("not streamed")
+	})
+	stop := make(chan struct{})
+	server.HandleStream("events", func(req *CommandRequest, send func(v interface{}) error, done <-chan struct{}) {
+		for i := 1; i <= 3; i++ {
+			if err := send(map[string]int{"n": i}); err != nil {
+				return
+			}
+		}
+		select {
+		case <-stop:
+		case <-done:
+		}
+	})
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	go func() {
+		_ = server.Serve()
+	}()
+
+	var lines []string
+	errs := make(chan error, 1)
+	go func() {
+		errs <- NewClient(socketPath).Events(nil, func(event []byte) error {
+			lines = append(lines, strings.TrimSpace(string(event)))
+			if len(lines) == 3 {
+				close(stop)
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Fatalf("Events() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Events() did not return when the stream ended")
+	}
+	if want := []string{`{"n":1}`, `{"n":2}`, `{"n":3}`}; strings.Join(lines, " ") != strings.Join(want, " ") {
+		t.Errorf("events = %v, want %v", lines, want)
+	}
+
+	if err := server.Shutdown(); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestClient_EventsStop(t *testing.T) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-events-stop-%d.sock", time.Now().UnixNano()))
+	defer os.Remove(socketPath)
+
+	server := NewServer(socketPath, func(req *CommandRequest) *CommandResponse {
+		return NewErrorResponse("not streamed")
+	})
+	// A stream without events until the client goes away
+	server.HandleStream("events", func(req *CommandRequest, send func(v interface{}) error, done <-chan struct{}) {
+		<-done
+	})
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	go func() {
+		_ = server.Serve()
+	}()
+	defer server.Shutdown()
+
+	stop := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- NewClient(socketPath).Events(stop, func([]byte) error { return nil })
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("Events() after stop = %v, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Events() did not return after stop was closed")
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-token-%d.sock", time.Now().UnixNano()))
+	defer os.Remove(socketPath)
+
+	server := NewServer(socketPath, func(req *CommandRequest) *CommandResponse {
+		return NewSuccessResponse([]CommandResult{{Command: req.Commands[0], Success: true}})
+	})
+	server.SetToken("secret")
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Shutdown()
+	go func() {
+		_ = server.Serve()
+	}()
+
+	for _, token := range []string{"", "wrong"} {
+		client := NewClient(socketPath)
+		client.SetToken(token)
+		resp, err := client.SendCommands([]string{"status"}, false)
+		if err != nil {
+			t.Fatalf("SendCommands() with token %q error = %v", token, err)
+		}
+		if resp.Success || !strings.Contains(resp.Error, "unauthorized") {
+			t.Errorf("token %q: response = %+v, want unauthorized", token, resp)
+		}
+	}
+
+	client := NewClient(socketPath)
+	client.SetToken("secret")
+	resp, err := client.SendCommands([]string{"status"}, false)
+	if err != nil || !resp.Success {
+		t.Errorf("SendCommands() with the token = %+v, %v, want success", resp, err)
+	}
+
+	// Streams are authenticated too
+	client.SetToken("wrong")
+	if err := client.Events(nil, func([]byte) error { return nil }); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("Events() with a wrong token error = %v, want unauthorized", err)
+	}
+}
+
+func TestCheckPeer(t *testing.T) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-peer-%d.sock", time.Now().UnixNano()))
+	defer os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	client, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	defer conn.Close()
+
+	// The test process connects as its own user
+	if err := checkPeer(conn); err != nil {
+		t.Errorf("checkPeer() error = %v", err)
+	}
+}
+
+func TestServer_ListenTCP(t *testing.T) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-tcp-%d.sock", time.Now().UnixNano()))
+	defer os.Remove(socketPath)
+
+	server := NewServer(socketPath, func(req *CommandRequest) *CommandResponse {
+		return NewSuccessResponse([]CommandResult{{Command: req.Commands[0], Success: true}})
+	})
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	server.SetToken("socket-token")
+	if err := server.ListenTCP("127.0.0.1:0", ""); err == nil {
+		t.Fatal("ListenTCP() without a token should fail")
+	}
+	if err := server.ListenTCP("127.0.0.1:0", "secret"); err != nil {
+		t.Fatalf("ListenTCP() error = %v", err)
+	}
+	go func() {
+		_ = server.Serve()
+	}()
+
+	addr := server.TCPAddr()
+	client := NewTCPClient(addr)
+	client.SetToken("secret")
+	resp, err := client.SendCommands([]string{"status"}, false)
+	if err != nil || !resp.Success || resp.Results[0].Command != "status" {
+		t.Fatalf("SendCommands() over TCP = %+v, %v", resp, err)
+	}
+
+	for _, token := range []string{"", "socket-token"} {
+		client.SetToken(token)
+		if resp, err := client.SendCommands([]string{"status"}, false); err != nil || resp.Success {
+			t.Errorf("SendCommands() over TCP with token %q = %+v, %v, want unauthorized", token, resp, err)
+		}
+	}
+
+	// The Unix socket keeps working alongside with its own token
+	unixClient := NewClient(socketPath)
+	unixClient.SetToken("secret")
+	if resp, err := unixClient.SendCommands([]string{"step"}, false); err != nil || resp.Success {
+		t.Errorf("SendCommands() over the socket with the TCP token = %+v, %v, want unauthorized", resp, err)
+	}
+	unixClient.SetToken("socket-token")
+	if resp, err := unixClient.SendCommands([]string{"step"}, false); err != nil || !resp.Success {
+		t.Errorf("SendCommands() over the socket = %+v, %v", resp, err)
+	}
+
+	if err := server.Shutdown(); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := NewTCPClient(addr).Ping(); err == nil {
+		t.Error("the TCP listener should be closed after Shutdown()")
+	}
+}
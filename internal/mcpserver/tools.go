@@ -234,7 +234,7 @@ func (s *Server) handleDaemonIsAlive(ctx context.Context, _ *mcp.CallToolRequest
 func (s *Server) registerExecute() {
 	mcp.AddTool(s.server, &mcp.Tool{
 		Name:        "xdebug_execute",
-		Description: "Execute debug commands on a running daemon session. Commands: run, step, next, out, break, print, context, eval, list, stack, status, info, delete, clear, disable, enable, finish, detach, help.",
+		Description: "Execute debug commands on a running daemon session. Commands: run (run --async returns immediately), wait [--timeout N], step, next, out, break, tbreak, until, logpoint, logs, print (--page N, --depth N, --full), context, eval, list (list +, list -, list 120,160, list Class::method; JSON includes per-line current/breakpoint flags), stack, exception, frame, up, down, status, info, delete, clear, disable, enable, pause, finish, detach, sessions, session, output, notifications, feature, record, watch, watches, unwatch, help.",
 	}, s.handleExecute)
 }
 
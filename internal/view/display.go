@@ -17,7 +17,7 @@ func (v *View) ShowInfoBreakpoints(breakpoints []ProtocolBreakpoint) {
 	v.PrintLn("")
 	v.PrintLn("Breakpoints:")
 	v.PrintLn(strings.Repeat("-", 80))
-	v.PrintLn(fmt.Sprintf("%-4s %-12s %-8s %-40s %s", "ID", "Type", "State", "Location", "Function"))
+	v.PrintLn(fmt.Sprintf("%-4s %-12s %-8s %-12s %-40s %s", "ID", "Type", "State", "Hits", "Location", "Details"))
 	v.PrintLn(strings.Repeat("-", 80))
 
 	for _, bp := range breakpoints {
@@ -30,9 +30,9 @@ func (v *View) ShowInfoBreakpoints(breakpoints []ProtocolBreakpoint) {
 			}
 		}
 
-		function := bp.GetFunction()
-		if function == "" {
-			function = "-"
+		details := FormatBreakpointDetails(bp.GetFunction(), bp.GetClass(), bp.GetException(), bp.GetExpression())
+		if details == "" {
+			details = "-"
 		}
 
 		// Truncate long paths for readability
@@ -40,18 +40,51 @@ func (v *View) ShowInfoBreakpoints(breakpoints []ProtocolBreakpoint) {
 			location = "..." + location[len(location)-37:]
 		}
 
-		v.PrintLn(fmt.Sprintf("%-4s %-12s %-8s %-40s %s",
+		v.PrintLn(fmt.Sprintf("%-4s %-12s %-8s %-12s %-40s %s",
 			bp.GetID(),
 			bp.GetType(),
 			bp.GetState(),
+			FormatHits(bp.GetHitCount(), bp.GetHitValue(), bp.GetHitCondition()),
 			location,
-			function,
+			details,
 		))
 	}
 	v.PrintLn(strings.Repeat("-", 80))
 	v.PrintLn("")
 }
 
+// FormatBreakpointDetails formats the type-specific fields of a breakpoint:
+// the function (Class::method) of call/return breakpoints, the exception
+// name and the condition expression, e.g. "Order::save if $id > 3"
+func FormatBreakpointDetails(function, class, exception, expression string) string {
+	var parts []string
+	if function != "" {
+		if class != "" {
+			function = class + "::" + function
+		}
+		parts = append(parts, function)
+	}
+	if exception != "" {
+		parts = append(parts, exception)
+	}
+	if expression != "" {
+		parts = append(parts, "if "+expression)
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatHits formats a breakpoint's live hit count, followed by its hit
+// condition if it has one, e.g. "3 (>= 100)"
+func FormatHits(count, hitValue int, hitCondition string) string {
+	if hitValue <= 0 {
+		return fmt.Sprintf("%d", count)
+	}
+	if hitCondition == "" {
+		hitCondition = ">="
+	}
+	return fmt.Sprintf("%d (%s %d)", count, hitCondition, hitValue)
+}
+
 // PrintPropertyListWithDetails displays a list of properties (variables) with their values.
 // scope is the context name (e.g., "Local", "Global", "Constants").
 // properties is the list of variables to display.
@@ -196,10 +229,42 @@ func (v *View) PrintJSONPropertyWithDepth(prop JSONProperty, depth int) {
 // This is used by the attach command to display properties returned from the daemon
 func (v *View) PrintJSONProperty(prop JSONProperty) {
 	v.PrintLn("")
-	v.PrintJSONPropertyWithDepth(prop, 0)
+	if len(prop.Children) == 0 && prop.NumChildren == 0 && prop.Value != "" {
+		// A single value is shown in full, only nested values are abbreviated
+		v.PrintLn(fmt.Sprintf("%s (%s) = %s", prop.Name, prop.Type, TryDecodeBase64(prop.Value, prop.Type)))
+	} else {
+		v.PrintJSONPropertyWithDepth(prop, 0)
+	}
+	if hint := TruncationHint(prop); hint != "" {
+		v.PrintLn(hint)
+	}
 	v.PrintLn("")
 }
 
+// TruncationHint explains how to retrieve the rest of a truncated property,
+// or returns "" when the property is complete
+func TruncationHint(prop JSONProperty) string {
+	if !prop.Truncated {
+		return ""
+	}
+	switch {
+	case prop.Paging != nil:
+		return fmt.Sprintf("(showing page %d of 0-%d, %d children per page; use --page N for the others)",
+			prop.Paging.Page, prop.Paging.Pages-1, prop.Paging.PageSize)
+	case prop.Size > len(prop.Value):
+		return fmt.Sprintf("(value truncated to %d of %d bytes; use --full for the complete value)",
+			len(prop.Value), prop.Size)
+	case prop.NumChildren > len(prop.Children) && len(prop.Children) == 0:
+		return "(children not retrieved; use --depth N to expand nested values)"
+	}
+	for _, child := range prop.Children {
+		if child.Truncated {
+			return "(nested values truncated; use --depth N or print them individually)"
+		}
+	}
+	return ""
+}
+
 // ShowInfoStack displays a formatted stack trace.
 func (v *View) ShowInfoStack(frames []ProtocolStack) {
 	if len(frames) == 0 {
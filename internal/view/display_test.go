@@ -15,6 +15,12 @@ type mockBreakpoint struct {
 	filename string
 	line     int
 	function string
+	class    string
+	except   string
+	expr     string
+	hitValue int
+	hitCond  string
+	hitCount int
 }
 
 func (m *mockBreakpoint) GetID() string         { return m.id }
@@ -23,6 +29,13 @@ func (m *mockBreakpoint) GetState() string      { return m.state }
 func (m *mockBreakpoint) GetFilename() string   { return m.filename }
 func (m *mockBreakpoint) GetLineNumber() int    { return m.line }
 func (m *mockBreakpoint) GetFunction() string   { return m.function }
+func (m *mockBreakpoint) GetClass() string      { return m.class }
+func (m *mockBreakpoint) GetException() string  { return m.except }
+func (m *mockBreakpoint) GetExpression() string { return m.expr }
+func (m *mockBreakpoint) GetHitValue() int      { return m.hitValue }
+func (m *mockBreakpoint) GetHitCondition() string { return m.hitCond }
+func (m *mockBreakpoint) GetHitCount() int      { return m.hitCount }
+func (m *mockBreakpoint) GetResolved() string   { return "" }
 
 type mockProperty struct {
 	name        string
@@ -32,6 +45,9 @@ type mockProperty struct {
 	children    []interface{}
 	hasChildren bool
 	numChildren int
+	size        int
+	page        int
+	pageSize    int
 }
 
 func (m *mockProperty) GetName() string        { return m.name }
@@ -41,6 +57,9 @@ func (m *mockProperty) GetValue() string       { return m.value }
 func (m *mockProperty) GetChildren() []interface{} { return m.children }
 func (m *mockProperty) HasChildren() bool      { return m.hasChildren }
 func (m *mockProperty) GetNumChildren() int    { return m.numChildren }
+func (m *mockProperty) GetSize() int           { return m.size }
+func (m *mockProperty) GetPage() int           { return m.page }
+func (m *mockProperty) GetPageSize() int       { return m.pageSize }
 
 func TestView_ShowInfoBreakpoints(t *testing.T) {
 	tests := []struct {
@@ -102,6 +121,40 @@ func TestView_ShowInfoBreakpoints(t *testing.T) {
 				"myFunction",
 			},
 		},
+		{
+			name: "hit-count breakpoint",
+			breakpoints: []ProtocolBreakpoint{
+				&mockBreakpoint{
+					id:       "3",
+					bpType:   "line",
+					state:    "enabled",
+					filename: "/path/to/loop.php",
+					line:     7,
+					hitValue: 100,
+					hitCond:  ">=",
+					hitCount: 42,
+				},
+			},
+			wantOutput: []string{
+				"Hits",
+				"42 (>= 100)",
+				"loop.php:7",
+			},
+		},
+		{
+			name: "type-specific details",
+			breakpoints: []ProtocolBreakpoint{
+				&mockBreakpoint{id: "4", bpType: "return", state: "enabled", function: "save", class: "Order"},
+				&mockBreakpoint{id: "5", bpType: "exception", state: "enabled", except: "RuntimeException"},
+				&mockBreakpoint{id: "6", bpType: "conditional", state: "enabled", expr: "$total < 0"},
+			},
+			wantOutput: []string{
+				"Details",
+				"Order::save",
+				"RuntimeException",
+				"if $total < 0",
+			},
+		},
 		{
 			name: "long path truncation",
 			breakpoints: []ProtocolBreakpoint{
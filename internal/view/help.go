@@ -16,7 +16,7 @@ Available Commands:
   print, p        Print variable value (see 'help print' for details)
   property_get    Print variable (DBGp-style: property_get -n $var)
   context, c      Show variables in current context (see 'help context' for details)
-  list, l         Show source code around current line
+  list, l         Show source code around current line (list +, list -, list 120,160, list Class::method)
   info, i         Show debugging information (see 'help info' for details)
   breakpoint_list List breakpoints (DBGp-style)
   status, st      Show current execution status
@@ -90,6 +90,9 @@ Usage:
   break :<line>             Set breakpoint at line in current file (explicit form)
   break <file>:<line>       Set breakpoint at line in specific file
   break call <function>     Set breakpoint on function call
+  break call <Class>::<method>  Set breakpoint on method call
+  break return <function>   Set breakpoint on function return
+  break when <expr>         Set breakpoint on any line where expr is true
   break exception           Set breakpoint on exceptions
 
 Arguments:
@@ -102,6 +105,8 @@ Examples:
   xdebug-cli listen --commands "break :42"                   # Same as above
   xdebug-cli listen --commands "break /path/to/file.php:15"  # Break at line 15 in specific file
   xdebug-cli listen --commands "break call myFunction"       # Break when myFunction is called
+  xdebug-cli listen --commands "break return Order::save"    # Break when Order::save returns
+  xdebug-cli listen --commands "break when \$total < 0"      # Break once $total goes negative
   xdebug-cli listen --commands "break exception"             # Break on any exception
 
 Note: Breakpoints are set before execution starts or while paused.
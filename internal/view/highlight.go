@@ -0,0 +1,167 @@
+package view
+
+import "strings"
+
+// ANSI colors of PHP tokens in source listings
+const (
+	colorReset    = "\x1b[0m"
+	colorKeyword  = "\x1b[35m"
+	colorVariable = "\x1b[36m"
+	colorString   = "\x1b[32m"
+	colorNumber   = "\x1b[33m"
+	colorComment  = "\x1b[90m"
+)
+
+// phpKeywords are the reserved words highlighted as keywords, in lower case
+var phpKeywords = map[string]bool{
+	"abstract": true, "and": true, "array": true, "as": true, "break": true,
+	"callable": true, "case": true, "catch": true, "class": true, "clone": true,
+	"const": true, "continue": true, "declare": true, "default": true, "do": true,
+	"echo": true, "else": true, "elseif": true, "empty": true, "enddeclare": true,
+	"endfor": true, "endforeach": true, "endif": true, "endswitch": true,
+	"endwhile": true, "enum": true, "extends": true, "false": true, "final": true,
+	"finally": true, "fn": true, "for": true, "foreach": true, "function": true,
+	"global": true, "goto": true, "if": true, "implements": true, "include": true,
+	"include_once": true, "instanceof": true, "insteadof": true, "interface": true,
+	"isset": true, "list": true, "match": true, "namespace": true, "new": true,
+	"null": true, "or": true, "parent": true, "print": true, "private": true,
+	"protected": true, "public": true, "readonly": true, "require": true,
+	"require_once": true, "return": true, "self": true, "static": true,
+	"switch": true, "throw": true, "trait": true, "true": true, "try": true,
+	"unset": true, "use": true, "var": true, "while": true, "xor": true,
+	"yield": true,
+}
+
+// phpHighlighter colors PHP source line by line. It carries block comments
+// and strings that span lines over to the next line.
+type phpHighlighter struct {
+	inComment bool
+	quote     byte // quote of a string continued from the previous line
+}
+
+// line returns s with ANSI colors for keywords, variables, strings, numbers
+// and comments
+func (h *phpHighlighter) line(s string) string {
+	var b strings.Builder
+	colored := func(color, text string) {
+		b.WriteString(color + text + colorReset)
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case h.inComment:
+			end := strings.Index(s[i:], "*/")
+			if end < 0 {
+				colored(colorComment, s[i:])
+				return b.String()
+			}
+			colored(colorComment, s[i:i+end+2])
+			i += end + 2
+			h.inComment = false
+
+		case h.quote != 0:
+			end := closingQuote(s, i, h.quote)
+			if end < 0 {
+				colored(colorString, s[i:])
+				return b.String()
+			}
+			colored(colorString, s[i:end])
+			i = end
+			h.quote = 0
+
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				h.inComment = true
+				colored(colorComment, s[i:])
+				return b.String()
+			}
+			colored(colorComment, s[i:i+2+end+2])
+			i += 2 + end + 2
+
+		case strings.HasPrefix(s[i:], "//") || (s[i] == '#' && !strings.HasPrefix(s[i:], "#[")):
+			colored(colorComment, s[i:])
+			return b.String()
+
+		case s[i] == '\'' || s[i] == '"':
+			end := closingQuote(s, i+1, s[i])
+			if end < 0 {
+				h.quote = s[i]
+				colored(colorString, s[i:])
+				return b.String()
+			}
+			colored(colorString, s[i:end])
+			i = end
+
+		case strings.HasPrefix(s[i:], "<?php"):
+			colored(colorKeyword, "<?php")
+			i += len("<?php")
+
+		case s[i] == '$' && i+1 < len(s) && isIdentStart(s[i+1]):
+			end := identEnd(s, i+1)
+			colored(colorVariable, s[i:end])
+			i = end
+
+		case isDigit(s[i]):
+			end := i + 1
+			for end < len(s) && (isIdentChar(s[end]) || s[end] == '.') {
+				end++
+			}
+			colored(colorNumber, s[i:end])
+			i = end
+
+		case isIdentStart(s[i]):
+			end := identEnd(s, i)
+			if word := s[i:end]; phpKeywords[strings.ToLower(word)] {
+				colored(colorKeyword, word)
+			} else {
+				b.WriteString(word)
+			}
+			i = end
+
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// closingQuote returns the index after the quote that ends a string whose
+// contents start at from, or -1 if the string continues past the line
+func closingQuote(s string, from int, quote byte) int {
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// identEnd returns the index after the identifier starting at start
+func identEnd(s string, start int) int {
+	end := start
+	for end < len(s) && isIdentChar(s[end]) {
+		end++
+	}
+	return end
+}
+
+// isIdentStart reports whether c can start a PHP identifier; bytes of
+// multi-byte UTF-8 characters count as letters
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// isIdentChar reports whether c can continue a PHP identifier
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
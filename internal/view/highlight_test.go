@@ -0,0 +1,44 @@
+package view
+
+import "testing"
+
+func TestPHPHighlighter(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "keyword, variable, number and comment",
+			lines: []string{`return $total + 1; // done`},
+			want: []string{colorKeyword + "return" + colorReset + " " + colorVariable + "$total" + colorReset + " + " +
+				colorNumber + "1" + colorReset + "; " + colorComment + "// done" + colorReset},
+		},
+		{
+			name:  "strings with escapes and comment markers",
+			lines: []string{`echo 'it\'s', "a # b";`},
+			want: []string{colorKeyword + "echo" + colorReset + " " + colorString + `'it\'s'` + colorReset + ", " +
+				colorString + `"a # b"` + colorReset + ";"},
+		},
+		{
+			name:  "identifiers",
+			lines: []string{"Foo::bar()"},
+			want:  []string{"Foo::bar()"},
+		},
+		{
+			name:  "block comment continues on the next line",
+			lines: []string{"/* start", "end */ if"},
+			want:  []string{colorComment + "/* start" + colorReset, colorComment + "end */" + colorReset + " " + colorKeyword + "if" + colorReset},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h phpHighlighter
+			for i, line := range tt.lines {
+				if got := h.line(line); got != tt.want[i] {
+					t.Errorf("line(%q) = %q, want %q", line, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
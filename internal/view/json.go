@@ -21,6 +21,17 @@ type JSONProperty struct {
 	Value       string         `json:"value"`
 	NumChildren int            `json:"num_children"`
 	Children    []JSONProperty `json:"children,omitempty"`
+	// Truncated is set when Value or Children are incomplete
+	Truncated bool        `json:"truncated,omitempty"`
+	Size      int         `json:"size,omitempty"`
+	Paging    *JSONPaging `json:"paging,omitempty"`
+}
+
+// JSONPaging describes which page of a property's children was returned
+type JSONPaging struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Pages    int `json:"pages"`
 }
 
 // JSONBreakpoint represents a breakpoint in JSON format
@@ -31,6 +42,20 @@ type JSONBreakpoint struct {
 	Filename string `json:"filename,omitempty"`
 	Line     int    `json:"line,omitempty"`
 	Function string `json:"function,omitempty"`
+	// Type-specific fields of call/return, exception and conditional breakpoints
+	Class      string `json:"class,omitempty"`
+	Exception  string `json:"exception,omitempty"`
+	Expression string `json:"expression,omitempty"`
+	// HitValue and HitCondition are set for hit-count breakpoints
+	HitValue     int    `json:"hit_value,omitempty"`
+	HitCondition string `json:"hit_condition,omitempty"`
+	HitCount     int    `json:"hit_count"`
+	// Log is the message of a logpoint
+	Log string `json:"log,omitempty"`
+	// Resolved is "resolved" once Xdebug has matched the breakpoint to code;
+	// RequestedLine is set when that moved it away from the requested line
+	Resolved      string `json:"resolved,omitempty"`
+	RequestedLine int    `json:"requested_line,omitempty"`
 }
 
 // JSONStack represents a stack frame in JSON format
@@ -39,6 +64,7 @@ type JSONStack struct {
 	Where    string `json:"where"`
 	Filename string `json:"filename"`
 	Line     int    `json:"line"`
+	Selected bool   `json:"selected,omitempty"`
 }
 
 // JSONStateResult represents the result of a state-changing command (run, step, next)
@@ -53,7 +79,23 @@ type JSONBreakpointResult struct {
 	ID        string `json:"id,omitempty"`
 	Location  string `json:"location"`
 	Condition string `json:"condition,omitempty"`
-	Error     string `json:"error,omitempty"`
+	Hits      string `json:"hits,omitempty"`
+	Temporary bool   `json:"temporary,omitempty"`
+	// Line is the line the breakpoint was set on, RequestedLine the line
+	// asked for when that had no executable code
+	Line          int    `json:"line,omitempty"`
+	RequestedLine int    `json:"requested_line,omitempty"`
+	Warning       string `json:"warning,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// JSONSourceLine is a line of a source listing with its annotations
+type JSONSourceLine struct {
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Current bool   `json:"current,omitempty"`
+	// Breakpoint is "enabled" or "disabled" when a line breakpoint is set
+	Breakpoint string `json:"breakpoint,omitempty"`
 }
 
 // OutputJSON outputs a JSON-formatted response
@@ -111,18 +153,44 @@ func ConvertPropertyToJSON(prop ProtocolProperty) JSONProperty {
 		}
 	}
 
+	// Children beyond max_children are split into pages, children beyond
+	// max_depth are left out and strings are cut at max_data
+	if pageSize := prop.GetPageSize(); pageSize > 0 && jsonProp.NumChildren > pageSize {
+		jsonProp.Paging = &JSONPaging{
+			Page:     prop.GetPage(),
+			PageSize: pageSize,
+			Pages:    (jsonProp.NumChildren + pageSize - 1) / pageSize,
+		}
+	}
+	if jsonProp.NumChildren > len(jsonProp.Children) {
+		jsonProp.Truncated = true
+	}
+	if size := prop.GetSize(); size > 0 {
+		jsonProp.Size = size
+		if size > len(value) {
+			jsonProp.Truncated = true
+		}
+	}
+
 	return jsonProp
 }
 
 // ConvertBreakpointToJSON converts a ProtocolBreakpoint to JSONBreakpoint
 func ConvertBreakpointToJSON(bp ProtocolBreakpoint) JSONBreakpoint {
 	return JSONBreakpoint{
-		ID:       bp.GetID(),
-		Type:     bp.GetType(),
-		State:    bp.GetState(),
-		Filename: bp.GetFilename(),
-		Line:     bp.GetLineNumber(),
-		Function: bp.GetFunction(),
+		ID:           bp.GetID(),
+		Type:         bp.GetType(),
+		State:        bp.GetState(),
+		Filename:     bp.GetFilename(),
+		Line:         bp.GetLineNumber(),
+		Function:     bp.GetFunction(),
+		Class:        bp.GetClass(),
+		Exception:    bp.GetException(),
+		Expression:   bp.GetExpression(),
+		HitValue:     bp.GetHitValue(),
+		HitCondition: bp.GetHitCondition(),
+		HitCount:     bp.GetHitCount(),
+		Resolved:     bp.GetResolved(),
 	}
 }
 
@@ -108,6 +108,70 @@ func TestJSONPropertyConversion(t *testing.T) {
 	}
 }
 
+func TestJSONPropertyConversion_Truncation(t *testing.T) {
+	tests := []struct {
+		name          string
+		prop          *mockProperty
+		wantTruncated bool
+		wantSize      int
+		wantPaging    *JSONPaging
+	}{
+		{
+			name:     "complete string",
+			prop:     &mockProperty{name: "s", propType: "string", value: "abc", size: 3},
+			wantSize: 3,
+		},
+		{
+			name:          "string cut at max_data",
+			prop:          &mockProperty{name: "s", propType: "string", value: "abc", size: 2048},
+			wantTruncated: true,
+			wantSize:      2048,
+		},
+		{
+			name: "second page of children",
+			prop: &mockProperty{
+				name: "rows", propType: "array", numChildren: 70, hasChildren: true, page: 1, pageSize: 32,
+				children: []interface{}{&mockProperty{name: "32", propType: "int", value: "1"}},
+			},
+			wantTruncated: true,
+			wantPaging:    &JSONPaging{Page: 1, PageSize: 32, Pages: 3},
+		},
+		{
+			name: "children beyond max_depth",
+			prop: &mockProperty{
+				name: "obj", propType: "object", numChildren: 2, hasChildren: true,
+			},
+			wantTruncated: true,
+		},
+		{
+			name: "all children on one page",
+			prop: &mockProperty{
+				name: "pair", propType: "array", numChildren: 1, hasChildren: true, pageSize: 32,
+				children: []interface{}{&mockProperty{name: "0", propType: "int", value: "1"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonProp := ConvertPropertyToJSON(tt.prop)
+			if jsonProp.Truncated != tt.wantTruncated {
+				t.Errorf("expected truncated %v, got %v", tt.wantTruncated, jsonProp.Truncated)
+			}
+			if jsonProp.Size != tt.wantSize {
+				t.Errorf("expected size %d, got %d", tt.wantSize, jsonProp.Size)
+			}
+			if (jsonProp.Paging == nil) != (tt.wantPaging == nil) ||
+				(tt.wantPaging != nil && *jsonProp.Paging != *tt.wantPaging) {
+				t.Errorf("expected paging %+v, got %+v", tt.wantPaging, jsonProp.Paging)
+			}
+			if tt.wantTruncated && TruncationHint(jsonProp) == "" {
+				t.Error("expected a truncation hint")
+			}
+		})
+	}
+}
+
 func TestJSONBreakpointConversion(t *testing.T) {
 	// Mock breakpoint for testing
 	mockBp := &mockBreakpoint{
@@ -117,10 +181,22 @@ func TestJSONBreakpointConversion(t *testing.T) {
 		filename: "/path/to/file.php",
 		line:     42,
 		function: "",
+		hitValue: 10,
+		hitCond:  "%",
+		hitCount: 30,
+		expr:     "$i > 2",
 	}
 
 	jsonBp := ConvertBreakpointToJSON(mockBp)
 
+	if jsonBp.Expression != "$i > 2" {
+		t.Errorf("expected expression %q, got %q", "$i > 2", jsonBp.Expression)
+	}
+
+	if jsonBp.HitValue != 10 || jsonBp.HitCondition != "%" || jsonBp.HitCount != 30 {
+		t.Errorf("expected hits 30 (%% 10), got %d (%s %d)", jsonBp.HitCount, jsonBp.HitCondition, jsonBp.HitValue)
+	}
+
 	if jsonBp.ID != mockBp.id {
 		t.Errorf("expected ID %q, got %q", mockBp.id, jsonBp.ID)
 	}
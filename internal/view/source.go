@@ -72,19 +72,15 @@ func (s *SourceFileCache) getLines(path string, begin, length int) ([]string, er
 }
 
 // PrintSourceLn displays source code around the specified line.
-// fileURI is expected to be a file:// URI or absolute path.
+// fileURI is expected to be a file:// URI or absolute path. Remote paths are
+// translated to local paths when a path mapper is set.
 // line is the current line (1-indexed).
 // length is the number of lines to display (before and after current line).
 func (v *View) PrintSourceLn(fileURI string, line, length int) {
-	// Convert file:// URI to path
-	path := fileURI
-	if strings.HasPrefix(fileURI, "file://") {
-		parsedURL, err := url.Parse(fileURI)
-		if err != nil {
-			v.PrintErrorLn(fmt.Sprintf("Error parsing file URI: %v", err))
-			return
-		}
-		path = parsedURL.Path
+	path, err := v.localSourcePath(fileURI)
+	if err != nil {
+		v.PrintErrorLn(err.Error())
+		return
 	}
 
 	// Calculate range: show 'length' lines before and after current line
@@ -121,6 +117,61 @@ func (v *View) PrintSourceLn(fileURI string, line, length int) {
 	v.PrintLn("")
 }
 
+// PrintSourceListing displays annotated source lines of file. The margin
+// marks enabled ("*") and disabled ("o") breakpoints and the current line
+// (">"). PHP syntax is highlighted when color is on.
+func (v *View) PrintSourceListing(file string, lines []JSONSourceLine) {
+	var highlighter phpHighlighter
+	v.PrintLn("")
+	v.PrintLn(file)
+	for _, line := range lines {
+		bpMarker := " "
+		switch line.Breakpoint {
+		case "enabled":
+			bpMarker = "*"
+		case "disabled":
+			bpMarker = "o"
+		}
+		marker := " "
+		if line.Current {
+			marker = ">"
+		}
+		text := line.Text
+		if v.color {
+			text = highlighter.line(text)
+		}
+		v.PrintLn(fmt.Sprintf("%s%s %4d | %s", bpMarker, marker, line.Line, text))
+	}
+	v.PrintLn("")
+}
+
+// SourceLines returns all lines of a source file. fileURI is translated like
+// in PrintSourceLn; an error means the file is not accessible locally.
+func (v *View) SourceLines(fileURI string) ([]string, error) {
+	path, err := v.localSourcePath(fileURI)
+	if err != nil {
+		return nil, err
+	}
+	return v.source.getLines(path, 1, 0)
+}
+
+// localSourcePath maps a remote path or file:// URI to a local file path
+func (v *View) localSourcePath(fileURI string) (string, error) {
+	if v.pathMapper != nil {
+		fileURI = v.pathMapper.ToLocal(fileURI)
+	}
+
+	// Convert file:// URI to path
+	if !strings.HasPrefix(fileURI, "file://") {
+		return fileURI, nil
+	}
+	parsedURL, err := url.Parse(fileURI)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing file URI: %v", err)
+	}
+	return parsedURL.Path, nil
+}
+
 // PrintSourceChangeLn displays a notification that execution moved to a new file.
 func (v *View) PrintSourceChangeLn(filename string) {
 	v.PrintLn(fmt.Sprintf("=> Execution in: %s", filename))
@@ -193,6 +193,31 @@ test();
 	}
 }
 
+func TestView_PrintSourceListing(t *testing.T) {
+	lines := []JSONSourceLine{
+		{Line: 9, Text: "$a = 1;", Breakpoint: "enabled"},
+		{Line: 10, Text: "$b = 2;", Current: true},
+		{Line: 11, Text: "$c = 3;", Breakpoint: "disabled"},
+	}
+
+	var buf bytes.Buffer
+	v := &View{stdout: &buf}
+	v.PrintSourceListing("/app/a.php", lines)
+
+	want := "\n/app/a.php\n*     9 | $a = 1;\n >   10 | $b = 2;\no    11 | $c = 3;\n\n"
+	if buf.String() != want {
+		t.Errorf("PrintSourceListing() = %q, want %q", buf.String(), want)
+	}
+
+	// With color on, the code is highlighted but the margin stays plain
+	buf.Reset()
+	v.SetColor(true)
+	v.PrintSourceListing("/app/a.php", lines[1:2])
+	if !strings.Contains(buf.String(), " >   10 | "+colorVariable+"$b"+colorReset) {
+		t.Errorf("PrintSourceListing() with color = %q", buf.String())
+	}
+}
+
 func TestView_PrintSourceChangeLn(t *testing.T) {
 	var buf bytes.Buffer
 	v := &View{stdout: &buf}
@@ -206,3 +231,34 @@ func TestView_PrintSourceChangeLn(t *testing.T) {
 		t.Errorf("PrintSourceChangeLn() = %q, want to contain %q", output, want)
 	}
 }
+
+// stubPathMapper maps one remote directory to a local one
+type stubPathMapper struct {
+	remote, local string
+}
+
+func (m stubPathMapper) ToLocal(path string) string {
+	return strings.Replace(path, m.remote, m.local, 1)
+}
+
+func TestView_PrintSourceLn_PathMapper(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "index.php")
+	if err := os.WriteFile(testFile, []byte("<?php\necho 'mapped';\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	v := &View{
+		stdout: &buf,
+		stderr: &buf,
+		source: NewSourceFileCache(),
+	}
+	v.SetPathMapper(stubPathMapper{remote: "/var/www/html", local: tmpDir})
+
+	v.PrintSourceLn("file:///var/www/html/index.php", 2, 3)
+
+	if !strings.Contains(buf.String(), "echo 'mapped'") {
+		t.Errorf("PrintSourceLn() did not read the mapped local file, got %q", buf.String())
+	}
+}
@@ -9,6 +9,13 @@ type ProtocolBreakpoint interface {
 	GetFilename() string
 	GetLineNumber() int
 	GetFunction() string
+	GetClass() string
+	GetException() string
+	GetExpression() string
+	GetHitValue() int
+	GetHitCondition() string
+	GetHitCount() int
+	GetResolved() string
 }
 
 // ProtocolProperty represents a variable/property in the DBGp protocol.
@@ -21,6 +28,9 @@ type ProtocolProperty interface {
 	GetChildren() []interface{}
 	HasChildren() bool
 	GetNumChildren() int
+	GetSize() int
+	GetPage() int
+	GetPageSize() int
 }
 
 // ProtocolStack represents a stack frame in the DBGp protocol.
@@ -32,3 +42,9 @@ type ProtocolStack interface {
 	GetFilename() string
 	GetLineNumber() int
 }
+
+// PathMapper translates file paths reported by Xdebug to local paths.
+// This interface is implemented by dbgp.PathMapper.
+type PathMapper interface {
+	ToLocal(path string) string
+}
@@ -8,9 +8,11 @@ import (
 
 // View handles terminal output operations for the debugger CLI.
 type View struct {
-	stdout io.Writer
-	stderr io.Writer
-	source *SourceFileCache
+	stdout     io.Writer
+	stderr     io.Writer
+	source     *SourceFileCache
+	pathMapper PathMapper
+	color      bool // highlight source listings
 }
 
 // NewView creates a new View instance with source cache.
@@ -19,9 +21,30 @@ func NewView() *View {
 		stdout: os.Stdout,
 		stderr: os.Stderr,
 		source: NewSourceFileCache(),
+		color:  colorEnabled(os.Stdout),
 	}
 }
 
+// colorEnabled reports whether output to f is colored: f is a terminal and
+// NO_COLOR is not set
+func colorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// SetColor turns syntax highlighting of source listings on or off.
+func (v *View) SetColor(color bool) {
+	v.color = color
+}
+
+// SetPathMapper sets the mapper used to find local copies of remote source files.
+func (v *View) SetPathMapper(mapper PathMapper) {
+	v.pathMapper = mapper
+}
+
 // Print outputs a string without a newline.
 func (v *View) Print(s string) {
 	fmt.Fprint(v.stdout, s)